@@ -5,6 +5,7 @@ go 1.22.0
 require (
 	github.com/go-resty/resty/v2 v2.13.1
 	github.com/goccy/go-json v0.10.3
+	github.com/goccy/go-yaml v1.19.2
 	github.com/google/go-querystring v1.1.0
 	github.com/google/uuid v1.6.0
 	go.uber.org/ratelimit v0.3.1
@@ -669,6 +669,13 @@ type AssortmentService interface {
 	// Принимает контекст и объект AssortmentSettings.
 	// Возвращает изменённый объект AssortmentSettings.
 	UpdateSettings(ctx context.Context, settings *AssortmentSettings) (*AssortmentSettings, *resty.Response, error)
+
+	// FindByBarcode выполняет поиск позиций ассортимента по штрихкоду.
+	// Принимает контекст, правила штрихкодов справочника и значение штрихкода.
+	// Штрихкоды весовых товаров декодируются в соответствии с правилами, поиск выполняется по коду товара,
+	// а при отсутствии результата – по штрихкоду.
+	// Возвращает найденные позиции ассортимента и вес (в килограммах) для штрихкода весового товара.
+	FindByBarcode(ctx context.Context, rules BarcodeRules, barcode string) (Assortment, float64, *resty.Response, error)
 }
 
 type assortmentService struct {
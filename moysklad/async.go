@@ -6,6 +6,7 @@ import (
 	"github.com/go-resty/resty/v2"
 	"github.com/google/uuid"
 	"net/http"
+	"time"
 )
 
 // Async Асинхронная задача.
@@ -159,6 +160,36 @@ func (service *asyncResultService[T]) Cancel(ctx context.Context) (bool, *resty.
 	return resp.StatusCode() == http.StatusNoContent, resp, nil
 }
 
+// asyncPollInterval интервал между запросами статуса Асинхронной задачи.
+const asyncPollInterval = time.Second
+
+// waitAsync ожидает завершения Асинхронной задачи, периодически запрашивая её статус.
+//
+// Возвращает объект Async с итоговым статусом задачи.
+func waitAsync(ctx context.Context, client *Client, statusURL string) (*Async, *resty.Response, error) {
+	ticker := time.NewTicker(asyncPollInterval)
+	defer ticker.Stop()
+
+	for {
+		async, resp, err := NewRequestBuilder[Async](client, statusURL).Get(ctx)
+		if err != nil {
+			return nil, resp, err
+		}
+
+		switch async.State {
+		case AsyncStatePending, AsyncStateProcessing:
+		default:
+			return async, resp, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, resp, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
 // NewAsyncService принимает [Client] и возвращает сервис для работы с асинхронными задачами.
 func NewAsyncService(client *Client) AsyncService {
 	return &asyncService{NewEndpoint(client, EndpointAsync)}
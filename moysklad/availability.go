@@ -0,0 +1,247 @@
+package moysklad
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"strings"
+)
+
+// availabilityBatchSize количество позиций ассортимента в одном запросе остатков.
+const availabilityBatchSize = 100
+
+// AssortmentShortage недостаток позиции ассортимента на складе.
+type AssortmentShortage struct {
+	AssortmentID string  // ID Товара/Модификации/Серии
+	Required     float64 // Требуемое количество в базовых единицах
+	Available    float64 // Доступное количество с учётом предыдущих позиций
+	Shortage     float64 // Недостающее количество
+}
+
+// PositionAvailability результат проверки доступности позиции заказа.
+type PositionAvailability struct {
+	Position   *CustomerOrderPosition // Позиция заказа
+	Required   float64                // Требуемое количество в базовых единицах
+	Shortages  []AssortmentShortage   // Недостающие товары (для комплекта – его компоненты)
+	IsService  bool                   // Позиция является услугой и не проверяется
+	IsReserved bool                   // Для позиции установлен резерв
+}
+
+// IsAvailable возвращает true, если позиция доступна полностью.
+func (positionAvailability PositionAvailability) IsAvailable() bool {
+	return len(positionAvailability.Shortages) == 0
+}
+
+// AvailabilityReport результат проверки доступности позиций заказа на складе.
+type AvailabilityReport struct {
+	StoreID   uuid.UUID               // ID склада
+	Positions []*PositionAvailability // Результаты по позициям в порядке следования
+}
+
+// IsAvailable возвращает true, если доступны все позиции.
+func (availabilityReport AvailabilityReport) IsAvailable() bool {
+	for _, position := range availabilityReport.Positions {
+		if !position.IsAvailable() {
+			return false
+		}
+	}
+	return true
+}
+
+// Shortages возвращает позиции, доступные не полностью.
+func (availabilityReport AvailabilityReport) Shortages() []*PositionAvailability {
+	var positions []*PositionAvailability
+	for _, position := range availabilityReport.Positions {
+		if !position.IsAvailable() {
+			positions = append(positions, position)
+		}
+	}
+	return positions
+}
+
+// AvailabilityChecker проверяет доступность позиций заказа покупателя на складе.
+//
+// Количество позиции с упаковкой переводится в базовые единицы товара, комплекты раскладываются на компоненты.
+// Доступное количество определяется как остаток за вычетом резерва (freeStock)
+// плюс резерв, уже установленный на проверяемой позиции.
+// Несколько позиций одного товара расходуют общий остаток в порядке следования.
+type AvailabilityChecker struct {
+	client     *Client
+	reserve    bool
+	components map[uuid.UUID][]*BundleComponent
+}
+
+// NewAvailabilityChecker принимает [Client] и возвращает новый объект [AvailabilityChecker].
+func NewAvailabilityChecker(client *Client) *AvailabilityChecker {
+	return &AvailabilityChecker{client: client, components: make(map[uuid.UUID][]*BundleComponent)}
+}
+
+// WithReserve включает установку резерва на полностью доступные позиции.
+//
+// Резерв устанавливается в объекты позиций; для сохранения необходимо выполнить запрос на изменение позиций заказа.
+func (availabilityChecker *AvailabilityChecker) WithReserve() *AvailabilityChecker {
+	availabilityChecker.reserve = true
+	return availabilityChecker
+}
+
+// CheckOrder выполняет проверку доступности позиций заказа покупателя на складе, указанном в заказе.
+//
+// Если позиции не были получены вместе с заказом, они запрашиваются отдельно.
+func (availabilityChecker *AvailabilityChecker) CheckOrder(ctx context.Context, customerOrder *CustomerOrder) (*AvailabilityReport, error) {
+	if customerOrder.Store == nil || customerOrder.Store.isNull() {
+		return nil, fmt.Errorf("check availability: customer order has no store")
+	}
+
+	positions := customerOrder.GetPositions().Rows
+	if len(positions) == 0 && customerOrder.ID != nil {
+		path := fmt.Sprintf("%s/%s/positions", EndpointCustomerOrder, customerOrder.ID)
+		err := forEachPage[CustomerOrderPosition](ctx, availabilityChecker.client, path, NewParams(), func(rows Slice[CustomerOrderPosition]) error {
+			positions.Push(rows...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	storeID := customerOrder.GetStore().GetID()
+	if storeID == uuid.Nil {
+		storeID = customerOrder.GetStore().GetMeta().GetUUIDFromHref()
+	}
+	return availabilityChecker.Check(ctx, storeID, positions...)
+}
+
+// Check выполняет проверку доступности переданных позиций на складе.
+func (availabilityChecker *AvailabilityChecker) Check(ctx context.Context, storeID uuid.UUID, positions ...*CustomerOrderPosition) (*AvailabilityReport, error) {
+	report := &AvailabilityReport{StoreID: storeID}
+
+	// требуемое количество по позициям: ID ассортимента -> количество в базовых единицах
+	var demands = make([]map[string]float64, len(positions))
+	var ids = make(map[string]struct{})
+
+	for i, position := range positions {
+		availability := &PositionAvailability{Position: position}
+		report.Positions = append(report.Positions, availability)
+
+		if position == nil || position.Assortment == nil {
+			continue
+		}
+
+		availability.Required = roundQuantity(position.GetQuantity() * packQuantity(position.Pack))
+
+		meta := position.Assortment.GetMeta()
+		id := meta.GetUUIDFromHref()
+
+		switch meta.GetType() {
+		case MetaTypeService:
+			availability.IsService = true
+		case MetaTypeBundle:
+			components, err := availabilityChecker.bundleComponents(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			demands[i] = make(map[string]float64)
+			for _, component := range components {
+				if component.Assortment == nil || component.Assortment.MetaType() == MetaTypeService {
+					continue
+				}
+				componentID := component.Assortment.GetMeta().GetUUIDFromHref().String()
+				demands[i][componentID] += availability.Required * Deref(component.Quantity)
+				ids[componentID] = struct{}{}
+			}
+		default:
+			demands[i] = map[string]float64{id.String(): availability.Required}
+			ids[id.String()] = struct{}{}
+		}
+	}
+
+	free, err := availabilityChecker.freeStock(ctx, storeID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// резерв проверяемых позиций уже учтён в резерве склада
+	for i, position := range positions {
+		if position == nil || demands[i] == nil || position.Reserve == nil || report.Positions[i].Required == 0 {
+			continue
+		}
+		ratio := position.GetReserve() / position.GetQuantity()
+		for id, required := range demands[i] {
+			free[id] += required * ratio
+		}
+	}
+
+	for i, availability := range report.Positions {
+		for id, required := range demands[i] {
+			if free[id] < required {
+				availability.Shortages = append(availability.Shortages, AssortmentShortage{
+					AssortmentID: id,
+					Required:     required,
+					Available:    max(free[id], 0),
+					Shortage:     required - max(free[id], 0),
+				})
+			}
+			free[id] -= required
+		}
+
+		sort.Slice(availability.Shortages, func(a, b int) bool {
+			return availability.Shortages[a].AssortmentID < availability.Shortages[b].AssortmentID
+		})
+
+		if availabilityChecker.reserve && demands[i] != nil && availability.IsAvailable() {
+			availability.Position.SetReserve(availability.Position.GetQuantity())
+			availability.IsReserved = true
+		}
+	}
+
+	return report, nil
+}
+
+// bundleComponents возвращает компоненты комплекта.
+func (availabilityChecker *AvailabilityChecker) bundleComponents(ctx context.Context, id uuid.UUID) ([]*BundleComponent, error) {
+	if components, ok := availabilityChecker.components[id]; ok {
+		return components, nil
+	}
+
+	list, _, err := NewBundleService(availabilityChecker.client).GetComponentList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	components := list.Rows
+	availabilityChecker.components[id] = components
+	return components, nil
+}
+
+// freeStock выполняет запрос на получение остатка за вычетом резерва по складу для переданных позиций.
+func (availabilityChecker *AvailabilityChecker) freeStock(ctx context.Context, storeID uuid.UUID, ids map[string]struct{}) (map[string]float64, error) {
+	free := make(map[string]float64, len(ids))
+	if len(ids) == 0 {
+		return free, nil
+	}
+
+	var all []string
+	for id := range ids {
+		all = append(all, id)
+	}
+
+	service := NewReportStockService(availabilityChecker.client)
+	for start := 0; start < len(all); start += availabilityBatchSize {
+		chunk := all[start:min(start+availabilityBatchSize, len(all))]
+		params := NewParams().
+			WithFilterEquals("storeId", storeID.String()).
+			WithFilterEquals("assortmentId", strings.Join(chunk, ",")).
+			WithStockFree()
+
+		stocks, _, err := service.GetCurrentByStore(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, stock := range Deref(stocks) {
+			free[stock.AssortmentID] += stock.FreeStock
+		}
+	}
+	return free, nil
+}
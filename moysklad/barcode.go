@@ -0,0 +1,268 @@
+package moysklad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-resty/resty/v2"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	InStoreBarcodePrefix = "20"  // Префикс штрихкодов EAN13 для внутреннего использования по умолчанию
+	WeightBarcodeDigits  = 5     // Количество цифр веса в штрихкоде весового товара
+	MaxBarcodeWeight     = 99999 // Максимальный вес в штрихкоде весового товара (в граммах)
+)
+
+var (
+	ErrBarcodeFormat     = errors.New("barcode: invalid format")
+	ErrBarcodeCheckDigit = errors.New("barcode: invalid check digit")
+)
+
+// BarcodeCheckDigit вычисляет контрольную цифру GS1 (EAN-8, EAN-13, UPC-A, GTIN-14)
+// для переданной последовательности цифр без контрольной цифры.
+func BarcodeCheckDigit(digits string) (int, error) {
+	if !isDigits(digits) {
+		return 0, fmt.Errorf("%w: %q", ErrBarcodeFormat, digits)
+	}
+
+	var sum int
+	for i := len(digits) - 1; i >= 0; i-- {
+		n := int(digits[i] - '0')
+		// цифры на нечётных позициях справа умножаются на 3
+		if (len(digits)-1-i)%2 == 0 {
+			n *= 3
+		}
+		sum += n
+	}
+	return (10 - sum%10) % 10, nil
+}
+
+// ValidateGTIN проверяет длину и контрольную цифру штрихкода формата GS1.
+//
+// Если длины не переданы, допускаются длины 8, 12, 13 и 14.
+func ValidateGTIN(value string, lengths ...int) error {
+	if len(lengths) == 0 {
+		lengths = []int{8, 12, 13, 14}
+	}
+
+	var lengthOK bool
+	for _, length := range lengths {
+		if len(value) == length {
+			lengthOK = true
+			break
+		}
+	}
+	if !lengthOK || !isDigits(value) {
+		return fmt.Errorf("%w: %q", ErrBarcodeFormat, value)
+	}
+
+	check, _ := BarcodeCheckDigit(value[:len(value)-1])
+	if int(value[len(value)-1]-'0') != check {
+		return fmt.Errorf("%w: %q", ErrBarcodeCheckDigit, value)
+	}
+	return nil
+}
+
+// Validate проверяет значение штрихкода в соответствии с его типом.
+//
+// Для штрихкодов EAN13, EAN8, UPC и GTIN проверяется длина и контрольная цифра.
+// Для штрихкода Code128 проверяется, что значение не пустое и содержит только символы ASCII.
+func (barcode Barcode) Validate() error {
+	switch barcode.Type {
+	case BarcodeEAN13:
+		return ValidateGTIN(barcode.Value, 13)
+	case BarcodeEAN8:
+		return ValidateGTIN(barcode.Value, 8)
+	case BarcodeUPC:
+		return ValidateGTIN(barcode.Value, 12)
+	case BarcodeGTIN:
+		return ValidateGTIN(barcode.Value, 8, 12, 13, 14)
+	case BarcodeCode128:
+		if barcode.Value == "" {
+			return fmt.Errorf("%w: empty value", ErrBarcodeFormat)
+		}
+		for _, r := range barcode.Value {
+			if r > 127 {
+				return fmt.Errorf("%w: %q", ErrBarcodeFormat, barcode.Value)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: unknown type %q", ErrBarcodeFormat, barcode.Type)
+}
+
+// IsValid возвращает true, если значение штрихкода соответствует его типу.
+func (barcode Barcode) IsValid() bool {
+	return barcode.Validate() == nil
+}
+
+// NewInStoreBarcodeEAN13 принимает префикс и порядковый номер и возвращает штрихкод EAN13 для внутреннего использования
+// с рассчитанной контрольной цифрой.
+//
+// Префикс должен состоять из 1-3 цифр (например, [InStoreBarcodePrefix] или другой префикс из диапазона 20-29,
+// не совпадающий с префиксом весовых товаров). Номер дополняется нулями слева до 12 цифр вместе с префиксом.
+// Если штрихкод совпадает по префиксу со штрихкодами весовых товаров [BarcodeRules], возвращается ошибка.
+func (barcodeRules BarcodeRules) NewInStoreBarcodeEAN13(prefix string, number int64) (*Barcode, error) {
+	if !isDigits(prefix) || len(prefix) > 3 {
+		return nil, fmt.Errorf("%w: in-store barcode prefix %q", ErrBarcodeFormat, prefix)
+	}
+
+	digits := 12 - len(prefix)
+	if number < 0 || number >= int64(math.Pow10(digits)) {
+		return nil, fmt.Errorf("%w: number %d out of range", ErrBarcodeFormat, number)
+	}
+
+	barcode, err := newBarcodeEAN13WithCheck(fmt.Sprintf("%s%0*d", prefix, digits, number))
+	if err != nil {
+		return nil, err
+	}
+
+	if prefix, err := barcodeRules.weightPrefix(); err == nil && strings.HasPrefix(barcode.Value, prefix) {
+		return nil, fmt.Errorf("%w: %q conflicts with weight barcode prefix %s", ErrBarcodeFormat, barcode.Value, prefix)
+	}
+	return barcode, nil
+}
+
+// newBarcodeEAN13WithCheck дополняет 12 цифр контрольной цифрой и возвращает [Barcode] с типом [BarcodeEAN13].
+func newBarcodeEAN13WithCheck(digits string) (*Barcode, error) {
+	check, err := BarcodeCheckDigit(digits)
+	if err != nil {
+		return nil, err
+	}
+	return NewBarcodeEAN13(digits + strconv.Itoa(check)), nil
+}
+
+// WeightBarcode данные штрихкода весового товара.
+//
+// Штрихкод весового товара имеет формат EAN13: префикс, код товара, вес в граммах (5 цифр) и контрольная цифра.
+// Длина кода товара зависит от длины префикса: 5 цифр для префикса XX и 6 цифр для префикса X.
+type WeightBarcode struct {
+	Prefix string  // Префикс штрихкода
+	Code   string  // Код товара
+	Weight float64 // Вес (в килограммах)
+}
+
+// String реализует интерфейс [fmt.Stringer].
+func (weightBarcode WeightBarcode) String() string {
+	return Stringify(weightBarcode)
+}
+
+// weightPrefix возвращает префикс штрихкодов весовых товаров.
+func (barcodeRules BarcodeRules) weightPrefix() (string, error) {
+	if !barcodeRules.GetWeightBarcode() || barcodeRules.WeightBarcodePrefix == nil {
+		return "", fmt.Errorf("%w: weight barcodes are disabled", ErrBarcodeFormat)
+	}
+
+	prefix := barcodeRules.GetWeightBarcodePrefix()
+	if prefix < 0 || prefix > 99 {
+		return "", fmt.Errorf("%w: weight barcode prefix %d", ErrBarcodeFormat, prefix)
+	}
+	return strconv.Itoa(prefix), nil
+}
+
+// NewWeightBarcode принимает код товара и вес (в килограммах) и возвращает штрихкод EAN13 весового товара,
+// сформированный в соответствии с префиксом [BarcodeRules].
+//
+// Код товара должен состоять из цифр и дополняется нулями слева до нужной длины.
+func (barcodeRules BarcodeRules) NewWeightBarcode(code string, weight float64) (*Barcode, error) {
+	prefix, err := barcodeRules.weightPrefix()
+	if err != nil {
+		return nil, err
+	}
+
+	codeDigits := 12 - WeightBarcodeDigits - len(prefix)
+	if !isDigits(code) || len(code) > codeDigits {
+		return nil, fmt.Errorf("%w: weight product code %q", ErrBarcodeFormat, code)
+	}
+
+	grams := int(math.Round(weight * 1000))
+	if grams < 0 || grams > MaxBarcodeWeight {
+		return nil, fmt.Errorf("%w: weight %v out of range", ErrBarcodeFormat, weight)
+	}
+
+	return newBarcodeEAN13WithCheck(fmt.Sprintf("%s%0*s%0*d", prefix, codeDigits, code, WeightBarcodeDigits, grams))
+}
+
+// IsWeightBarcode возвращает true, если значение является корректным штрихкодом EAN13 весового товара
+// с префиксом из [BarcodeRules].
+func (barcodeRules BarcodeRules) IsWeightBarcode(value string) bool {
+	_, err := barcodeRules.DecodeWeightBarcode(value)
+	return err == nil
+}
+
+// DecodeWeightBarcode принимает значение штрихкода весового товара и возвращает код товара и вес.
+func (barcodeRules BarcodeRules) DecodeWeightBarcode(value string) (*WeightBarcode, error) {
+	prefix, err := barcodeRules.weightPrefix()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ValidateGTIN(value, 13); err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(value, prefix) {
+		return nil, fmt.Errorf("%w: %q is not a weight barcode", ErrBarcodeFormat, value)
+	}
+
+	weightStart := 12 - WeightBarcodeDigits
+	grams, _ := strconv.Atoi(value[weightStart:12])
+
+	weightBarcode := &WeightBarcode{
+		Prefix: prefix,
+		Code:   value[len(prefix):weightStart],
+		Weight: float64(grams) / 1000,
+	}
+	return weightBarcode, nil
+}
+
+// FindByBarcode выполняет поиск позиций ассортимента по штрихкоду.
+//
+// Правила штрихкодов rules передаются вызывающей стороной (см. [AssortmentSettings.GetBarcodeRules]),
+// чтобы не запрашивать настройки справочника при каждом сканировании.
+//
+// Если штрихкод является штрихкодом весового товара согласно rules, поиск выполняется по коду товара,
+// а вес возвращается в качестве второго значения. Если по коду товара ничего не найдено,
+// выполняется поиск по штрихкоду, а вес равен 0. Для остальных штрихкодов вес равен 0.
+func (service *assortmentService) FindByBarcode(ctx context.Context, rules BarcodeRules, barcode string) (Assortment, float64, *resty.Response, error) {
+	if weightBarcode, err := rules.DecodeWeightBarcode(barcode); err == nil {
+		// код товара в штрихкоде дополнен нулями слева, поэтому ищем по обоим вариантам
+		params := NewParams().WithFilterEquals("code", weightBarcode.Code)
+		if code := strings.TrimLeft(weightBarcode.Code, "0"); code != "" && code != weightBarcode.Code {
+			params.WithFilterEquals("code", code)
+		}
+
+		response, resp, err := service.Get(ctx, params)
+		if err != nil {
+			return nil, 0, resp, err
+		}
+		if response != nil && len(response.Rows) > 0 {
+			return response.Rows, weightBarcode.Weight, resp, nil
+		}
+	}
+
+	response, resp, err := service.Get(ctx, NewParams().WithFilterEquals("barcode", barcode))
+	if err != nil {
+		return nil, 0, resp, err
+	}
+	if response == nil {
+		return nil, 0, resp, nil
+	}
+	return response.Rows, 0, resp, nil
+}
+
+// isDigits возвращает true, если строка не пустая и состоит только из цифр.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
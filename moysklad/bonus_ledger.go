@@ -0,0 +1,306 @@
+package moysklad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"time"
+)
+
+// ErrInsufficientBonus ошибка списания баллов сверх доступного баланса.
+var ErrInsufficientBonus = errors.New("bonus ledger: insufficient bonus points")
+
+// ErrBonusKeyCollision ошибка повторного использования ключа идемпотентности для другой операции.
+var ErrBonusKeyCollision = errors.New("bonus ledger: idempotency key collision")
+
+// BonusLedgerEntry запись истории бонусных операций.
+type BonusLedgerEntry struct {
+	Transaction *BonusTransaction // Бонусная операция
+	Points      int               // Изменение баланса: положительное при начислении, отрицательное при списании
+	Balance     int               // Баланс после операции (с учётом только завершённых операций)
+	Pending     bool              // Операция ожидает начисления
+	AvailableAt time.Time         // Дата начисления отложенных баллов (дата начисления операции либо её дата с учётом задержки начисления программы)
+}
+
+// BonusAccount бонусный счёт контрагента в бонусной программе.
+type BonusAccount struct {
+	CounterpartyID uuid.UUID           // ID контрагента
+	BonusProgramID uuid.UUID           // ID бонусной программы
+	Entries        []*BonusLedgerEntry // История операций в хронологическом порядке (без отменённых)
+	Earned         int                 // Начислено баллов (завершённые операции)
+	Spent          int                 // Списано баллов (завершённые операции)
+	Pending        int                 // Баллы, ожидающие начисления
+	Balance        int                 // Баланс по операциям
+	Reported       *int                // Баланс контрагента по данным сервиса (Counterparty.BonusPoints)
+}
+
+// Discrepancy возвращает расхождение баланса контрагента с балансом по операциям.
+//
+// Если баланс контрагента не загружен, возвращается 0.
+func (bonusAccount BonusAccount) Discrepancy() int {
+	if bonusAccount.Reported == nil {
+		return 0
+	}
+	return *bonusAccount.Reported - bonusAccount.Balance
+}
+
+// IsReconciled возвращает true, если баланс контрагента совпадает с балансом по операциям.
+func (bonusAccount BonusAccount) IsReconciled() bool {
+	return bonusAccount.Discrepancy() == 0
+}
+
+// PendingUntil возвращает количество баллов, которые будут начислены до момента at включительно.
+//
+// Учитываются ожидающие начисления с известной датой начисления [BonusLedgerEntry.AvailableAt].
+func (bonusAccount BonusAccount) PendingUntil(at time.Time) int {
+	var points int
+	for _, entry := range bonusAccount.Entries {
+		if entry.Pending && entry.Points > 0 && !entry.AvailableAt.IsZero() && !entry.AvailableAt.After(at) {
+			points += entry.Points
+		}
+	}
+	return points
+}
+
+// add учитывает бонусную операцию в счёте.
+//
+// Для начислений без даты начисления дата рассчитывается от даты операции с учётом задержки delayDays.
+func (bonusAccount *BonusAccount) add(transaction *BonusTransaction, now time.Time, delayDays int) {
+	if transaction.TransactionStatus == BonusTransactionStatusCanceled {
+		return
+	}
+
+	entry := &BonusLedgerEntry{Transaction: transaction, Points: transaction.GetBonusValue()}
+	if transaction.TransactionType == Spending {
+		entry.Points = -entry.Points
+	}
+
+	switch {
+	case transaction.ExecutionDate != nil:
+		entry.AvailableAt = transaction.GetExecutionDate()
+	case transaction.TransactionType == Earning && delayDays > 0 && transaction.Moment != nil:
+		entry.AvailableAt = transaction.GetMoment().AddDate(0, 0, delayDays)
+	}
+	entry.Pending = transaction.TransactionStatus == BonusTransactionStatusWaitProcessing ||
+		(transaction.TransactionType == Earning && entry.AvailableAt.After(now))
+
+	bonusAccount.Entries = append(bonusAccount.Entries, entry)
+}
+
+// calculate упорядочивает историю и рассчитывает итоги счёта.
+func (bonusAccount *BonusAccount) calculate() {
+	sort.SliceStable(bonusAccount.Entries, func(i, j int) bool {
+		return bonusAccount.Entries[i].Transaction.GetMoment().Before(bonusAccount.Entries[j].Transaction.GetMoment())
+	})
+
+	bonusAccount.Earned, bonusAccount.Spent, bonusAccount.Pending, bonusAccount.Balance = 0, 0, 0, 0
+	for _, entry := range bonusAccount.Entries {
+		switch {
+		case entry.Pending && entry.Points > 0:
+			bonusAccount.Pending += entry.Points
+		case entry.Points > 0:
+			bonusAccount.Earned += entry.Points
+		default:
+			// списание уменьшает баланс сразу, в том числе ожидающее обработки
+			bonusAccount.Spent -= entry.Points
+		}
+		bonusAccount.Balance = bonusAccount.Earned - bonusAccount.Spent
+		entry.Balance = bonusAccount.Balance
+	}
+}
+
+// BonusLedger агрегирует бонусные операции контрагентов и выполняет начисление и списание баллов.
+//
+// Баланс рассчитывается по завершённым операциям; отменённые операции не учитываются.
+// Начисления в статусе ожидания и начисления с датой начисления в будущем
+// (бонусные программы с отложенным начислением) учитываются как ожидающие.
+// Если дата начисления не указана в операции, она рассчитывается от даты операции
+// с учётом задержки [BonusProgram.PostponedBonusesDelayDays].
+//
+// Начисление и списание принимают ключ идемпотентности, который сохраняется во внешнем коде операции:
+// повторный вызов с тем же ключом возвращает ранее созданную операцию.
+// Если операция с тем же ключом создана для другого контрагента, программы, типа операции или количества баллов,
+// возвращается ошибка [ErrBonusKeyCollision].
+type BonusLedger struct {
+	client *Client
+	now    func() time.Time
+}
+
+// NewBonusLedger принимает [Client] и возвращает новый объект [BonusLedger].
+func NewBonusLedger(client *Client) *BonusLedger {
+	return &BonusLedger{client: client, now: time.Now}
+}
+
+// Account загружает бонусные операции контрагента в бонусной программе и возвращает его счёт.
+//
+// Если объект контрагента содержит бонусные баллы, они сохраняются в [BonusAccount.Reported] для сверки.
+func (bonusLedger *BonusLedger) Account(ctx context.Context, counterparty *Counterparty, bonusProgram *BonusProgram) (*BonusAccount, error) {
+	account := &BonusAccount{
+		CounterpartyID: counterparty.GetMeta().GetUUIDFromHref(),
+		BonusProgramID: bonusProgram.GetMeta().GetUUIDFromHref(),
+		Reported:       counterparty.BonusPoints,
+	}
+
+	params := NewParams().
+		WithFilterEquals("agent", counterparty.GetMeta().GetHref()).
+		WithFilterEquals("bonusProgram", bonusProgram.GetMeta().GetHref())
+
+	now := bonusLedger.now()
+	err := forEachPage[BonusTransaction](ctx, bonusLedger.client, EndpointBonusTransaction, params, func(rows Slice[BonusTransaction]) error {
+		for _, transaction := range rows {
+			account.add(transaction, now, bonusProgram.GetPostponedBonusesDelayDays())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bonus ledger: load transactions: %w", err)
+	}
+
+	account.calculate()
+	return account, nil
+}
+
+// Reconcile сверяет балансы всех контрагентов бонусной программы с их бонусными операциями
+// и возвращает счета с расхождениями.
+//
+// Сверяются контрагенты с операциями по программе и контрагенты, участвующие в программе.
+func (bonusLedger *BonusLedger) Reconcile(ctx context.Context, bonusProgram *BonusProgram) ([]*BonusAccount, error) {
+	programID := bonusProgram.GetMeta().GetUUIDFromHref()
+	accounts := make(map[uuid.UUID]*BonusAccount)
+	account := func(id uuid.UUID) *BonusAccount {
+		if accounts[id] == nil {
+			accounts[id] = &BonusAccount{CounterpartyID: id, BonusProgramID: programID}
+		}
+		return accounts[id]
+	}
+
+	now := bonusLedger.now()
+	params := NewParams().WithFilterEquals("bonusProgram", bonusProgram.GetMeta().GetHref())
+	err := forEachPage[BonusTransaction](ctx, bonusLedger.client, EndpointBonusTransaction, params, func(rows Slice[BonusTransaction]) error {
+		for _, transaction := range rows {
+			account(transaction.GetAgent().GetMeta().GetUUIDFromHref()).add(transaction, now, bonusProgram.GetPostponedBonusesDelayDays())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bonus ledger: load transactions: %w", err)
+	}
+
+	err = forEachPage[Counterparty](ctx, bonusLedger.client, EndpointCounterparty, NewParams(), func(rows Slice[Counterparty]) error {
+		for _, counterparty := range rows {
+			_, ok := accounts[counterparty.GetID()]
+			member := counterparty.BonusProgram != nil && !counterparty.BonusProgram.isNull() &&
+				counterparty.GetBonusProgram().GetMeta().GetUUIDFromHref() == programID
+			if ok || member {
+				account(counterparty.GetID()).Reported = Int(counterparty.GetBonusPoints())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bonus ledger: load counterparties: %w", err)
+	}
+
+	var discrepancies []*BonusAccount
+	for _, account := range accounts {
+		account.calculate()
+		if account.Reported == nil {
+			account.Reported = Int(0)
+		}
+		if !account.IsReconciled() {
+			discrepancies = append(discrepancies, account)
+		}
+	}
+
+	sort.Slice(discrepancies, func(i, j int) bool {
+		return discrepancies[i].CounterpartyID.String() < discrepancies[j].CounterpartyID.String()
+	})
+	return discrepancies, nil
+}
+
+// Earn начисляет контрагенту points баллов по бонусной программе.
+//
+// Ключ идемпотентности key сохраняется во внешнем коде операции.
+func (bonusLedger *BonusLedger) Earn(ctx context.Context, counterparty *Counterparty, bonusProgram *BonusProgram, points int, key string) (*BonusTransaction, error) {
+	return bonusLedger.create(ctx, counterparty, bonusProgram, Earning, points, key)
+}
+
+// Spend списывает у контрагента points баллов по бонусной программе.
+//
+// Если баланс по операциям меньше points, возвращается ошибка [ErrInsufficientBonus].
+// Ключ идемпотентности key сохраняется во внешнем коде операции.
+func (bonusLedger *BonusLedger) Spend(ctx context.Context, counterparty *Counterparty, bonusProgram *BonusProgram, points int, key string) (*BonusTransaction, error) {
+	if existing, err := bonusLedger.lookup(ctx, counterparty, bonusProgram, Spending, points, key); existing != nil || err != nil {
+		return existing, err
+	}
+
+	account, err := bonusLedger.Account(ctx, counterparty, bonusProgram)
+	if err != nil {
+		return nil, err
+	}
+	if account.Balance < points {
+		return nil, fmt.Errorf("%w: balance %d, requested %d", ErrInsufficientBonus, account.Balance, points)
+	}
+	return bonusLedger.insert(ctx, counterparty, bonusProgram, Spending, points, key)
+}
+
+// create создаёт бонусную операцию, если операция с ключом key не была создана ранее.
+func (bonusLedger *BonusLedger) create(ctx context.Context, counterparty *Counterparty, bonusProgram *BonusProgram, transactionType BonusTransactionType, points int, key string) (*BonusTransaction, error) {
+	if existing, err := bonusLedger.lookup(ctx, counterparty, bonusProgram, transactionType, points, key); existing != nil || err != nil {
+		return existing, err
+	}
+	return bonusLedger.insert(ctx, counterparty, bonusProgram, transactionType, points, key)
+}
+
+// lookup проверяет points и key и возвращает ранее созданную операцию с ключом key или nil.
+func (bonusLedger *BonusLedger) lookup(ctx context.Context, counterparty *Counterparty, bonusProgram *BonusProgram, transactionType BonusTransactionType, points int, key string) (*BonusTransaction, error) {
+	if points <= 0 {
+		return nil, fmt.Errorf("bonus ledger: points must be positive, got %d", points)
+	}
+	if key == "" {
+		return nil, fmt.Errorf("bonus ledger: idempotency key is required")
+	}
+	return bonusLedger.findByKey(ctx, counterparty, bonusProgram, transactionType, points, key)
+}
+
+// insert создаёт бонусную операцию без проверки ключа идемпотентности.
+func (bonusLedger *BonusLedger) insert(ctx context.Context, counterparty *Counterparty, bonusProgram *BonusProgram, transactionType BonusTransactionType, points int, key string) (*BonusTransaction, error) {
+	transaction := new(BonusTransaction).
+		SetAgent(counterparty).
+		SetBonusProgram(bonusProgram).
+		SetTransactionType(transactionType).
+		SetBonusValue(points).
+		SetExternalCode(key)
+
+	created, _, err := NewBonusTransactionService(bonusLedger.client).Create(ctx, transaction)
+	if err != nil {
+		return nil, fmt.Errorf("bonus ledger: create transaction: %w", err)
+	}
+	return created, nil
+}
+
+// findByKey возвращает бонусную операцию с внешним кодом key или nil.
+//
+// Если найденная операция не совпадает с запрошенной, возвращается ошибка [ErrBonusKeyCollision].
+func (bonusLedger *BonusLedger) findByKey(ctx context.Context, counterparty *Counterparty, bonusProgram *BonusProgram, transactionType BonusTransactionType, points int, key string) (*BonusTransaction, error) {
+	params := NewParams().WithFilterEquals("externalCode", key).WithLimit(1)
+	list, _, err := NewBonusTransactionService(bonusLedger.client).GetList(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("bonus ledger: find transaction %q: %w", key, err)
+	}
+	if len(list.Rows) == 0 {
+		return nil, nil
+	}
+
+	existing := list.Rows[0]
+	if existing.GetAgent().GetMeta().GetUUIDFromHref() != counterparty.GetMeta().GetUUIDFromHref() ||
+		existing.GetBonusProgram().GetMeta().GetUUIDFromHref() != bonusProgram.GetMeta().GetUUIDFromHref() ||
+		existing.GetTransactionType() != transactionType ||
+		existing.GetBonusValue() != points {
+		return nil, fmt.Errorf("%w: key %q is used by transaction %s (%s, %d points)",
+			ErrBonusKeyCollision, key, existing.GetID(), existing.GetTransactionType(), existing.GetBonusValue())
+	}
+	return existing, nil
+}
@@ -286,6 +286,11 @@ type BonusProgramService interface {
 	// Возвращает изменённую бонусную программу.
 	Update(ctx context.Context, id uuid.UUID, bonusProgram *BonusProgram, params ...*Params) (*BonusProgram, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение бонусной программы, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *BonusProgram, bonusProgram *BonusProgram, params ...*Params) (*BonusProgram, *resty.Response, error)
+
 	// GetByID выполняет запрос на получение бонусной программы.
 	// Принимает контекст, ID бонусной программы и опционально объект параметров запроса Params.
 	// Возвращает бонусную программу.
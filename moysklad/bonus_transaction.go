@@ -389,6 +389,11 @@ type BonusTransactionService interface {
 	// Принимает контекст, бонусную операцию и опционально объект параметров запроса Params.
 	// Возвращает изменённую бонусную операцию.
 	Update(ctx context.Context, id uuid.UUID, bonusTransaction *BonusTransaction, params ...*Params) (*BonusTransaction, *resty.Response, error)
+
+	// UpdateIfUnchanged выполняет запрос на изменение бонусной операции, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *BonusTransaction, bonusTransaction *BonusTransaction, params ...*Params) (*BonusTransaction, *resty.Response, error)
 }
 
 const (
@@ -668,6 +668,11 @@ type BundleService interface {
 	// Возвращает изменённый комплект.
 	Update(ctx context.Context, id uuid.UUID, bundle *Bundle, params ...*Params) (*Bundle, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение комплекта, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Bundle, bundle *Bundle, params ...*Params) (*Bundle, *resty.Response, error)
+
 	// DeleteByID выполняет запрос на удаление комплекта по ID.
 	// Принимает контекст и ID комплекта.
 	// Возвращает «true» в случае успешного удаления комплекта.
@@ -818,6 +823,7 @@ type bundleService struct {
 	endpointCreateUpdateMany[Bundle]
 	endpointGetByID[Bundle]
 	endpointUpdate[Bundle]
+	endpointUpdateSafe[Bundle]
 	endpointDeleteByID
 	endpointDelete[Bundle]
 	endpointDeleteMany[Bundle]
@@ -862,6 +868,7 @@ func NewBundleService(client *Client) BundleService {
 		endpointCreateUpdateMany: endpointCreateUpdateMany[Bundle]{e},
 		endpointGetByID:          endpointGetByID[Bundle]{e},
 		endpointUpdate:           endpointUpdate[Bundle]{e},
+		endpointUpdateSafe:       endpointUpdateSafe[Bundle]{e},
 		endpointDeleteByID:       endpointDeleteByID{e},
 		endpointDelete:           endpointDelete[Bundle]{e},
 		endpointDeleteMany:       endpointDeleteMany[Bundle]{e},
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/go-resty/resty/v2"
 	"github.com/google/uuid"
+	"io"
 	"time"
 )
 
@@ -546,6 +547,11 @@ type CashInService interface {
 	// Возвращает изменённый приходный ордер.
 	Update(ctx context.Context, id uuid.UUID, cashIn *CashIn, params ...*Params) (*CashIn, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение приходного ордера, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *CashIn, cashIn *CashIn, params ...*Params) (*CashIn, *resty.Response, error)
+
 	// GetPublicationList выполняет запрос на получение списка публикаций.
 	// Принимает контекст и ID документа.
 	// Возвращает объект List.
@@ -630,6 +636,21 @@ type CashInService interface {
 	// Принимает контекст, ID сущности/документа и множество файлов.
 	// Возвращает объект DeleteManyResponse, содержащий информацию об успешном удалении или ошибку.
 	DeleteFileMany(ctx context.Context, id uuid.UUID, files ...*File) (*DeleteManyResponse, *resty.Response, error)
+
+	// PrintDocument выполняет запрос на печать отдельного документа по шаблону печатной формы.
+	// Принимает контекст, ID документа и объект PrintDocumentArg.
+	// Возвращает объект PrintFile.
+	PrintDocument(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg) (*PrintFile, *resty.Response, error)
+
+	// PrintDocumentTo выполняет запрос на печать отдельного документа по шаблону печатной формы.
+	// Принимает контекст, ID документа, объект PrintDocumentArg и io.Writer, в который записывается файл без полной буферизации.
+	// Возвращает название файла.
+	PrintDocumentTo(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg, w io.Writer) (string, *resty.Response, error)
+
+	// PrintDocumentMany выполняет асинхронный запрос на массовую печать документов.
+	// Принимает контекст, объект MassPrintArg и io.Writer, в который записывается полученный файл.
+	// Возвращает объект MassPrintResult, содержащий название файла и ошибки печати отдельных документов.
+	PrintDocumentMany(ctx context.Context, massPrintArg *MassPrintArg, w io.Writer) (*MassPrintResult, *resty.Response, error)
 }
 
 const (
@@ -0,0 +1,622 @@
+package moysklad
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"github.com/goccy/go-json"
+	"hash/fnv"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportField поле позиции ассортимента, выгружаемое в таблицу.
+//
+// Возможные значения:
+//   - ExportFieldID           – ID
+//   - ExportFieldType         – Тип сущности
+//   - ExportFieldName         – Наименование
+//   - ExportFieldArticle      – Артикул
+//   - ExportFieldCode         – Код
+//   - ExportFieldExternalCode – Внешний код
+//   - ExportFieldDescription  – Описание
+//   - ExportFieldFolder       – Путь группы товаров
+//   - ExportFieldPrice        – Цена продажи выбранного типа (в рублях)
+//   - ExportFieldStock        – Доступный остаток
+//   - ExportFieldBarcodes     – Штрихкоды через запятую
+//   - ExportFieldImages       – Ссылки на изображения через запятую
+//   - ExportFieldArchived     – Признак архивной позиции
+type ExportField string
+
+const (
+	ExportFieldID           ExportField = "id"           // ID
+	ExportFieldType         ExportField = "type"         // Тип сущности
+	ExportFieldName         ExportField = "name"         // Наименование
+	ExportFieldArticle      ExportField = "article"      // Артикул
+	ExportFieldCode         ExportField = "code"         // Код
+	ExportFieldExternalCode ExportField = "externalCode" // Внешний код
+	ExportFieldDescription  ExportField = "description"  // Описание
+	ExportFieldFolder       ExportField = "folder"       // Путь группы товаров
+	ExportFieldPrice        ExportField = "price"        // Цена продажи
+	ExportFieldStock        ExportField = "stock"        // Доступный остаток
+	ExportFieldBarcodes     ExportField = "barcodes"     // Штрихкоды
+	ExportFieldImages       ExportField = "images"       // Ссылки на изображения
+	ExportFieldArchived     ExportField = "archived"     // Признак архивной позиции
+)
+
+// DefaultExportFields поля, выгружаемые по умолчанию.
+var DefaultExportFields = []ExportField{
+	ExportFieldID, ExportFieldType, ExportFieldName, ExportFieldArticle, ExportFieldCode,
+	ExportFieldFolder, ExportFieldPrice, ExportFieldStock, ExportFieldBarcodes,
+}
+
+// CatalogItem позиция ассортимента, подготовленная к выгрузке.
+type CatalogItem struct {
+	ID           string   // ID позиции
+	Type         MetaType // Тип сущности
+	Name         string   // Наименование
+	Article      string   // Артикул
+	Code         string   // Код
+	ExternalCode string   // Внешний код
+	Description  string   // Описание
+	FolderID     string   // ID группы товаров
+	FolderPath   string   // Путь группы товаров через «/»
+	ProductID    string   // ID товара (для модификаций)
+	Price        float64  // Цена продажи выбранного типа (в рублях)
+	Stock        float64  // Доступный остаток
+	Barcodes     []string // Штрихкоды
+	Images       []string // Ссылки на скачивание изображений (требуют авторизации)
+	Archived     bool     // Признак архивной позиции
+}
+
+// Value возвращает строковое значение поля позиции.
+func (catalogItem CatalogItem) Value(field ExportField) string {
+	switch field {
+	case ExportFieldID:
+		return catalogItem.ID
+	case ExportFieldType:
+		return catalogItem.Type.String()
+	case ExportFieldName:
+		return catalogItem.Name
+	case ExportFieldArticle:
+		return catalogItem.Article
+	case ExportFieldCode:
+		return catalogItem.Code
+	case ExportFieldExternalCode:
+		return catalogItem.ExternalCode
+	case ExportFieldDescription:
+		return catalogItem.Description
+	case ExportFieldFolder:
+		return catalogItem.FolderPath
+	case ExportFieldPrice:
+		return strconv.FormatFloat(catalogItem.Price, 'f', -1, 64)
+	case ExportFieldStock:
+		return strconv.FormatFloat(catalogItem.Stock, 'f', -1, 64)
+	case ExportFieldBarcodes:
+		return strings.Join(catalogItem.Barcodes, ",")
+	case ExportFieldImages:
+		return strings.Join(catalogItem.Images, ",")
+	case ExportFieldArchived:
+		return strconv.FormatBool(catalogItem.Archived)
+	}
+	return ""
+}
+
+// catalogFolder группа товаров для построения дерева категорий.
+type catalogFolder struct {
+	ID       string
+	ParentID string
+	Name     string
+	Path     string
+}
+
+// CatalogExporter выгружает товары, модификации, комплекты и услуги в форматы CSV, XLSX и YML.
+//
+// Позиции загружаются постранично и записываются по мере получения.
+// Остатки берутся из отчёта о текущих остатках, цена – из цены продажи выбранного типа.
+// Остаток комплекта рассчитывается как количество комплектов, которое можно собрать из остатков компонентов.
+type CatalogExporter struct {
+	client          *Client
+	types           []MetaType
+	fields          []ExportField
+	priceType       string
+	folderPath      string
+	includeArchived bool
+	inStockOnly     bool
+	withImages      bool
+}
+
+// NewCatalogExporter принимает [Client] и возвращает новый объект [CatalogExporter].
+//
+// По умолчанию выгружаются неархивные товары, модификации, комплекты и услуги с полями [DefaultExportFields].
+func NewCatalogExporter(client *Client) *CatalogExporter {
+	return &CatalogExporter{
+		client: client,
+		types:  []MetaType{MetaTypeProduct, MetaTypeVariant, MetaTypeBundle, MetaTypeService},
+		fields: DefaultExportFields,
+	}
+}
+
+// WithTypes устанавливает типы выгружаемых позиций.
+func (catalogExporter *CatalogExporter) WithTypes(types ...MetaType) *CatalogExporter {
+	catalogExporter.types = types
+	return catalogExporter
+}
+
+// WithFields устанавливает выгружаемые поля для форматов CSV и XLSX.
+func (catalogExporter *CatalogExporter) WithFields(fields ...ExportField) *CatalogExporter {
+	catalogExporter.fields = fields
+	return catalogExporter
+}
+
+// WithPriceType устанавливает наименование типа цены продажи.
+//
+// Если тип цены не задан, используется первая цена продажи позиции.
+func (catalogExporter *CatalogExporter) WithPriceType(priceTypeName string) *CatalogExporter {
+	catalogExporter.priceType = priceTypeName
+	return catalogExporter
+}
+
+// WithFolder ограничивает выгрузку группой товаров с указанным путём (через «/») и её подгруппами.
+func (catalogExporter *CatalogExporter) WithFolder(folderPath string) *CatalogExporter {
+	catalogExporter.folderPath = strings.Trim(folderPath, "/")
+	return catalogExporter
+}
+
+// WithArchived включает выгрузку архивных позиций.
+func (catalogExporter *CatalogExporter) WithArchived() *CatalogExporter {
+	catalogExporter.includeArchived = true
+	return catalogExporter
+}
+
+// WithInStockOnly ограничивает выгрузку позициями с положительным доступным остатком.
+//
+// Услуги выгружаются независимо от остатка.
+func (catalogExporter *CatalogExporter) WithInStockOnly() *CatalogExporter {
+	catalogExporter.inStockOnly = true
+	return catalogExporter
+}
+
+// WithImages включает загрузку ссылок на изображения.
+//
+// Ссылки загружаются с раскрытием изображений, что уменьшает размер страницы запроса до 100 позиций.
+func (catalogExporter *CatalogExporter) WithImages() *CatalogExporter {
+	catalogExporter.withImages = true
+	return catalogExporter
+}
+
+// catalogRaw поля позиции ассортимента, необходимые для выгрузки.
+type catalogRaw struct {
+	Meta          Meta              `json:"meta"`
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Article       string            `json:"article"`
+	Code          string            `json:"code"`
+	ExternalCode  string            `json:"externalCode"`
+	Description   string            `json:"description"`
+	PathName      string            `json:"pathName"`
+	Archived      bool              `json:"archived"`
+	SalePrices    Slice[SalePrice]  `json:"salePrices"`
+	Barcodes      Slice[Barcode]    `json:"barcodes"`
+	Images        *MetaArray[Image] `json:"images"`
+	ProductFolder *MetaWrapper      `json:"productFolder"`
+	Product       *MetaWrapper      `json:"product"`
+}
+
+// Each загружает позиции ассортимента, удовлетворяющие условиям выгрузки, и передаёт каждую в функцию fn.
+func (catalogExporter *CatalogExporter) Each(ctx context.Context, fn func(item *CatalogItem) error) error {
+	folders, err := catalogExporter.loadFolders(ctx)
+	if err != nil {
+		return err
+	}
+	return catalogExporter.each(ctx, folders, fn)
+}
+
+func (catalogExporter *CatalogExporter) each(ctx context.Context, folders map[string]*catalogFolder, fn func(item *CatalogItem) error) error {
+	stocks := make(map[string]float64)
+	current, _, err := NewReportStockService(catalogExporter.client).GetCurrentAll(ctx, NewParams().WithStockQuantity())
+	if err != nil {
+		return err
+	}
+	for _, stock := range Deref(current) {
+		stocks[stock.AssortmentID] = stock.Quantity
+	}
+
+	if slices.Contains(catalogExporter.types, MetaTypeBundle) {
+		if err = catalogExporter.loadBundleStocks(ctx, stocks); err != nil {
+			return err
+		}
+	}
+
+	// группа модификации берётся из товара, поэтому товары загружаются и тогда, когда они не выгружаются
+	params := NewParams()
+	types := catalogExporter.types
+	if slices.Contains(types, MetaTypeVariant) && !slices.Contains(types, MetaTypeProduct) {
+		types = append(slices.Clip(types), MetaTypeProduct)
+	}
+	for _, metaType := range types {
+		params.WithFilterEquals("type", metaType.String())
+	}
+	if catalogExporter.includeArchived {
+		params.WithFilterEquals("archived", "true").WithFilterEquals("archived", "false")
+	}
+	if catalogExporter.withImages {
+		params.WithExpand("images").WithLimit(100)
+	}
+
+	// папки товаров для модификаций, у которых группа не указана
+	productFolders := make(map[string]string)
+	// модификации без группы выгружаются после загрузки всех товаров: порядок позиций ассортимента не гарантирован
+	var variants []*CatalogItem
+
+	emit := func(item *CatalogItem) error {
+		if !slices.Contains(catalogExporter.types, item.Type) || !catalogExporter.match(item) {
+			return nil
+		}
+		return fn(item)
+	}
+
+	err = forEachPage[AssortmentPosition](ctx, catalogExporter.client, EndpointAssortment, params, func(positions Slice[AssortmentPosition]) error {
+		for _, position := range positions {
+			var raw catalogRaw
+			if err := json.Unmarshal(position.Raw(), &raw); err != nil {
+				return err
+			}
+
+			item := catalogExporter.item(&raw, stocks, folders)
+			if item.Type == MetaTypeProduct {
+				productFolders[item.ID] = item.FolderID
+			}
+			if item.Type == MetaTypeVariant && item.FolderID == "" {
+				variants = append(variants, item)
+				continue
+			}
+			if err := emit(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range variants {
+		if folder, ok := folders[productFolders[item.ProductID]]; ok {
+			item.FolderID, item.FolderPath = folder.ID, folder.Path
+		}
+		if err = emit(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// item преобразует позицию ассортимента в [CatalogItem].
+func (catalogExporter *CatalogExporter) item(raw *catalogRaw, stocks map[string]float64, folders map[string]*catalogFolder) *CatalogItem {
+	item := &CatalogItem{
+		ID:           raw.ID,
+		Type:         raw.Meta.GetType(),
+		Name:         raw.Name,
+		Article:      raw.Article,
+		Code:         raw.Code,
+		ExternalCode: raw.ExternalCode,
+		Description:  raw.Description,
+		FolderPath:   raw.PathName,
+		Stock:        stocks[raw.ID],
+		Archived:     raw.Archived,
+	}
+
+	if raw.ProductFolder != nil {
+		item.FolderID = raw.ProductFolder.Meta.GetUUIDFromHref().String()
+		if folder, ok := folders[item.FolderID]; ok {
+			item.FolderPath = folder.Path
+		}
+	}
+	if raw.Product != nil {
+		item.ProductID = raw.Product.Meta.GetUUIDFromHref().String()
+	}
+
+	for _, salePrice := range raw.SalePrices {
+		if catalogExporter.priceType == "" || strings.EqualFold(salePrice.GetPriceType().GetName(), catalogExporter.priceType) {
+			item.Price = salePrice.GetValue() / 100
+			break
+		}
+	}
+
+	for _, barcode := range raw.Barcodes {
+		item.Barcodes = append(item.Barcodes, barcode.Value)
+	}
+
+	if raw.Images != nil {
+		for _, image := range raw.Images.Rows {
+			if href := image.GetMeta().GetDownloadHref(); href != "" {
+				item.Images = append(item.Images, href)
+			}
+		}
+	}
+	return item
+}
+
+// loadBundleStocks рассчитывает остатки комплектов по остаткам компонентов и добавляет их в stocks.
+//
+// Компоненты-услуги не ограничивают количество комплектов.
+func (catalogExporter *CatalogExporter) loadBundleStocks(ctx context.Context, stocks map[string]float64) error {
+	params := NewParams().WithExpand("components").WithLimit(100)
+	if catalogExporter.includeArchived {
+		params.WithFilterEquals("archived", "true").WithFilterEquals("archived", "false")
+	}
+
+	bundleStocks := make(map[string]float64)
+	err := forEachPage[Bundle](ctx, catalogExporter.client, EndpointBundle, params, func(bundles Slice[Bundle]) error {
+		for _, bundle := range bundles {
+			stock := math.Inf(1)
+			for _, component := range bundle.GetComponents().Rows {
+				quantity := Deref(component.Quantity)
+				if component.Assortment == nil || quantity <= 0 || component.Assortment.Meta.GetType() == MetaTypeService {
+					continue
+				}
+				id := component.Assortment.Meta.GetUUIDFromHref().String()
+				stock = min(stock, math.Floor(max(stocks[id], 0)/quantity))
+			}
+			if math.IsInf(stock, 1) {
+				stock = 0
+			}
+			bundleStocks[bundle.GetID().String()] = stock
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for id, stock := range bundleStocks {
+		stocks[id] = stock
+	}
+	return nil
+}
+
+// match возвращает true, если позиция удовлетворяет условиям выгрузки.
+func (catalogExporter *CatalogExporter) match(item *CatalogItem) bool {
+	if item.Archived && !catalogExporter.includeArchived {
+		return false
+	}
+	if catalogExporter.inStockOnly && item.Type != MetaTypeService && item.Stock <= 0 {
+		return false
+	}
+	if path := catalogExporter.folderPath; path != "" {
+		if !strings.EqualFold(item.FolderPath, path) && !strings.HasPrefix(strings.ToLower(item.FolderPath), strings.ToLower(path)+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// loadFolders загружает группы товаров и вычисляет их полные пути.
+func (catalogExporter *CatalogExporter) loadFolders(ctx context.Context) (map[string]*catalogFolder, error) {
+	folders := make(map[string]*catalogFolder)
+	err := forEachPage[ProductFolder](ctx, catalogExporter.client, EndpointProductFolder, NewParams(), func(rows Slice[ProductFolder]) error {
+		for _, row := range rows {
+			folder := &catalogFolder{ID: row.GetID().String(), Name: row.GetName(), Path: row.GetName()}
+			if pathName := row.GetPathName(); pathName != "" {
+				folder.Path = pathName + "/" + row.GetName()
+			}
+			if row.ProductFolder != nil && !row.ProductFolder.isNull() {
+				folder.ParentID = row.GetProductFolder().GetMeta().GetUUIDFromHref().String()
+			}
+			folders[folder.ID] = folder
+		}
+		return nil
+	})
+	return folders, err
+}
+
+// WriteTable записывает выгрузку с выбранными полями в [RowWriter]. Первая строка содержит коды полей.
+func (catalogExporter *CatalogExporter) WriteTable(ctx context.Context, rowWriter RowWriter) error {
+	header := make([]string, len(catalogExporter.fields))
+	for i, field := range catalogExporter.fields {
+		header[i] = string(field)
+	}
+	if err := rowWriter.WriteRow(header); err != nil {
+		return err
+	}
+
+	err := catalogExporter.Each(ctx, func(item *CatalogItem) error {
+		row := make([]string, len(catalogExporter.fields))
+		for i, field := range catalogExporter.fields {
+			row[i] = item.Value(field)
+		}
+		return rowWriter.WriteRow(row)
+	})
+	if err != nil {
+		return err
+	}
+	return rowWriter.Close()
+}
+
+// WriteCSV записывает выгрузку в формате CSV с разделителем comma.
+func (catalogExporter *CatalogExporter) WriteCSV(ctx context.Context, w io.Writer, comma rune) error {
+	return catalogExporter.WriteTable(ctx, NewCSVRowWriter(w, comma))
+}
+
+// WriteXLSX записывает выгрузку в формате XLSX.
+func (catalogExporter *CatalogExporter) WriteXLSX(ctx context.Context, w io.Writer) error {
+	return catalogExporter.WriteTable(ctx, NewXLSXRowWriter(w))
+}
+
+// YMLShop сведения о магазине для выгрузки в формате YML.
+type YMLShop struct {
+	Name     string // Короткое название магазина
+	Company  string // Полное наименование компании
+	URL      string // Адрес сайта магазина
+	Currency string // Код валюты цен (по умолчанию RUR)
+	OfferURL string // Шаблон ссылки на страницу товара, «{id}» заменяется на ID позиции
+	ImageURL string // Шаблон общедоступной ссылки на изображение позиции, «{id}» заменяется на ID позиции
+}
+
+// ymlIDs преобразует ID позиций или групп товаров в числовые идентификаторы формата YML длиной не более digits цифр.
+//
+// Идентификатор вычисляется как хэш ID, поэтому не меняется между выгрузками. При совпадении хэшей
+// разных ID в пределах выгрузки используется следующее свободное значение.
+type ymlIDs struct {
+	digits int
+	byID   map[string]string
+	used   map[string]string
+}
+
+// newYMLIDs возвращает новый объект [ymlIDs] для идентификаторов длиной не более digits цифр.
+func newYMLIDs(digits int) *ymlIDs {
+	return &ymlIDs{digits: digits, byID: make(map[string]string), used: make(map[string]string)}
+}
+
+// get возвращает числовой идентификатор для id или пустую строку, если id пустой.
+func (ymlIDs *ymlIDs) get(id string) string {
+	if id == "" {
+		return ""
+	}
+	if value, ok := ymlIDs.byID[id]; ok {
+		return value
+	}
+
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(id))
+	modulus := uint64(math.Pow10(ymlIDs.digits)) - 1
+	number := hash.Sum64() % modulus
+	value := strconv.FormatUint(number+1, 10)
+	for owner, ok := ymlIDs.used[value]; ok && owner != id; owner, ok = ymlIDs.used[value] {
+		number = (number + 1) % modulus
+		value = strconv.FormatUint(number+1, 10)
+	}
+
+	ymlIDs.byID[id] = value
+	ymlIDs.used[value] = id
+	return value
+}
+
+// Длины числовых идентификаторов формата YML.
+const (
+	ymlCategoryIDDigits = 9  // ID категории и group_id
+	ymlOfferIDDigits    = 18 // ID предложения (не более 20 символов)
+)
+
+// Элементы каталога в формате YML.
+type (
+	ymlCategory struct {
+		XMLName  xml.Name `xml:"category"`
+		ID       string   `xml:"id,attr"`
+		ParentID string   `xml:"parentId,attr,omitempty"`
+		Name     string   `xml:",chardata"`
+	}
+	ymlOffer struct {
+		XMLName     xml.Name `xml:"offer"`
+		ID          string   `xml:"id,attr"`
+		Available   bool     `xml:"available,attr"`
+		GroupID     string   `xml:"group_id,attr,omitempty"`
+		URL         string   `xml:"url,omitempty"`
+		Price       float64  `xml:"price"`
+		CurrencyID  string   `xml:"currencyId"`
+		CategoryID  string   `xml:"categoryId,omitempty"`
+		Pictures    []string `xml:"picture"`
+		Name        string   `xml:"name"`
+		VendorCode  string   `xml:"vendorCode,omitempty"`
+		Barcodes    []string `xml:"barcode"`
+		Description string   `xml:"description,omitempty"`
+		Count       float64  `xml:"count"`
+	}
+)
+
+// WriteYML записывает выгрузку в формате YML (Яндекс Маркет).
+//
+// Группы товаров выгружаются в качестве категорий, позиции – в качестве предложений.
+// Модификации объединяются с товаром атрибутом group_id.
+//
+// Формат YML требует числовых идентификаторов категорий, предложений и group_id, поэтому вместо ID МойСклад
+// выгружаются числовые идентификаторы, вычисленные по ID и не меняющиеся между выгрузками.
+// Плейсхолдер «{id}» в шаблонах ссылок на товар и изображение заменяется на ID позиции МойСклад.
+//
+// Ссылки на скачивание изображений МойСклад требуют авторизации и недоступны площадкам,
+// поэтому изображения выгружаются, только если задан шаблон [YMLShop.ImageURL].
+func (catalogExporter *CatalogExporter) WriteYML(ctx context.Context, w io.Writer, shop YMLShop) error {
+	folders, err := catalogExporter.loadFolders(ctx)
+	if err != nil {
+		return err
+	}
+
+	currency := shop.Currency
+	if currency == "" {
+		currency = "RUR"
+	}
+
+	encoder := xml.NewEncoder(w)
+	var head strings.Builder
+	head.WriteString(xml.Header)
+	fmt.Fprintf(&head, `<yml_catalog date="%s"><shop>`, time.Now().Format("2006-01-02T15:04:05-07:00"))
+	for _, element := range []struct{ name, value string }{{"name", shop.Name}, {"company", shop.Company}, {"url", shop.URL}} {
+		fmt.Fprintf(&head, "<%s>", element.name)
+		if err = xml.EscapeText(&head, []byte(element.value)); err != nil {
+			return err
+		}
+		fmt.Fprintf(&head, "</%s>", element.name)
+	}
+	fmt.Fprintf(&head, `<currencies><currency id="%s" rate="1"/></currencies><categories>`, currency)
+	if _, err = io.WriteString(w, head.String()); err != nil {
+		return err
+	}
+
+	categoryIDs, groupIDs, offerIDs := newYMLIDs(ymlCategoryIDDigits), newYMLIDs(ymlCategoryIDDigits), newYMLIDs(ymlOfferIDDigits)
+
+	// порядок групп фиксирован, чтобы идентификаторы при совпадении хэшей не менялись между выгрузками
+	folderIDs := make([]string, 0, len(folders))
+	for id := range folders {
+		folderIDs = append(folderIDs, id)
+	}
+	slices.Sort(folderIDs)
+
+	for _, id := range folderIDs {
+		folder := folders[id]
+		category := ymlCategory{
+			ID:       categoryIDs.get(folder.ID),
+			ParentID: categoryIDs.get(folder.ParentID),
+			Name:     folder.Name,
+		}
+		if err = encoder.Encode(category); err != nil {
+			return err
+		}
+	}
+	if _, err = io.WriteString(w, "</categories><offers>"); err != nil {
+		return err
+	}
+
+	err = catalogExporter.each(ctx, folders, func(item *CatalogItem) error {
+		offer := ymlOffer{
+			ID:          offerIDs.get(item.ID),
+			Available:   item.Type == MetaTypeService || item.Stock > 0,
+			GroupID:     groupIDs.get(item.ProductID),
+			Price:       item.Price,
+			CurrencyID:  currency,
+			CategoryID:  categoryIDs.get(item.FolderID),
+			Name:        item.Name,
+			VendorCode:  item.Article,
+			Barcodes:    item.Barcodes,
+			Description: item.Description,
+			Count:       max(item.Stock, 0),
+		}
+		if shop.OfferURL != "" {
+			offer.URL = strings.ReplaceAll(shop.OfferURL, "{id}", item.ID)
+		}
+		if shop.ImageURL != "" {
+			offer.Pictures = []string{strings.ReplaceAll(shop.ImageURL, "{id}", item.ID)}
+		}
+		return encoder.Encode(offer)
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "</offers></shop></yml_catalog>")
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/go-resty/resty/v2"
 	"github.com/google/uuid"
+	"io"
 	"time"
 )
 
@@ -873,6 +874,11 @@ type CommissionReportInService interface {
 	// Возвращает изменённый полученный отчёт комиссионера.
 	Update(ctx context.Context, id uuid.UUID, commissionReportIn *CommissionReportIn, params ...*Params) (*CommissionReportIn, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение полученного отчёта комиссионера, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *CommissionReportIn, commissionReportIn *CommissionReportIn, params ...*Params) (*CommissionReportIn, *resty.Response, error)
+
 	// GetMetadata выполняет запрос на получение метаданных полученных отчётов комиссионера.
 	// Принимает контекст.
 	// Возвращает объект метаданных MetaAttributesStatesSharedWrapper.
@@ -1094,6 +1100,21 @@ type CommissionReportInService interface {
 	//	- EvaluateCost     – себестоимость
 	// Возвращает шаблон документа с автозаполнением.
 	Evaluate(ctx context.Context, commissionReportIn *CommissionReportIn, evaluate ...Evaluate) (*CommissionReportIn, *resty.Response, error)
+
+	// PrintDocument выполняет запрос на печать отдельного документа по шаблону печатной формы.
+	// Принимает контекст, ID документа и объект PrintDocumentArg.
+	// Возвращает объект PrintFile.
+	PrintDocument(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg) (*PrintFile, *resty.Response, error)
+
+	// PrintDocumentTo выполняет запрос на печать отдельного документа по шаблону печатной формы.
+	// Принимает контекст, ID документа, объект PrintDocumentArg и io.Writer, в который записывается файл без полной буферизации.
+	// Возвращает название файла.
+	PrintDocumentTo(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg, w io.Writer) (string, *resty.Response, error)
+
+	// PrintDocumentMany выполняет асинхронный запрос на массовую печать документов.
+	// Принимает контекст, объект MassPrintArg и io.Writer, в который записывается полученный файл.
+	// Возвращает объект MassPrintResult, содержащий название файла и ошибки печати отдельных документов.
+	PrintDocumentMany(ctx context.Context, massPrintArg *MassPrintArg, w io.Writer) (*MassPrintResult, *resty.Response, error)
 }
 
 const (
@@ -1112,6 +1133,7 @@ type commissionReportInService struct {
 	endpointDelete[CommissionReportIn]
 	endpointGetByID[CommissionReportIn]
 	endpointUpdate[CommissionReportIn]
+	endpointUpdateSafe[CommissionReportIn]
 	endpointMetadata[MetaAttributesStatesSharedWrapper]
 	endpointPositions[CommissionReportInPosition]
 	endpointAttributes
@@ -1123,6 +1145,7 @@ type commissionReportInService struct {
 	endpointFiles
 	endpointTemplate[CommissionReportIn]
 	endpointEvaluate[CommissionReportIn]
+	endpointPrintDocument
 }
 
 func (service *commissionReportInService) GetReturnPositionList(ctx context.Context, id uuid.UUID, params ...*Params) (*List[CommissionReportInReturnPosition], *resty.Response, error) {
@@ -1168,6 +1191,7 @@ func NewCommissionReportInService(client *Client) CommissionReportInService {
 		endpointDelete:           endpointDelete[CommissionReportIn]{e},
 		endpointGetByID:          endpointGetByID[CommissionReportIn]{e},
 		endpointUpdate:           endpointUpdate[CommissionReportIn]{e},
+		endpointUpdateSafe:       endpointUpdateSafe[CommissionReportIn]{e},
 		endpointMetadata:         endpointMetadata[MetaAttributesStatesSharedWrapper]{e},
 		endpointPositions:        endpointPositions[CommissionReportInPosition]{e},
 		endpointAttributes:       endpointAttributes{e},
@@ -1179,5 +1203,6 @@ func NewCommissionReportInService(client *Client) CommissionReportInService {
 		endpointStates:           endpointStates{e},
 		endpointFiles:            endpointFiles{e},
 		endpointEvaluate:         endpointEvaluate[CommissionReportIn]{e},
+		endpointPrintDocument:    endpointPrintDocument{e},
 	}
 }
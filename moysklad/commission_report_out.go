@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/go-resty/resty/v2"
 	"github.com/google/uuid"
+	"io"
 	"time"
 )
 
@@ -696,6 +697,11 @@ type CommissionReportOutService interface {
 	// Возвращает изменённый выданный отчёт комиссионера.
 	Update(ctx context.Context, id uuid.UUID, commissionReportOut *CommissionReportOut, params ...*Params) (*CommissionReportOut, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение выданного отчёта комиссионера, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *CommissionReportOut, commissionReportOut *CommissionReportOut, params ...*Params) (*CommissionReportOut, *resty.Response, error)
+
 	// GetMetadata выполняет запрос на получение метаданных выданных отчётов комиссионера.
 	// Принимает контекст.
 	// Возвращает объект метаданных MetaAttributesStatesSharedWrapper.
@@ -892,6 +898,21 @@ type CommissionReportOutService interface {
 	//	- EvaluateCost     – себестоимость
 	// Возвращает шаблон документа с автозаполнением.
 	Evaluate(ctx context.Context, entity *CommissionReportOut, evaluate ...Evaluate) (*CommissionReportOut, *resty.Response, error)
+
+	// PrintDocument выполняет запрос на печать отдельного документа по шаблону печатной формы.
+	// Принимает контекст, ID документа и объект PrintDocumentArg.
+	// Возвращает объект PrintFile.
+	PrintDocument(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg) (*PrintFile, *resty.Response, error)
+
+	// PrintDocumentTo выполняет запрос на печать отдельного документа по шаблону печатной формы.
+	// Принимает контекст, ID документа, объект PrintDocumentArg и io.Writer, в который записывается файл без полной буферизации.
+	// Возвращает название файла.
+	PrintDocumentTo(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg, w io.Writer) (string, *resty.Response, error)
+
+	// PrintDocumentMany выполняет асинхронный запрос на массовую печать документов.
+	// Принимает контекст, объект MassPrintArg и io.Writer, в который записывается полученный файл.
+	// Возвращает объект MassPrintResult, содержащий название файла и ошибки печати отдельных документов.
+	PrintDocumentMany(ctx context.Context, massPrintArg *MassPrintArg, w io.Writer) (*MassPrintResult, *resty.Response, error)
 }
 
 const (
@@ -261,6 +261,11 @@ type ConsignmentService interface {
 	// Возвращает изменённую серию.
 	Update(ctx context.Context, id uuid.UUID, consignment *Consignment, params ...*Params) (*Consignment, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение серии, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Consignment, consignment *Consignment, params ...*Params) (*Consignment, *resty.Response, error)
+
 	// GetMetadata выполняет запрос на получение метаданных серий.
 	// Принимает контекст.
 	// Возвращает объект метаданных MetaAttributesWrapper.
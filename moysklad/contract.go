@@ -464,6 +464,11 @@ type ContractService interface {
 	// Возвращает изменённый договор.
 	Update(ctx context.Context, id uuid.UUID, contract *Contract, params ...*Params) (*Contract, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение договора, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Contract, contract *Contract, params ...*Params) (*Contract, *resty.Response, error)
+
 	// GetAttributeList выполняет запрос на получение списка доп полей.
 	// Принимает контекст.
 	// Возвращает объект List.
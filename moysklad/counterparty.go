@@ -856,6 +856,11 @@ type CounterpartyService interface {
 	// Возвращает изменённый контрагент.
 	Update(ctx context.Context, id uuid.UUID, counterparty *Counterparty, params ...*Params) (*Counterparty, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение контрагента, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Counterparty, counterparty *Counterparty, params ...*Params) (*Counterparty, *resty.Response, error)
+
 	// GetMetadata выполняет запрос на получение метаданных контрагентов.
 	// Принимает контекст.
 	// Возвращает объект метаданных MetaAttributesStatesSharedTagsWrapper.
@@ -1036,6 +1041,7 @@ type counterpartyService struct {
 	endpointDelete[Counterparty]
 	endpointGetByID[Counterparty]
 	endpointUpdate[Counterparty]
+	endpointUpdateSafe[Counterparty]
 	endpointMetadata[MetaAttributesStatesSharedTagsWrapper]
 	endpointAttributes
 	endpointSettings[CounterpartySettings]
@@ -1108,6 +1114,7 @@ func NewCounterpartyService(client *Client) CounterpartyService {
 		endpointDelete:           endpointDelete[Counterparty]{e},
 		endpointGetByID:          endpointGetByID[Counterparty]{e},
 		endpointUpdate:           endpointUpdate[Counterparty]{e},
+		endpointUpdateSafe:       endpointUpdateSafe[Counterparty]{e},
 		endpointMetadata:         endpointMetadata[MetaAttributesStatesSharedTagsWrapper]{e},
 		endpointAttributes:       endpointAttributes{e},
 		endpointSettings:         endpointSettings[CounterpartySettings]{e},
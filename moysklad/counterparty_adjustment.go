@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/go-resty/resty/v2"
 	"github.com/google/uuid"
+	"io"
 	"time"
 )
 
@@ -319,6 +320,11 @@ type CounterPartyAdjustmentService interface {
 	// Возвращает изменённый корректировку взаиморасчётов.
 	Update(ctx context.Context, id uuid.UUID, counterPartyAdjustment *CounterpartyAdjustment, params ...*Params) (*CounterpartyAdjustment, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение корректировки взаиморасчётов, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *CounterpartyAdjustment, counterPartyAdjustment *CounterpartyAdjustment, params ...*Params) (*CounterpartyAdjustment, *resty.Response, error)
+
 	// GetMetadata выполняет запрос на получение метаданных корректировок взаиморасчётов.
 	// Принимает контекст.
 	// Возвращает объект метаданных MetaAttributesStatesSharedWrapper.
@@ -363,6 +369,21 @@ type CounterPartyAdjustmentService interface {
 	// Принимает контекст, ID сущности/документа и множество файлов.
 	// Возвращает объект DeleteManyResponse, содержащий информацию об успешном удалении или ошибку.
 	DeleteFileMany(ctx context.Context, id uuid.UUID, files ...*File) (*DeleteManyResponse, *resty.Response, error)
+
+	// PrintDocument выполняет запрос на печать отдельного документа по шаблону печатной формы.
+	// Принимает контекст, ID документа и объект PrintDocumentArg.
+	// Возвращает объект PrintFile.
+	PrintDocument(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg) (*PrintFile, *resty.Response, error)
+
+	// PrintDocumentTo выполняет запрос на печать отдельного документа по шаблону печатной формы.
+	// Принимает контекст, ID документа, объект PrintDocumentArg и io.Writer, в который записывается файл без полной буферизации.
+	// Возвращает название файла.
+	PrintDocumentTo(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg, w io.Writer) (string, *resty.Response, error)
+
+	// PrintDocumentMany выполняет асинхронный запрос на массовую печать документов.
+	// Принимает контекст, объект MassPrintArg и io.Writer, в который записывается полученный файл.
+	// Возвращает объект MassPrintResult, содержащий название файла и ошибки печати отдельных документов.
+	PrintDocumentMany(ctx context.Context, massPrintArg *MassPrintArg, w io.Writer) (*MassPrintResult, *resty.Response, error)
 }
 
 const (
@@ -215,6 +215,11 @@ type CountryService interface {
 	// Возвращает изменённую страну.
 	Update(ctx context.Context, id uuid.UUID, country *Country, params ...*Params) (*Country, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение страны, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Country, country *Country, params ...*Params) (*Country, *resty.Response, error)
+
 	// GetNamedFilterList выполняет запрос на получение списка фильтров.
 	// Принимает контекст и опционально объект параметров запроса Params.
 	// Возвращает объект List.
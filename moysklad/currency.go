@@ -351,6 +351,11 @@ type CurrencyService interface {
 	// Возвращает изменённую валюту.
 	Update(ctx context.Context, id uuid.UUID, currency *Currency, params ...*Params) (*Currency, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение валюты, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Currency, currency *Currency, params ...*Params) (*Currency, *resty.Response, error)
+
 	// GetNamedFilterList выполняет запрос на получение списка фильтров.
 	// Принимает контекст и опционально объект параметров запроса Params.
 	// Возвращает объект List.
@@ -228,6 +228,11 @@ type CustomEntityService interface {
 	// Возвращает изменённый пользовательский справочник.
 	Update(ctx context.Context, id uuid.UUID, customEntity *CustomEntity, params ...*Params) (*CustomEntity, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение пользовательского справочника, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *CustomEntity, customEntity *CustomEntity, params ...*Params) (*CustomEntity, *resty.Response, error)
+
 	// DeleteByID выполняет запрос на удаление пользовательского справочника по ID.
 	// Принимает контекст и ID пользовательского справочника.
 	// Возвращает «true» в случае успешного удаления пользовательского справочника.
@@ -276,6 +281,7 @@ type customEntityService struct {
 	Endpoint
 	endpointCreate[CustomEntity]
 	endpointUpdate[CustomEntity]
+	endpointUpdateSafe[CustomEntity]
 	endpointDeleteByID
 	endpointDelete[CustomEntity]
 }
@@ -312,6 +318,7 @@ func NewCustomEntityService(client *Client) CustomEntityService {
 		Endpoint:           e,
 		endpointCreate:     endpointCreate[CustomEntity]{e},
 		endpointUpdate:     endpointUpdate[CustomEntity]{e},
+		endpointUpdateSafe: endpointUpdateSafe[CustomEntity]{e},
 		endpointDeleteByID: endpointDeleteByID{e},
 		endpointDelete:     endpointDelete[CustomEntity]{e},
 	}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/go-resty/resty/v2"
 	"github.com/google/uuid"
+	"io"
 	"time"
 )
 
@@ -810,6 +811,7 @@ type customerOrderService struct {
 	endpointCreateUpdateMany[CustomerOrder]
 	endpointGetByID[CustomerOrder]
 	endpointUpdate[CustomerOrder]
+	endpointUpdateSafe[CustomerOrder]
 	endpointMetadata[MetaAttributesStatesSharedWrapper]
 	endpointPositions[CustomerOrderPosition]
 	endpointAttributes
@@ -825,6 +827,7 @@ type customerOrderService struct {
 	endpointTrash
 	endpointTemplate[CustomerOrder]
 	endpointEvaluate[CustomerOrder]
+	endpointPrintDocument
 }
 
 // CustomerOrderService описывает методы сервис для работы с заказами покупателя.
@@ -873,6 +876,11 @@ type CustomerOrderService interface {
 	// Возвращает изменённый заказ покупателя.
 	Update(ctx context.Context, id uuid.UUID, customerOrder *CustomerOrder, params ...*Params) (*CustomerOrder, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение заказа покупателя, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *CustomerOrder, customerOrder *CustomerOrder, params ...*Params) (*CustomerOrder, *resty.Response, error)
+
 	// GetMetadata выполняет запрос на получение метаданных заказов покупателей.
 	// Принимает контекст.
 	// Возвращает объект метаданных MetaAttributesStatesSharedWrapper.
@@ -1099,6 +1107,21 @@ type CustomerOrderService interface {
 	//	- EvaluateCost     – себестоимость
 	// Возвращает шаблон документа с автозаполнением.
 	Evaluate(ctx context.Context, entity *CustomerOrder, evaluate ...Evaluate) (*CustomerOrder, *resty.Response, error)
+
+	// PrintDocument выполняет запрос на печать отдельного документа по шаблону печатной формы.
+	// Принимает контекст, ID документа и объект PrintDocumentArg.
+	// Возвращает объект PrintFile.
+	PrintDocument(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg) (*PrintFile, *resty.Response, error)
+
+	// PrintDocumentTo выполняет запрос на печать отдельного документа по шаблону печатной формы.
+	// Принимает контекст, ID документа, объект PrintDocumentArg и io.Writer, в который записывается файл без полной буферизации.
+	// Возвращает название файла.
+	PrintDocumentTo(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg, w io.Writer) (string, *resty.Response, error)
+
+	// PrintDocumentMany выполняет асинхронный запрос на массовую печать документов.
+	// Принимает контекст, объект MassPrintArg и io.Writer, в который записывается полученный файл.
+	// Возвращает объект MassPrintResult, содержащий название файла и ошибки печати отдельных документов.
+	PrintDocumentMany(ctx context.Context, massPrintArg *MassPrintArg, w io.Writer) (*MassPrintResult, *resty.Response, error)
 }
 
 const (
@@ -1126,6 +1149,7 @@ func NewCustomerOrderService(client *Client) CustomerOrderService {
 		endpointCreateUpdateMany: endpointCreateUpdateMany[CustomerOrder]{e},
 		endpointGetByID:          endpointGetByID[CustomerOrder]{e},
 		endpointUpdate:           endpointUpdate[CustomerOrder]{e},
+		endpointUpdateSafe:       endpointUpdateSafe[CustomerOrder]{e},
 		endpointMetadata:         endpointMetadata[MetaAttributesStatesSharedWrapper]{e},
 		endpointPositions:        endpointPositions[CustomerOrderPosition]{e},
 		endpointAttributes:       endpointAttributes{e},
@@ -1138,5 +1162,6 @@ func NewCustomerOrderService(client *Client) CustomerOrderService {
 		endpointDelete:           endpointDelete[CustomerOrder]{e},
 		endpointNamedFilter:      endpointNamedFilter{e},
 		endpointDeleteMany:       endpointDeleteMany[CustomerOrder]{e},
+		endpointPrintDocument:    endpointPrintDocument{e},
 	}
 }
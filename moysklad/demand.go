@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/go-resty/resty/v2"
 	"github.com/google/uuid"
+	"io"
 	"time"
 )
 
@@ -897,6 +898,21 @@ func (demandPosition *DemandPosition) SetVatEnabled(vatEnabled bool) *DemandPosi
 	return demandPosition
 }
 
+// SetBaseQuantity устанавливает упаковку, количество и цену позиции по количеству baseQuantity в базовых единицах
+// и цене basePrice за базовую единицу (в копейках).
+//
+// Количество и цена пересчитываются в упаковки pack с помощью [QuantityConverter];
+// если pack равен nil, количество и цена устанавливаются без изменений.
+// Возвращает ошибку, если количество не допускается единицей измерения товара или упаковки.
+func (demandPosition *DemandPosition) SetBaseQuantity(converter *QuantityConverter, baseQuantity, basePrice float64, pack *Pack) error {
+	quantity, price, err := converter.packedPosition(baseQuantity, basePrice, pack)
+	if err != nil {
+		return err
+	}
+	demandPosition.SetPack(pack).SetQuantity(quantity).SetPrice(price)
+	return nil
+}
+
 // AsSalesReturnPosition преобразует позицию отгрузки в позицию возврата покупателя.
 //
 // Копирует все поля позиции, кроме ID и AccountID.
@@ -974,6 +990,11 @@ type DemandService interface {
 	// Возвращает изменённую отгрузку.
 	Update(ctx context.Context, id uuid.UUID, demand *Demand, params ...*Params) (*Demand, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение отгрузки, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Demand, demand *Demand, params ...*Params) (*Demand, *resty.Response, error)
+
 	// Template выполняет запрос на получение предзаполненной отгрузки со стандартными полями.
 	// без связи с какими-либо другими документами.
 	// Принимает контекст.
@@ -1149,6 +1170,16 @@ type DemandService interface {
 	// Возвращает файл
 	PrintDocument(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg) (*PrintFile, *resty.Response, error)
 
+	// PrintDocumentTo выполняет запрос на печать отдельного документа по шаблону печатной формы.
+	// Принимает контекст, ID документа, объект PrintDocumentArg и io.Writer, в который записывается файл без полной буферизации.
+	// Возвращает название файла.
+	PrintDocumentTo(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg, w io.Writer) (string, *resty.Response, error)
+
+	// PrintDocumentMany выполняет асинхронный запрос на массовую печать документов.
+	// Принимает контекст, объект MassPrintArg и io.Writer, в который записывается полученный файл.
+	// Возвращает объект MassPrintResult, содержащий название файла и ошибки печати отдельных документов.
+	PrintDocumentMany(ctx context.Context, massPrintArg *MassPrintArg, w io.Writer) (*MassPrintResult, *resty.Response, error)
+
 	// GetStateByID выполняет запрос на получение статуса документа по ID.
 	// Принимает контекст и ID статуса.
 	// Возвращает найденный статус.
@@ -0,0 +1,473 @@
+package moysklad
+
+import (
+	"context"
+	"fmt"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"math"
+	"sort"
+	"strings"
+)
+
+// discountEngineBatchSize количество позиций ассортимента в одном запросе при загрузке данных корзины.
+const discountEngineBatchSize = 100
+
+// BasketLine позиция корзины.
+type BasketLine struct {
+	Assortment AssortmentConverter // Товар/Модификация/Услуга/Комплект
+	Quantity   float64             // Количество
+	Price      float64             // Цена за единицу до скидок (в копейках)
+}
+
+// AppliedDiscount скидка, действующая на позицию корзины.
+type AppliedDiscount struct {
+	Meta    Meta    // Метаданные скидки
+	Name    string  // Наименование скидки
+	Percent float64 // Процент скидки
+}
+
+// LineDiscount результат расчёта скидки позиции корзины.
+type LineDiscount struct {
+	Line      *BasketLine       // Позиция корзины
+	Discounts []AppliedDiscount // Действующие скидки, упорядоченные по убыванию процента
+	Percent   float64           // Итоговый процент скидки с учётом стратегии совместного применения
+	Price     float64           // Цена за единицу со скидкой (в копейках)
+	Sum       float64           // Сумма позиции со скидкой (в копейках)
+}
+
+// DiscountResult результат расчёта скидок и бонусов для корзины.
+type DiscountResult struct {
+	Lines           []*LineDiscount  // Позиции корзины в порядке следования
+	Strategy        DiscountStrategy // Стратегия совместного применения скидок
+	Total           float64          // Сумма корзины до скидок (в копейках)
+	Sum             float64          // Сумма корзины со скидками (в копейках)
+	BonusProgram    *BonusProgram    // Бонусная программа контрагента (nil, если контрагент не участвует)
+	BonusBalance    int              // Баланс баллов контрагента
+	MaxRedeemPoints int              // Максимальное количество баллов для списания
+	MaxRedeemAmount float64          // Сумма, оплачиваемая максимальным количеством баллов (в копейках)
+	EarnPoints      int              // Количество баллов к начислению без списания
+}
+
+// Discount возвращает сумму скидки по корзине (в копейках).
+func (discountResult DiscountResult) Discount() float64 {
+	return discountResult.Total - discountResult.Sum
+}
+
+// Earn возвращает количество баллов к начислению при списании redeemPoints баллов.
+//
+// Если бонусная программа не разрешает одновременное начисление и списание, при списании баллы не начисляются.
+func (discountResult DiscountResult) Earn(redeemPoints int) int {
+	program := discountResult.BonusProgram
+	if program == nil || program.GetEarnRateRoublesToPoint() <= 0 {
+		return 0
+	}
+	if redeemPoints > 0 && !program.GetEarnWhileRedeeming() {
+		return 0
+	}
+	payable := discountResult.Sum - redeemAmount(program, redeemPoints)
+	return int(math.Floor(payable / 100 / float64(program.GetEarnRateRoublesToPoint())))
+}
+
+// redeemAmount возвращает сумму, оплачиваемую баллами (в копейках).
+func redeemAmount(program *BonusProgram, points int) float64 {
+	if program.GetSpendRatePointsToRouble() <= 0 {
+		return 0
+	}
+	return math.Floor(float64(points)/float64(program.GetSpendRatePointsToRouble())) * 100
+}
+
+// discountRule условия и размер скидки, приведённые к общему виду.
+type discountRule struct {
+	meta         Meta
+	name         string
+	allAgents    bool
+	agentTags    Slice[string]
+	allProducts  bool
+	assortment   map[uuid.UUID]bool
+	folders      map[uuid.UUID]bool
+	levels       Slice[AccumulationLevel]
+	percent      *float64
+	specialPrice *SpecialPrice
+}
+
+// newDiscountRule возвращает правило скидки.
+func newDiscountRule(meta *Meta, name *string, allAgents, allProducts *bool, agentTags Slice[string], assortment Assortment, folders *MetaArray[ProductFolder]) *discountRule {
+	rule := &discountRule{
+		meta:        Deref(meta),
+		name:        Deref(name),
+		allAgents:   Deref(allAgents),
+		agentTags:   agentTags,
+		allProducts: Deref(allProducts),
+		assortment:  make(map[uuid.UUID]bool),
+		folders:     make(map[uuid.UUID]bool),
+	}
+	for _, position := range assortment {
+		rule.assortment[position.GetMeta().GetUUIDFromHref()] = true
+	}
+	if folders != nil {
+		for _, folder := range folders.Rows {
+			rule.folders[folder.GetMeta().GetUUIDFromHref()] = true
+		}
+	}
+	return rule
+}
+
+// matchAgent возвращает true, если скидка действует на контрагента.
+func (rule discountRule) matchAgent(counterparty *Counterparty) bool {
+	return rule.allAgents || matchTags(rule.agentTags, counterparty.GetTags())
+}
+
+// matchTags возвращает true, если списки тегов пересекаются.
+func matchTags(tags, counterpartyTags Slice[string]) bool {
+	for _, tag := range tags {
+		for _, counterpartyTag := range counterpartyTags {
+			if strings.EqualFold(Deref(tag), Deref(counterpartyTag)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchLine возвращает true, если скидка действует на позицию.
+func (rule discountRule) matchLine(line *basketLineInfo) bool {
+	if rule.allProducts || rule.assortment[line.id] || (line.productID != uuid.Nil && rule.assortment[line.productID]) {
+		return true
+	}
+	for _, folderID := range line.folders {
+		if rule.folders[folderID] {
+			return true
+		}
+	}
+	return false
+}
+
+// basketLineInfo сведения о позиции ассортимента, необходимые для применения скидок.
+type basketLineInfo struct {
+	id         uuid.UUID
+	productID  uuid.UUID
+	folders    []uuid.UUID // Группа позиции и все родительские группы
+	salePrices Slice[SalePrice]
+}
+
+// DiscountEngine выполняет расчёт скидок и бонусов для корзины без создания документов.
+//
+// Учитываются активные накопительные и персональные скидки, специальные цены и бонусная программа.
+// Скидки применяются по стратегии совместного применения из настроек компании:
+// при [DiscountStrategyBySum] проценты скидок складываются, при [DiscountStrategyByPriority]
+// действует одна наибольшая скидка.
+//
+// Скидки и бонусные программы загружаются один раз методом [DiscountEngine.Load].
+type DiscountEngine struct {
+	client        *Client
+	strategy      DiscountStrategy
+	rules         []*discountRule
+	bonusPrograms []*BonusProgram
+	folders       *FolderTree[ProductFolder]
+}
+
+// NewDiscountEngine принимает [Client] и возвращает новый объект [DiscountEngine].
+func NewDiscountEngine(client *Client) *DiscountEngine {
+	return &DiscountEngine{client: client}
+}
+
+// Load загружает настройки компании, активные скидки, бонусные программы и группы товаров.
+func (discountEngine *DiscountEngine) Load(ctx context.Context) error {
+	settings, _, err := NewContextCompanySettingsService(discountEngine.client).Get(ctx)
+	if err != nil {
+		return fmt.Errorf("discount engine: get company settings: %w", err)
+	}
+	discountEngine.strategy = settings.GetDiscountStrategy()
+
+	if discountEngine.folders, err = LoadProductFolderTree(ctx, discountEngine.client); err != nil {
+		return fmt.Errorf("discount engine: load product folders: %w", err)
+	}
+
+	discountEngine.rules = nil
+	err = forEachPage[AccumulationDiscount](ctx, discountEngine.client, EndpointAccumulationDiscount, NewParams(), func(rows Slice[AccumulationDiscount]) error {
+		for _, row := range rows {
+			if row.GetActive() {
+				rule := newDiscountRule(row.Meta, row.Name, row.AllAgents, row.AllProducts, row.AgentTags, row.Assortment, row.ProductFolders)
+				rule.levels = row.Levels
+				discountEngine.rules = append(discountEngine.rules, rule)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("discount engine: load accumulation discounts: %w", err)
+	}
+
+	err = forEachPage[PersonalDiscount](ctx, discountEngine.client, EndpointPersonalDiscount, NewParams(), func(rows Slice[PersonalDiscount]) error {
+		for _, row := range rows {
+			if row.GetActive() {
+				discountEngine.rules = append(discountEngine.rules, newDiscountRule(row.Meta, row.Name, row.AllAgents, row.AllProducts, row.AgentTags, row.Assortment, row.ProductFolders))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("discount engine: load personal discounts: %w", err)
+	}
+
+	err = forEachPage[SpecialPriceDiscount](ctx, discountEngine.client, EndpointSpecialPriceDiscount, NewParams(), func(rows Slice[SpecialPriceDiscount]) error {
+		for _, row := range rows {
+			if !row.GetActive() {
+				continue
+			}
+			rule := newDiscountRule(row.Meta, row.Name, row.AllAgents, row.AllProducts, row.AgentTags, row.Assortment, row.ProductFolders)
+			if row.GetUsePriceType() && row.SpecialPrice != nil {
+				rule.specialPrice = row.SpecialPrice
+			} else {
+				rule.percent = Float(row.GetDiscount())
+			}
+			discountEngine.rules = append(discountEngine.rules, rule)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("discount engine: load special price discounts: %w", err)
+	}
+
+	discountEngine.bonusPrograms = nil
+	err = forEachPage[BonusProgram](ctx, discountEngine.client, EndpointBonusProgram, NewParams(), func(rows Slice[BonusProgram]) error {
+		for _, row := range rows {
+			if row.GetActive() {
+				discountEngine.bonusPrograms = append(discountEngine.bonusPrograms, row)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("discount engine: load bonus programs: %w", err)
+	}
+	return nil
+}
+
+// Evaluate рассчитывает скидки и бонусы для контрагента и позиций корзины.
+//
+// Контрагент должен содержать теги, скидки (discounts), сумму продаж и бонусные баллы.
+// Если контрагент равен nil, применяются только скидки, действующие на всех контрагентов.
+func (discountEngine *DiscountEngine) Evaluate(ctx context.Context, counterparty *Counterparty, lines ...*BasketLine) (*DiscountResult, error) {
+	if counterparty == nil {
+		counterparty = new(Counterparty)
+	}
+
+	infos, err := discountEngine.loadLines(ctx, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DiscountResult{Strategy: discountEngine.strategy}
+	for _, line := range lines {
+		lineDiscount := &LineDiscount{Line: line, Price: line.Price}
+		result.Lines = append(result.Lines, lineDiscount)
+		result.Total += line.Price * line.Quantity
+
+		if info, ok := infos[line.Assortment.AsAssortment().GetMeta().GetUUIDFromHref()]; ok {
+			for _, rule := range discountEngine.rules {
+				if !rule.matchAgent(counterparty) || !rule.matchLine(info) {
+					continue
+				}
+				if percent := discountEngine.rulePercent(rule, counterparty, info, line.Price); percent > 0 {
+					lineDiscount.Discounts = append(lineDiscount.Discounts, AppliedDiscount{Meta: rule.meta, Name: rule.name, Percent: percent})
+				}
+			}
+		}
+
+		sort.SliceStable(lineDiscount.Discounts, func(i, j int) bool {
+			return lineDiscount.Discounts[i].Percent > lineDiscount.Discounts[j].Percent
+		})
+
+		for _, applied := range lineDiscount.Discounts {
+			if discountEngine.strategy == DiscountStrategyBySum {
+				lineDiscount.Percent += applied.Percent
+			} else {
+				lineDiscount.Percent = max(lineDiscount.Percent, applied.Percent)
+			}
+		}
+		lineDiscount.Percent = Clamp(lineDiscount.Percent, 0, 100)
+		lineDiscount.Price = math.Round(line.Price * (1 - lineDiscount.Percent/100))
+		lineDiscount.Sum = lineDiscount.Price * line.Quantity
+		result.Sum += lineDiscount.Sum
+	}
+
+	if program := discountEngine.bonusProgram(counterparty); program != nil {
+		result.BonusProgram = program
+		result.BonusBalance = counterparty.GetBonusPoints()
+
+		maxAmount := math.Floor(result.Sum * float64(program.GetMaxPaidRatePercents()) / 100 / 100) // в рублях
+		points := int(maxAmount) * program.GetSpendRatePointsToRouble()
+		result.MaxRedeemPoints = max(min(points, result.BonusBalance), 0)
+		result.MaxRedeemAmount = redeemAmount(program, result.MaxRedeemPoints)
+		result.EarnPoints = result.Earn(0)
+	}
+	return result, nil
+}
+
+// rulePercent возвращает процент скидки по правилу для контрагента и позиции.
+func (discountEngine *DiscountEngine) rulePercent(rule *discountRule, counterparty *Counterparty, info *basketLineInfo, price float64) float64 {
+	switch {
+	case rule.specialPrice != nil:
+		special := float64(rule.specialPrice.GetValue())
+		if priceType := rule.specialPrice.PriceType; priceType != nil {
+			priceTypeID := priceType.GetMeta().GetUUIDFromHref()
+			special = 0
+			for _, salePrice := range info.salePrices {
+				if salePrice.GetPriceType().GetMeta().GetUUIDFromHref() == priceTypeID {
+					special = salePrice.GetValue()
+					break
+				}
+			}
+		}
+		if special <= 0 || price <= 0 || special >= price {
+			return 0
+		}
+		return (price - special) / price * 100
+
+	case rule.percent != nil:
+		return *rule.percent
+	}
+
+	// скидка контрагента: персональная или накопительная
+	id := rule.meta.GetUUIDFromHref()
+	for _, discount := range counterparty.GetDiscounts() {
+		if discount.Discount == nil || discount.Discount.Meta.GetUUIDFromHref() != id {
+			continue
+		}
+		if discount.PersonalDiscount != nil {
+			return Deref(discount.PersonalDiscount)
+		}
+		if len(rule.levels) == 0 {
+			return Deref(discount.AccumulationDiscount)
+		}
+		return accumulationPercent(rule.levels, counterparty.GetSalesAmount()+Deref(discount.DemandSumCorrection))
+	}
+
+	if len(rule.levels) > 0 {
+		return accumulationPercent(rule.levels, counterparty.GetSalesAmount())
+	}
+	return 0
+}
+
+// accumulationPercent возвращает процент скидки уровня, соответствующего сумме накоплений.
+func accumulationPercent(levels Slice[AccumulationLevel], amount float64) float64 {
+	var percent, reached float64 = 0, -1
+	for _, level := range levels {
+		if level.GetAmount() <= amount && level.GetAmount() > reached {
+			percent, reached = level.GetDiscount(), level.GetAmount()
+		}
+	}
+	return percent
+}
+
+// bonusProgram возвращает бонусную программу контрагента.
+func (discountEngine *DiscountEngine) bonusProgram(counterparty *Counterparty) *BonusProgram {
+	if counterparty.BonusProgram != nil && !counterparty.BonusProgram.isNull() {
+		id := counterparty.GetBonusProgram().GetMeta().GetUUIDFromHref()
+		for _, program := range discountEngine.bonusPrograms {
+			if program.GetID() == id || program.GetMeta().GetUUIDFromHref() == id {
+				return program
+			}
+		}
+		return nil
+	}
+
+	for _, program := range discountEngine.bonusPrograms {
+		if program.GetAllAgents() || matchTags(program.GetAgentTags(), counterparty.GetTags()) {
+			return program
+		}
+	}
+	return nil
+}
+
+// basketLineRaw поля позиции ассортимента, необходимые для применения скидок.
+type basketLineRaw struct {
+	Meta          Meta             `json:"meta"`
+	ProductFolder *MetaWrapper     `json:"productFolder"`
+	Product       *MetaWrapper     `json:"product"`
+	SalePrices    Slice[SalePrice] `json:"salePrices"`
+}
+
+// loadLines загружает группы и цены продажи позиций корзины.
+//
+// Для модификаций используется группа товара.
+func (discountEngine *DiscountEngine) loadLines(ctx context.Context, lines []*BasketLine) (map[uuid.UUID]*basketLineInfo, error) {
+	infos := make(map[uuid.UUID]*basketLineInfo)
+	var ids []uuid.UUID
+	for _, line := range lines {
+		if line == nil || line.Assortment == nil {
+			return nil, fmt.Errorf("discount engine: basket line has no assortment")
+		}
+		ids = append(ids, line.Assortment.AsAssortment().GetMeta().GetUUIDFromHref())
+	}
+
+	raws, err := discountEngine.loadRaw(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var productIDs []uuid.UUID
+	for id, raw := range raws {
+		info := &basketLineInfo{id: id, salePrices: raw.SalePrices}
+		if raw.Product != nil {
+			info.productID = raw.Product.Meta.GetUUIDFromHref()
+			productIDs = append(productIDs, info.productID)
+		}
+		info.folders = discountEngine.folderPath(raw.ProductFolder)
+		infos[id] = info
+	}
+
+	if len(productIDs) > 0 {
+		products, err := discountEngine.loadRaw(ctx, productIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range infos {
+			if product, ok := products[info.productID]; ok && len(info.folders) == 0 {
+				info.folders = discountEngine.folderPath(product.ProductFolder)
+			}
+		}
+	}
+	return infos, nil
+}
+
+// folderPath возвращает ID группы и всех её родительских групп.
+func (discountEngine *DiscountEngine) folderPath(folder *MetaWrapper) []uuid.UUID {
+	if folder == nil || discountEngine.folders == nil {
+		return nil
+	}
+
+	var ids []uuid.UUID
+	for node := discountEngine.folders.ByID(folder.Meta.GetUUIDFromHref()); node != nil; node = node.Parent {
+		ids = append(ids, (*node.Folder).GetID())
+	}
+	return ids
+}
+
+// loadRaw загружает позиции ассортимента с указанными ID.
+func (discountEngine *DiscountEngine) loadRaw(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*basketLineRaw, error) {
+	raws := make(map[uuid.UUID]*basketLineRaw, len(ids))
+	for start := 0; start < len(ids); start += discountEngineBatchSize {
+		params := NewParams().WithFilterEquals("archived", "true").WithFilterEquals("archived", "false")
+		for _, id := range ids[start:min(start+discountEngineBatchSize, len(ids))] {
+			params.WithFilterEquals("id", id.String())
+		}
+
+		err := forEachPage[AssortmentPosition](ctx, discountEngine.client, EndpointAssortment, params, func(positions Slice[AssortmentPosition]) error {
+			for _, position := range positions {
+				var raw basketLineRaw
+				if err := json.Unmarshal(position.Raw(), &raw); err != nil {
+					return err
+				}
+				raws[raw.Meta.GetUUIDFromHref()] = &raw
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("discount engine: load assortment: %w", err)
+		}
+	}
+	return raws, nil
+}
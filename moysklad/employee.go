@@ -576,6 +576,11 @@ type EmployeeService interface {
 	// Возвращает изменённого сотрудника.
 	Update(ctx context.Context, id uuid.UUID, employee *Employee, params ...*Params) (*Employee, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение сотрудника, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Employee, employee *Employee, params ...*Params) (*Employee, *resty.Response, error)
+
 	// GetPermissions выполняет запрос на получение информации о правах сотрудника.
 	// Принимает контекст и ID сотрудника.
 	// Возвращает объект EmployeePermission.
@@ -630,6 +635,7 @@ type employeeService struct {
 	endpointAttributes
 	endpointGetByID[Employee]
 	endpointUpdate[Employee]
+	endpointUpdateSafe[Employee]
 }
 
 func (service *employeeService) GetPermissions(ctx context.Context, id uuid.UUID) (*EmployeePermission, *resty.Response, error) {
@@ -681,5 +687,6 @@ func NewEmployeeService(client *Client) EmployeeService {
 		endpointAttributes:       endpointAttributes{e},
 		endpointGetByID:          endpointGetByID[Employee]{e},
 		endpointUpdate:           endpointUpdate[Employee]{e},
+		endpointUpdateSafe:       endpointUpdateSafe[Employee]{e},
 	}
 }
@@ -0,0 +1,295 @@
+package moysklad
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+)
+
+// DefaultOffboardingMetaTypes коды сущностей, владелец которых передаётся преемнику при увольнении сотрудника.
+var DefaultOffboardingMetaTypes = []MetaType{
+	MetaTypeCounterparty,
+	MetaTypeContract,
+	MetaTypeCustomerOrder,
+	MetaTypeInvoiceOut,
+	MetaTypeDemand,
+	MetaTypeSalesReturn,
+	MetaTypePurchaseOrder,
+	MetaTypeInvoiceIn,
+	MetaTypeSupply,
+	MetaTypePurchaseReturn,
+	MetaTypePaymentIn,
+	MetaTypePaymentOut,
+	MetaTypeCashIn,
+	MetaTypeCashOut,
+}
+
+// EmployeeOnboarding параметры приёма сотрудника.
+type EmployeeOnboarding struct {
+	Employee     *Employee          // Данные сотрудника (поиск существующего выполняется по логину или email)
+	Role         *Role              // Роль сотрудника (пользовательская или системная)
+	Group        *Group             // Отдел сотрудника
+	RetailStores Slice[RetailStore] // Точки продаж, в которых сотрудник назначается кассиром
+}
+
+// EmployeeOnboardingResult результат приёма сотрудника.
+type EmployeeOnboardingResult struct {
+	Employee               *Employee // Созданный или восстановленный сотрудник
+	Created                bool      // Сотрудник создан
+	MailActivationRequired bool      // Для входа сотрудник должен подтвердить почту
+	RetailStores           int       // Количество точек продаж, в которых сотрудник назначен кассиром
+}
+
+// EmployeeOffboardingResult результат увольнения сотрудника.
+type EmployeeOffboardingResult struct {
+	Tasks        int              // Количество переназначенных невыполненных задач
+	Owned        map[MetaType]int // Количество объектов, переданных преемнику, по кодам сущностей
+	RetailStores int              // Количество точек продаж, из кассиров которых исключён сотрудник
+}
+
+// EmployeeLifecycle выполняет приём и увольнение сотрудников.
+//
+// При приёме сотрудник создаётся или восстанавливается из архива, получает доступ к сервису
+// с указанной ролью и отделом и назначается кассиром точек продаж.
+// При увольнении сотрудник исключается из кассиров, его невыполненные задачи и объекты
+// передаются преемнику, после чего доступ к сервису отключается.
+type EmployeeLifecycle struct {
+	client *Client
+}
+
+// NewEmployeeLifecycle принимает [Client] и возвращает новый объект [EmployeeLifecycle].
+func NewEmployeeLifecycle(client *Client) *EmployeeLifecycle {
+	return &EmployeeLifecycle{client: client}
+}
+
+// find возвращает сотрудника (в том числе архивного) с указанным логином или email или nil.
+func (employeeLifecycle *EmployeeLifecycle) find(ctx context.Context, employee *Employee) (*Employee, error) {
+	var filters []*Params
+	if employee.GetUID() != "" {
+		filters = append(filters, NewParams().WithFilterEquals("uid", employee.GetUID()))
+	}
+	if employee.GetEmail() != "" {
+		filters = append(filters, NewParams().WithFilterEquals("email", employee.GetEmail()))
+	}
+
+	for _, filter := range filters {
+		list, _, err := NewEmployeeService(employeeLifecycle.client).GetList(ctx, filter.
+			WithFilterEquals("archived", "true").
+			WithFilterEquals("archived", "false"))
+		if err != nil {
+			return nil, fmt.Errorf("employee lifecycle: find employee: %w", err)
+		}
+		if len(list.Rows) > 0 {
+			return list.Rows[0], nil
+		}
+	}
+	return nil, nil
+}
+
+// Onboard принимает сотрудника: создаёт его или восстанавливает из архива, предоставляет доступ к сервису
+// с указанными ролью и отделом и назначает кассиром точек продаж.
+//
+// Если у сотрудника уже есть доступ к сервису, изменяются только роль и отдел.
+func (employeeLifecycle *EmployeeLifecycle) Onboard(ctx context.Context, onboarding *EmployeeOnboarding) (*EmployeeOnboardingResult, error) {
+	if onboarding.Role == nil {
+		return nil, fmt.Errorf("employee lifecycle: role is required")
+	}
+
+	employeeService := NewEmployeeService(employeeLifecycle.client)
+	result := new(EmployeeOnboardingResult)
+
+	existing, err := employeeLifecycle.find(ctx, onboarding.Employee)
+	if err != nil {
+		return nil, err
+	}
+
+	// переданный объект сотрудника не изменяется; отдел передаётся только метаданными
+	employee := *onboarding.Employee
+	employee.SetArchived(false).SetGroup(onboarding.Group)
+	if existing == nil {
+		if result.Employee, _, err = employeeService.Create(ctx, &employee); err != nil {
+			return nil, fmt.Errorf("employee lifecycle: create employee: %w", err)
+		}
+		result.Created = true
+	} else {
+		if result.Employee, _, err = employeeService.Update(ctx, existing.GetID(), &employee); err != nil {
+			return nil, fmt.Errorf("employee lifecycle: update employee: %w", err)
+		}
+	}
+
+	id := result.Employee.GetID()
+	security, _, err := employeeService.GetPermissions(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("employee lifecycle: load permissions: %w", err)
+	}
+
+	permission := new(EmployeePermission).SetRole(onboarding.Role).SetGroup(onboarding.Group)
+	if security.GetActive() {
+		if _, _, err = employeeService.UpdatePermissions(ctx, id, permission); err != nil {
+			return nil, fmt.Errorf("employee lifecycle: update permissions: %w", err)
+		}
+	} else {
+		if uid := result.Employee.GetUID(); uid != "" {
+			permission.SetLogin(uid)
+		}
+		if email := result.Employee.GetEmail(); email != "" {
+			permission.SetEmail(email)
+		}
+		if result.MailActivationRequired, _, err = employeeService.Activate(ctx, id, permission); err != nil {
+			return nil, fmt.Errorf("employee lifecycle: activate employee: %w", err)
+		}
+	}
+
+	for _, retailStore := range onboarding.RetailStores {
+		changed, err := employeeLifecycle.setCashier(ctx, retailStore.GetMeta().GetUUIDFromHref(), result.Employee, true)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			result.RetailStores++
+		}
+	}
+	return result, nil
+}
+
+// Offboard увольняет сотрудника: исключает его из кассиров всех точек продаж, передаёт преемнику
+// невыполненные задачи и объекты с указанными кодами сущностей и отключает доступ к сервису.
+//
+// Если коды сущностей не указаны, используются [DefaultOffboardingMetaTypes].
+func (employeeLifecycle *EmployeeLifecycle) Offboard(ctx context.Context, employee, successor *Employee, metaTypes ...MetaType) (*EmployeeOffboardingResult, error) {
+	if successor == nil || successor.Meta == nil {
+		return nil, fmt.Errorf("employee lifecycle: successor is required")
+	}
+	if len(metaTypes) == 0 {
+		metaTypes = DefaultOffboardingMetaTypes
+	}
+
+	result := &EmployeeOffboardingResult{Owned: make(map[MetaType]int)}
+	href := employee.GetMeta().GetHref()
+
+	// кассиры
+	var retailStoreIDs []uuid.UUID
+	params := NewParams().WithExpand("cashiers").WithLimit(100)
+	err := forEachPage[RetailStore](ctx, employeeLifecycle.client, EndpointRetailStore, params, func(rows Slice[RetailStore]) error {
+		for _, retailStore := range rows {
+			for _, cashier := range retailStore.GetCashiers().Rows {
+				if cashier.Employee != nil && cashier.Employee.GetMeta().GetHref() == href {
+					retailStoreIDs = append(retailStoreIDs, retailStore.GetID())
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("employee lifecycle: load retail stores: %w", err)
+	}
+	for _, id := range retailStoreIDs {
+		if _, err = employeeLifecycle.setCashier(ctx, id, employee, false); err != nil {
+			return nil, err
+		}
+		result.RetailStores++
+	}
+
+	// задачи
+	var tasks Slice[Task]
+	params = NewParams().WithFilterEquals("assignee", href).WithFilterEquals("done", "false")
+	err = forEachPage[Task](ctx, employeeLifecycle.client, EndpointTask, params, func(rows Slice[Task]) error {
+		for _, task := range rows {
+			tasks.Push(new(Task).SetMeta(task.Meta).SetAssignee(successor))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("employee lifecycle: load tasks: %w", err)
+	}
+	for _, chunk := range tasks.IntoChunks(MaxPositions) {
+		if _, _, err = NewTaskService(employeeLifecycle.client).CreateUpdateMany(ctx, chunk); err != nil {
+			return nil, fmt.Errorf("employee lifecycle: reassign tasks: %w", err)
+		}
+	}
+	result.Tasks = len(tasks)
+
+	// владелец объектов
+	for _, metaType := range metaTypes {
+		count, err := employeeLifecycle.reassignOwner(ctx, metaType, href, successor)
+		if err != nil {
+			return nil, err
+		}
+		result.Owned[metaType] = count
+	}
+
+	if _, _, err = NewEmployeeService(employeeLifecycle.client).Deactivate(ctx, employee.GetMeta().GetUUIDFromHref()); err != nil {
+		return nil, fmt.Errorf("employee lifecycle: deactivate employee: %w", err)
+	}
+	return result, nil
+}
+
+// ownerUpdate изменение владельца объекта при массовом изменении.
+type ownerUpdate struct {
+	Meta  *Meta     `json:"meta"`
+	Owner *Employee `json:"owner"`
+}
+
+// reassignOwner передаёт преемнику объекты сущности с владельцем href и возвращает их количество.
+func (employeeLifecycle *EmployeeLifecycle) reassignOwner(ctx context.Context, metaType MetaType, href string, successor *Employee) (int, error) {
+	path := EndpointEntity + string(metaType)
+
+	// объекты собираются до изменения, так как изменённые объекты выпадают из выборки
+	var updates Slice[ownerUpdate]
+	params := NewParams().WithFilterEquals("owner", href)
+	err := forEachPage[MetaWrapper](ctx, employeeLifecycle.client, path, params, func(rows Slice[MetaWrapper]) error {
+		for _, row := range rows {
+			meta := row.GetMeta()
+			updates.Push(&ownerUpdate{Meta: &meta, Owner: successor.Clean()})
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("employee lifecycle: load %s: %w", metaType, err)
+	}
+
+	for _, chunk := range updates.IntoChunks(MaxPositions) {
+		if _, _, err = NewRequestBuilder[any](employeeLifecycle.client, path).Post(ctx, chunk); err != nil {
+			return 0, fmt.Errorf("employee lifecycle: reassign %s: %w", metaType, err)
+		}
+	}
+	return len(updates), nil
+}
+
+// setCashier добавляет сотрудника в кассиры точки продаж или исключает его из них
+// и возвращает true, если состав кассиров изменился.
+func (employeeLifecycle *EmployeeLifecycle) setCashier(ctx context.Context, retailStoreID uuid.UUID, employee *Employee, member bool) (bool, error) {
+	service := NewRetailStoreService(employeeLifecycle.client)
+	retailStore, _, err := service.GetByID(ctx, retailStoreID, NewParams().WithExpand("cashiers"))
+	if err != nil {
+		return false, fmt.Errorf("employee lifecycle: get retail store: %w", err)
+	}
+
+	href := employee.GetMeta().GetHref()
+	var cashiers Slice[Cashier]
+	found := false
+	for _, cashier := range retailStore.GetCashiers().Rows {
+		if cashier.Employee == nil {
+			continue
+		}
+		if cashier.Employee.GetMeta().GetHref() == href {
+			found = true
+			if !member {
+				continue
+			}
+		}
+		cashiers.Push(&Cashier{Employee: cashier.Employee.Clean()})
+	}
+	if found == member {
+		return false, nil
+	}
+	if member {
+		cashiers.Push(&Cashier{Employee: employee.Clean()})
+	}
+
+	update := new(RetailStore).SetCashiers(cashiers...)
+	if _, _, err = service.Update(ctx, retailStoreID, update); err != nil {
+		return false, fmt.Errorf("employee lifecycle: update cashiers of %s: %w", retailStore.GetName(), err)
+	}
+	return true, nil
+}
@@ -3,13 +3,17 @@ package moysklad
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"github.com/go-resty/resty/v2"
+	"github.com/goccy/go-json"
 	"github.com/google/uuid"
 	"io"
 	"net/http"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 )
 
 const (
@@ -73,6 +77,7 @@ type mainService[E MetaIDOwner, P any, M any, S any] struct {
 	endpointDelete[E]
 	endpointGetByID[E]
 	endpointUpdate[E]
+	endpointUpdateSafe[E]
 	endpointMetadata[M]
 	endpointAttributes
 	endpointNamedFilter
@@ -106,6 +111,7 @@ func newMainService[E MetaIDOwner, P any, M any, S any](client *Client, path str
 		endpointDelete:           endpointDelete[E]{endpoint},
 		endpointGetByID:          endpointGetByID[E]{endpoint},
 		endpointUpdate:           endpointUpdate[E]{endpoint},
+		endpointUpdateSafe:       endpointUpdateSafe[E]{endpoint},
 		endpointMetadata:         endpointMetadata[M]{endpoint},
 		endpointAttributes:       endpointAttributes{endpoint},
 		endpointNamedFilter:      endpointNamedFilter{endpoint},
@@ -287,8 +293,167 @@ func (endpoint *endpointCreateUpdateMany[T]) CreateUpdateMany(ctx context.Contex
 type endpointUpdate[T any] struct{ Endpoint }
 
 // Update выполняет запрос на изменение объекта.
+//
+// Если к клиенту подключена машина состояний для сущности ([Client.WithStateMachine]),
+// изменение проверяется ею перед выполнением запроса.
 func (endpoint *endpointUpdate[T]) Update(ctx context.Context, id uuid.UUID, entity *T, params ...*Params) (*T, *resty.Response, error) {
+	if stateMachine := endpoint.client.stateMachine(endpoint.uri); stateMachine != nil {
+		return stateMachineUpdate(ctx, stateMachine, endpoint.client, id, entity, params...)
+	}
+	path := fmt.Sprintf("%s/%s", endpoint.uri, id)
+	return NewRequestBuilder[T](endpoint.client, path).SetParams(params...).Put(ctx, entity)
+}
+
+// UpdatedGetter описывает метод, возвращающий момент последнего обновления объекта.
+type UpdatedGetter interface {
+	GetUpdated() time.Time
+}
+
+// isSameVersion сравнивает две версии объекта.
+//
+// Если объект реализует интерфейс [UpdatedGetter] и момент последнего обновления заполнен у обеих версий,
+// версии сравниваются по нему. В противном случае сравнивается хэш содержимого объектов
+// без учёта раскрытых (expand) вложенных сущностей.
+func isSameVersion[T any](expected, current *T) bool {
+	if expected == nil || current == nil {
+		return expected == current
+	}
+
+	e, eOk := any(*expected).(UpdatedGetter)
+	c, cOk := any(*current).(UpdatedGetter)
+	if eOk && cOk && !e.GetUpdated().IsZero() && !c.GetUpdated().IsZero() {
+		return e.GetUpdated().Equal(c.GetUpdated())
+	}
+
+	return contentHash(expected) == contentHash(current)
+}
+
+// expandedCollections возвращает наименования полей объекта, содержащих загруженные элементы коллекций.
+func expandedCollections(v any) []string {
+	b, _ := json.Marshal(v)
+
+	var fields map[string]any
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil
+	}
+
+	var names []string
+	for name, value := range fields {
+		if collection, ok := value.(map[string]any); ok && collection["meta"] != nil && collection["rows"] != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// contentHash возвращает хэш сериализованного в JSON объекта.
+//
+// Ссылки на вложенные сущности сводятся к их метаданным, поэтому объект, загруженный с параметром expand,
+// имеет тот же хэш, что и объект, загруженный без него. Содержимое загруженных коллекций
+// (например, позиций документа) учитывается.
+func contentHash(v any) [sha256.Size]byte {
+	b, _ := json.Marshal(v)
+
+	var data any
+	if err := json.Unmarshal(b, &data); err == nil {
+		if root, ok := data.(map[string]any); ok {
+			b, _ = json.Marshal(collapseFields(root))
+		}
+	}
+
+	return sha256.Sum256(b)
+}
+
+// collapseFields сводит ссылки на вложенные сущности в полях объекта к их метаданным.
+func collapseFields(fields map[string]any) map[string]any {
+	for key, value := range fields {
+		fields[key] = collapseExpanded(value)
+	}
+	return fields
+}
+
+// collapseExpanded заменяет ссылки на сущности (объекты с метаданными без поля rows) объектом с одним полем meta.
+// У дополнительных полей сохраняется значение value.
+//
+// У коллекций (объекты с метаданными, содержащими размер коллекции) сохраняются ссылка и размер коллекции,
+// а загруженные элементы коллекции (поле rows) учитываются полностью.
+func collapseExpanded(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		meta, ok := v["meta"]
+		if !ok {
+			return collapseFields(v)
+		}
+		m, _ := meta.(map[string]any)
+		if _, ok := m["size"]; ok {
+			// коллекция: параметры постраничного вывода не учитываются
+			collection := map[string]any{"meta": map[string]any{"href": m["href"], "size": m["size"]}}
+			rows, ok := v["rows"].([]any)
+			if !ok {
+				return collection
+			}
+			collection["rows"] = rows
+			for i, row := range rows {
+				if fields, ok := row.(map[string]any); ok {
+					rows[i] = collapseFields(fields)
+				} else {
+					rows[i] = collapseExpanded(row)
+				}
+			}
+			return collection
+		}
+		collapsed := map[string]any{"meta": meta}
+		if attrValue, ok := v["value"]; ok {
+			collapsed["value"] = collapseExpanded(attrValue)
+		}
+		return collapsed
+	case []any:
+		for i, nested := range v {
+			v[i] = collapseExpanded(nested)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+type endpointUpdateSafe[T MetaIDOwner] struct{ Endpoint }
+
+// UpdateIfUnchanged выполняет запрос на изменение объекта, если он не был изменён с момента загрузки.
+//
+// Перед изменением повторно запрашивает объект и сравнивает поле updated (или хэш содержимого,
+// если поле недоступно) с версией expected, загруженной вызывающей стороной.
+// Коллекции, загруженные в expected (например, позиции документа), запрашиваются повторно и сравниваются по содержимому.
+// При несовпадении версий изменение не выполняется и возвращается ошибка [ConflictError].
+//
+// Проверка и изменение выполняются двумя отдельными запросами, поэтому метод снижает,
+// но не исключает полностью вероятность потери изменений.
+func (endpoint *endpointUpdateSafe[T]) UpdateIfUnchanged(ctx context.Context, expected *T, entity *T, params ...*Params) (*T, *resty.Response, error) {
+	id := GetUUIDFromEntity(expected)
+	if id == uuid.Nil {
+		return nil, nil, fmt.Errorf("update if unchanged: expected entity has no id")
+	}
+
+	// коллекции, загруженные в expected (например, позиции документа), загружаются и для сравнения
+	getParams := NewParams()
+	if names := expandedCollections(expected); len(names) > 0 {
+		getParams.WithExpand(names...)
+	}
+
 	path := fmt.Sprintf("%s/%s", endpoint.uri, id)
+	current, resp, err := NewRequestBuilder[T](endpoint.client, path).SetParams(getParams).Get(ctx)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	if !isSameVersion(expected, current) {
+		return nil, resp, &ConflictError[T]{ID: id, Expected: expected, Current: current}
+	}
+
+	if stateMachine := endpoint.client.stateMachine(endpoint.uri); stateMachine != nil {
+		return stateMachineUpdate(ctx, stateMachine, endpoint.client, id, entity, params...)
+	}
 	return NewRequestBuilder[T](endpoint.client, path).SetParams(params...).Put(ctx, entity)
 }
 
@@ -616,13 +781,17 @@ func printFileFromResp(resp *resty.Response) (*PrintFile, *resty.Response, error
 		return nil, resp, err
 	}
 
-	var fileName string
+	file := &PrintFile{buf, fileNameFromResp(resp)}
+	return file, resp, nil
+}
+
+// fileNameFromResp возвращает название файла из заголовка Content-Disposition.
+func fileNameFromResp(resp *resty.Response) string {
 	headerStr := resp.Header().Get(headerContentDisposition)
 	if match := reContentDisposition.FindStringSubmatch(headerStr); len(match) > 1 {
-		fileName = match[1]
+		return match[1]
 	}
-	file := &PrintFile{buf, fileName}
-	return file, resp, nil
+	return ""
 }
 
 // PrintDocument выполняет запрос на печать документа.
@@ -639,6 +808,62 @@ func (endpoint *endpointPrintDocument) PrintDocument(ctx context.Context, id uui
 	return printFileFromResp(resp)
 }
 
+// PrintDocumentTo выполняет запрос на печать документа и записывает полученный файл в w без полной буферизации в памяти.
+//
+// Возвращает название файла.
+//
+// [Документация МойСклад]
+//
+// [Документация МойСклад]: https://dev.moysklad.ru/doc/api/remap/1.2/documents/#dokumenty-pechat-dokumentow-zapros-na-pechat
+func (endpoint *endpointPrintDocument) PrintDocumentTo(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg, w io.Writer) (string, *resty.Response, error) {
+	path := fmt.Sprintf(EndpointExport, endpoint.uri, id)
+	resp, err := NewRequestBuilder[any](endpoint.client, path).SetHeader(headerGetContent, "true").Stream(ctx, http.MethodPost, PrintDocumentArg, w)
+	if err != nil {
+		return "", resp, err
+	}
+	return fileNameFromResp(resp), resp, nil
+}
+
+// PrintDocumentMany выполняет запрос на массовую печать документов одного типа.
+//
+// Запрос выполняется асинхронно: метод ожидает завершения асинхронной задачи,
+// после чего записывает полученный файл (ZIP-архив или объединённый документ) в w.
+//
+// Ошибки печати отдельных документов возвращаются в поле Failures объекта [MassPrintResult].
+//
+// [Документация МойСклад]
+//
+// [Документация МойСклад]: https://dev.moysklad.ru/doc/api/remap/1.2/documents/#dokumenty-pechat-dokumentow-massowaq-pechat
+func (endpoint *endpointPrintDocument) PrintDocumentMany(ctx context.Context, massPrintArg *MassPrintArg, w io.Writer) (*MassPrintResult, *resty.Response, error) {
+	path := fmt.Sprintf("%s/export", endpoint.uri)
+	asyncResult, resp, err := NewRequestBuilder[any](endpoint.client, path).AsyncPost(ctx, massPrintArg)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	async, resp, err := waitAsync(ctx, endpoint.client, asyncResult.StatusURL())
+	if err != nil {
+		return nil, resp, err
+	}
+
+	result := &MassPrintResult{Failures: newMassPrintFailures(async.Errors)}
+	if async.State != AsyncStateDone {
+		return result, resp, fmt.Errorf("mass print: async task finished with state %s", async.State)
+	}
+
+	resultURL := async.ResultURL
+	if resultURL == "" {
+		resultURL = asyncResult.ResultURL()
+	}
+
+	resp, err = NewRequestBuilder[any](endpoint.client, resultURL).Stream(ctx, http.MethodGet, nil, w)
+	if err != nil {
+		return result, resp, err
+	}
+	result.FileName = fileNameFromResp(resp)
+	return result, resp, nil
+}
+
 type endpointPrintLabel struct{ Endpoint }
 
 // PrintLabel выполняет запрос на печать этикеток и ценников.
@@ -659,6 +884,22 @@ func (endpoint *endpointPrintLabel) PrintLabel(ctx context.Context, id uuid.UUID
 	return printFileFromResp(resp)
 }
 
+// PrintLabelTo выполняет запрос на печать этикеток и ценников и записывает полученный файл в w без полной буферизации в памяти.
+//
+// Возвращает название файла.
+//
+// [Документация МойСклад]
+//
+// [Документация МойСклад]: https://dev.moysklad.ru/doc/api/remap/1.2/dictionaries/#suschnosti-pechat-atiketok-i-cennikow
+func (endpoint *endpointPrintLabel) PrintLabelTo(ctx context.Context, id uuid.UUID, PrintLabelArg *PrintLabelArg, w io.Writer) (string, *resty.Response, error) {
+	path := fmt.Sprintf(EndpointExport, endpoint.uri, id)
+	resp, err := NewRequestBuilder[any](endpoint.client, path).SetHeader(headerGetContent, "true").Stream(ctx, http.MethodPost, PrintLabelArg, w)
+	if err != nil {
+		return "", resp, err
+	}
+	return fileNameFromResp(resp), resp, nil
+}
+
 type endpointPublication struct{ Endpoint }
 
 // GetPublicationList выполняет запрос на получение списка Публикаций по указанному документу.
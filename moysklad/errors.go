@@ -1,7 +1,9 @@
 package moysklad
 
 import (
+	"fmt"
 	"github.com/goccy/go-json"
+	"github.com/google/uuid"
 )
 
 // ApiError Структура ошибки API МойСклад.
@@ -36,3 +38,18 @@ func (apiErrors ApiErrors) Error() string {
 	b, _ := json.Marshal(apiErrors)
 	return string(b)
 }
+
+// ConflictError ошибка конкурентного изменения объекта.
+//
+// Возвращается методом UpdateIfUnchanged, если объект был изменён после того, как его загрузила вызывающая сторона.
+// Содержит обе версии объекта, что позволяет выполнить слияние изменений или повторить попытку.
+type ConflictError[T any] struct {
+	ID       uuid.UUID // ID объекта
+	Expected *T        // Версия объекта, загруженная вызывающей стороной
+	Current  *T        // Актуальная версия объекта
+}
+
+// Error реализует интерфейс error.
+func (conflictError *ConflictError[T]) Error() string {
+	return fmt.Sprintf("update %s: object has been changed since it was loaded", conflictError.ID)
+}
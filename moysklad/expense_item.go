@@ -175,6 +175,11 @@ type ExpenseItemService interface {
 	// Возвращает изменённую статью расходов.
 	Update(ctx context.Context, id uuid.UUID, expenseItem *ExpenseItem, params ...*Params) (*ExpenseItem, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение статьи расходов, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *ExpenseItem, expenseItem *ExpenseItem, params ...*Params) (*ExpenseItem, *resty.Response, error)
+
 	// MoveToTrash выполняет запрос на перемещение документа с указанным ID в корзину.
 	// Принимает контекст и ID документа.
 	// Возвращает «true» в случае успешного перемещения в корзину.
@@ -0,0 +1,374 @@
+package moysklad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"sort"
+	"strings"
+)
+
+// FolderPathSeparator разделитель наименований групп в пути.
+const FolderPathSeparator = "/"
+
+// FolderEntity описывает методы группы (группы товаров или группы тех. карт), необходимые для построения дерева.
+type FolderEntity interface {
+	GetID() uuid.UUID
+	GetMeta() Meta
+	GetName() string
+	GetPathName() string
+}
+
+// FolderNode узел дерева групп.
+type FolderNode[T FolderEntity] struct {
+	Folder       *T               // Группа
+	Parent       *FolderNode[T]   // Родительский узел (nil для корневых групп)
+	Children     []*FolderNode[T] // Дочерние узлы, упорядоченные по наименованию
+	ProductCount int              // Количество товаров непосредственно в группе (заполняется методом CountProducts)
+}
+
+// Name возвращает наименование группы.
+func (folderNode FolderNode[T]) Name() string {
+	return (*folderNode.Folder).GetName()
+}
+
+// Path возвращает полный путь группы, включая её наименование.
+func (folderNode FolderNode[T]) Path() string {
+	if folderNode.Parent == nil {
+		return folderNode.Name()
+	}
+	return folderNode.Parent.Path() + FolderPathSeparator + folderNode.Name()
+}
+
+// Depth возвращает глубину узла (0 для корневых групп).
+func (folderNode FolderNode[T]) Depth() int {
+	if folderNode.Parent == nil {
+		return 0
+	}
+	return folderNode.Parent.Depth() + 1
+}
+
+// TotalProductCount возвращает количество товаров в группе и всех её подгруппах.
+func (folderNode FolderNode[T]) TotalProductCount() int {
+	count := folderNode.ProductCount
+	for _, child := range folderNode.Children {
+		count += child.TotalProductCount()
+	}
+	return count
+}
+
+// Walk обходит узел и его потомков в глубину; обход поддерева прекращается, если fn возвращает false.
+func (folderNode *FolderNode[T]) Walk(fn func(node *FolderNode[T]) bool) {
+	if !fn(folderNode) {
+		return
+	}
+	for _, child := range folderNode.Children {
+		child.Walk(fn)
+	}
+}
+
+// folderTreeAdapter операции над группами конкретного типа.
+//
+// Если функция parentID не задана, родительская группа определяется по полю pathName.
+type folderTreeAdapter[T FolderEntity] struct {
+	endpoint string
+	parentID func(folder *T) uuid.UUID
+	create   func(ctx context.Context, name string, parent *T) (*T, error)
+	move     func(ctx context.Context, folder *T, parent *T) (*T, error)
+}
+
+// FolderTree дерево групп, построенное в памяти по плоскому списку.
+//
+// Иерархия групп товаров ([ProductFolder]) восстанавливается по ссылке на родительскую группу,
+// поэтому одноимённые группы и наименования, содержащие «/», не нарушают структуру дерева.
+// Группы тех. карт ([ProcessingPlanFolder]) не содержат ссылки на родителя, их иерархия восстанавливается по полю pathName.
+type FolderTree[T FolderEntity] struct {
+	client  *Client
+	adapter folderTreeAdapter[T]
+	Roots   []*FolderNode[T] // Корневые узлы, упорядоченные по наименованию
+	byID    map[uuid.UUID]*FolderNode[T]
+}
+
+// ErrFolderNesting ошибка создания или перемещения вложенной группы для типа групп, не поддерживающего вложенность.
+var ErrFolderNesting = errors.New("folder tree: nested folders are not supported")
+
+// LoadProductFolderTree выполняет загрузку всех групп товаров и возвращает их дерево.
+func LoadProductFolderTree(ctx context.Context, client *Client) (*FolderTree[ProductFolder], error) {
+	service := NewProductFolderService(client)
+	adapter := folderTreeAdapter[ProductFolder]{
+		endpoint: EndpointProductFolder,
+		parentID: func(folder *ProductFolder) uuid.UUID {
+			if folder.ProductFolder == nil || folder.ProductFolder.isNull() {
+				return uuid.Nil
+			}
+			return folder.GetProductFolder().GetMeta().GetUUIDFromHref()
+		},
+		create: func(ctx context.Context, name string, parent *ProductFolder) (*ProductFolder, error) {
+			folder := new(ProductFolder).SetName(name)
+			if parent != nil {
+				folder.SetProductFolder(parent.Clean())
+			}
+			created, _, err := service.Create(ctx, folder)
+			return created, err
+		},
+		move: func(ctx context.Context, folder *ProductFolder, parent *ProductFolder) (*ProductFolder, error) {
+			// nil устанавливает null: группа перемещается в корень
+			if parent != nil {
+				parent = parent.Clean()
+			}
+			update := new(ProductFolder).SetProductFolder(parent)
+			updated, _, err := service.Update(ctx, folder.GetID(), update)
+			return updated, err
+		},
+	}
+	return loadFolderTree(ctx, client, adapter)
+}
+
+// LoadProcessingPlanFolderTree выполняет загрузку всех групп тех. карт и возвращает их дерево.
+//
+// Сущность группы тех. карт не содержит ссылки на родительскую группу,
+// поэтому создание вложенных групп и перемещение групп не поддерживаются.
+func LoadProcessingPlanFolderTree(ctx context.Context, client *Client) (*FolderTree[ProcessingPlanFolder], error) {
+	service := NewProcessingPlanFolderService(client)
+	adapter := folderTreeAdapter[ProcessingPlanFolder]{
+		endpoint: EndpointProcessingPlanFolder,
+		create: func(ctx context.Context, name string, parent *ProcessingPlanFolder) (*ProcessingPlanFolder, error) {
+			if parent != nil {
+				return nil, ErrFolderNesting
+			}
+			created, _, err := service.Create(ctx, new(ProcessingPlanFolder).SetName(name))
+			return created, err
+		},
+		move: func(context.Context, *ProcessingPlanFolder, *ProcessingPlanFolder) (*ProcessingPlanFolder, error) {
+			return nil, ErrFolderNesting
+		},
+	}
+	return loadFolderTree(ctx, client, adapter)
+}
+
+// loadFolderTree загружает группы и строит дерево.
+func loadFolderTree[T FolderEntity](ctx context.Context, client *Client, adapter folderTreeAdapter[T]) (*FolderTree[T], error) {
+	var folders []*T
+	err := forEachPage[T](ctx, client, adapter.endpoint, NewParams(), func(rows Slice[T]) error {
+		folders = append(folders, rows...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &FolderTree[T]{client: client, adapter: adapter, byID: make(map[uuid.UUID]*FolderNode[T])}
+
+	if adapter.parentID != nil {
+		nodes := make(map[uuid.UUID]*FolderNode[T], len(folders))
+		for _, folder := range folders {
+			nodes[(*folder).GetID()] = &FolderNode[T]{Folder: folder}
+		}
+		for _, folder := range folders {
+			var parent *FolderNode[T]
+			if parentID := adapter.parentID(folder); parentID != uuid.Nil {
+				if parent = nodes[parentID]; parent == nil {
+					return nil, fmt.Errorf("folder tree: parent %s of %q not found", parentID, (*folder).GetName())
+				}
+			}
+			tree.attach(nodes[(*folder).GetID()], parent)
+		}
+		return tree, nil
+	}
+
+	// родители обрабатываются раньше потомков: сортировка по глубине пути
+	sort.SliceStable(folders, func(i, j int) bool {
+		return folderDepth((*folders[i]).GetPathName()) < folderDepth((*folders[j]).GetPathName())
+	})
+
+	for _, folder := range folders {
+		var parent *FolderNode[T]
+		if pathName := (*folder).GetPathName(); pathName != "" {
+			if parent = tree.Find(pathName); parent == nil {
+				return nil, fmt.Errorf("folder tree: parent %q of %q not found", pathName, (*folder).GetName())
+			}
+		}
+		tree.attach(&FolderNode[T]{Folder: folder}, parent)
+	}
+	return tree, nil
+}
+
+// folderDepth возвращает количество уровней в пути.
+func folderDepth(path string) int {
+	if path == "" {
+		return 0
+	}
+	return strings.Count(path, FolderPathSeparator) + 1
+}
+
+// attach добавляет узел к родителю с сохранением порядка по наименованию.
+func (folderTree *FolderTree[T]) attach(node *FolderNode[T], parent *FolderNode[T]) {
+	node.Parent = parent
+	siblings := &folderTree.Roots
+	if parent != nil {
+		siblings = &parent.Children
+	}
+	*siblings = append(*siblings, node)
+	sort.SliceStable(*siblings, func(i, j int) bool {
+		return (*siblings)[i].Name() < (*siblings)[j].Name()
+	})
+	folderTree.byID[(*node.Folder).GetID()] = node
+}
+
+// detach удаляет узел из списка дочерних узлов родителя.
+func (folderTree *FolderTree[T]) detach(node *FolderNode[T]) {
+	siblings := &folderTree.Roots
+	if node.Parent != nil {
+		siblings = &node.Parent.Children
+	}
+	for i, sibling := range *siblings {
+		if sibling == node {
+			*siblings = append((*siblings)[:i], (*siblings)[i+1:]...)
+			break
+		}
+	}
+	node.Parent = nil
+}
+
+// ByID возвращает узел группы с указанным ID или nil.
+func (folderTree *FolderTree[T]) ByID(id uuid.UUID) *FolderNode[T] {
+	return folderTree.byID[id]
+}
+
+// Find возвращает узел группы по полному пути (через «/») или nil.
+//
+// Наименования сравниваются без учёта регистра.
+func (folderTree *FolderTree[T]) Find(path string) *FolderNode[T] {
+	var node *FolderNode[T]
+	nodes := folderTree.Roots
+	for _, name := range splitFolderPath(path) {
+		node = nil
+		for _, candidate := range nodes {
+			if strings.EqualFold(candidate.Name(), name) {
+				node = candidate
+				break
+			}
+		}
+		if node == nil {
+			return nil
+		}
+		nodes = node.Children
+	}
+	return node
+}
+
+// Walk обходит все узлы дерева в глубину.
+func (folderTree *FolderTree[T]) Walk(fn func(node *FolderNode[T]) bool) {
+	for _, root := range folderTree.Roots {
+		root.Walk(fn)
+	}
+}
+
+// splitFolderPath разбивает путь на наименования групп, отбрасывая пустые элементы.
+func splitFolderPath(path string) []string {
+	var names []string
+	for _, name := range strings.Split(path, FolderPathSeparator) {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// EnsurePath возвращает узел группы с указанным путём, создавая отсутствующие группы.
+func (folderTree *FolderTree[T]) EnsurePath(ctx context.Context, path string) (*FolderNode[T], error) {
+	var node *FolderNode[T]
+	for _, name := range splitFolderPath(path) {
+		var next *FolderNode[T]
+		siblings := folderTree.Roots
+		if node != nil {
+			siblings = node.Children
+		}
+		for _, candidate := range siblings {
+			if strings.EqualFold(candidate.Name(), name) {
+				next = candidate
+				break
+			}
+		}
+
+		if next == nil {
+			var parent *T
+			if node != nil {
+				parent = node.Folder
+			}
+			folder, err := folderTree.adapter.create(ctx, name, parent)
+			if err != nil {
+				return nil, fmt.Errorf("folder tree: create %q: %w", name, err)
+			}
+			next = &FolderNode[T]{Folder: folder}
+			folderTree.attach(next, node)
+		}
+		node = next
+	}
+
+	if node == nil {
+		return nil, fmt.Errorf("folder tree: empty path")
+	}
+	return node, nil
+}
+
+// Move перемещает группу со всеми подгруппами в группу parent (nil – в корень).
+func (folderTree *FolderTree[T]) Move(ctx context.Context, node *FolderNode[T], parent *FolderNode[T]) error {
+	for ancestor := parent; ancestor != nil; ancestor = ancestor.Parent {
+		if ancestor == node {
+			return fmt.Errorf("folder tree: cannot move %q into itself", node.Path())
+		}
+	}
+
+	var parentFolder *T
+	if parent != nil {
+		parentFolder = parent.Folder
+	}
+
+	folder, err := folderTree.adapter.move(ctx, node.Folder, parentFolder)
+	if err != nil {
+		return fmt.Errorf("folder tree: move %q: %w", node.Path(), err)
+	}
+	if folder != nil {
+		node.Folder = folder
+	}
+
+	folderTree.detach(node)
+	folderTree.attach(node, parent)
+	return nil
+}
+
+// CountProducts заполняет количество товаров в группах по списку ассортимента.
+//
+// Учитываются товары, комплекты и услуги; модификации относятся к группе своего товара и не учитываются.
+func (folderTree *FolderTree[T]) CountProducts(ctx context.Context) error {
+	folderTree.Walk(func(node *FolderNode[T]) bool {
+		node.ProductCount = 0
+		return true
+	})
+
+	params := NewParams().
+		WithFilterEquals("type", MetaTypeProduct.String()).
+		WithFilterEquals("type", MetaTypeBundle.String()).
+		WithFilterEquals("type", MetaTypeService.String())
+
+	return forEachPage[AssortmentPosition](ctx, folderTree.client, EndpointAssortment, params, func(positions Slice[AssortmentPosition]) error {
+		for _, position := range positions {
+			var raw struct {
+				ProductFolder *MetaWrapper `json:"productFolder"`
+			}
+			if err := json.Unmarshal(position.Raw(), &raw); err != nil {
+				return err
+			}
+			if raw.ProductFolder == nil {
+				continue
+			}
+			if node := folderTree.ByID(raw.ProductFolder.Meta.GetUUIDFromHref()); node != nil {
+				node.ProductCount++
+			}
+		}
+		return nil
+	})
+}
@@ -132,6 +132,11 @@ type GroupService interface {
 	// Принимает контекст, отдел и опционально объект параметров запроса Params.
 	// Возвращает изменённый отдел.
 	Update(ctx context.Context, id uuid.UUID, group *Group, params ...*Params) (*Group, *resty.Response, error)
+
+	// UpdateIfUnchanged выполняет запрос на изменение отдела, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Group, group *Group, params ...*Params) (*Group, *resty.Response, error)
 }
 
 const (
@@ -267,6 +267,11 @@ func (nullValue NullValue[T]) getValue() T {
 	return Deref(nullValue.value)
 }
 
+// valueAny возвращает указатель на значение поля в виде any.
+func (nullValue *NullValue[T]) valueAny() any {
+	return nullValue.value
+}
+
 // setValue устанавливает значение поля.
 func (nullValue *NullValue[T]) setValue(value *T) *NullValue[T] {
 	if value == nil {
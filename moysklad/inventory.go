@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/go-resty/resty/v2"
 	"github.com/google/uuid"
+	"io"
 	"net/http"
 	"time"
 )
@@ -505,6 +506,11 @@ type InventoryService interface {
 	// Возвращает изменённую инвентаризацию.
 	Update(ctx context.Context, id uuid.UUID, inventory *Inventory, params ...*Params) (*Inventory, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение инвентаризации, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Inventory, inventory *Inventory, params ...*Params) (*Inventory, *resty.Response, error)
+
 	// Template выполняет запрос на получение предзаполненной инвентаризации со стандартными полями без связи с какими-либо другими документами.
 	// Принимает контекст.
 	// Возвращает предзаполненную инвентаризацию.
@@ -656,6 +662,21 @@ type InventoryService interface {
 	//	- EvaluateCost     – себестоимость
 	// Возвращает шаблон документа с автозаполнением.
 	Evaluate(ctx context.Context, entity *Inventory, evaluate ...Evaluate) (*Inventory, *resty.Response, error)
+
+	// PrintDocument выполняет запрос на печать отдельного документа по шаблону печатной формы.
+	// Принимает контекст, ID документа и объект PrintDocumentArg.
+	// Возвращает объект PrintFile.
+	PrintDocument(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg) (*PrintFile, *resty.Response, error)
+
+	// PrintDocumentTo выполняет запрос на печать отдельного документа по шаблону печатной формы.
+	// Принимает контекст, ID документа, объект PrintDocumentArg и io.Writer, в который записывается файл без полной буферизации.
+	// Возвращает название файла.
+	PrintDocumentTo(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg, w io.Writer) (string, *resty.Response, error)
+
+	// PrintDocumentMany выполняет асинхронный запрос на массовую печать документов.
+	// Принимает контекст, объект MassPrintArg и io.Writer, в который записывается полученный файл.
+	// Возвращает объект MassPrintResult, содержащий название файла и ошибки печати отдельных документов.
+	PrintDocumentMany(ctx context.Context, massPrintArg *MassPrintArg, w io.Writer) (*MassPrintResult, *resty.Response, error)
 }
 
 const (
@@ -673,6 +694,7 @@ type inventoryService struct {
 	endpointDelete[Inventory]
 	endpointGetByID[Inventory]
 	endpointUpdate[Inventory]
+	endpointUpdateSafe[Inventory]
 	endpointTemplate[Inventory]
 	endpointMetadata[MetaAttributesStatesSharedWrapper]
 	endpointPositions[InventoryPosition]
@@ -682,6 +704,7 @@ type inventoryService struct {
 	endpointStates
 	endpointFiles
 	endpointEvaluate[Inventory]
+	endpointPrintDocument
 }
 
 func (service *inventoryService) Recalculate(ctx context.Context, id uuid.UUID) (bool, *resty.Response, error) {
@@ -706,6 +729,7 @@ func NewInventoryService(client *Client) InventoryService {
 		endpointDelete:           endpointDelete[Inventory]{e},
 		endpointGetByID:          endpointGetByID[Inventory]{e},
 		endpointUpdate:           endpointUpdate[Inventory]{e},
+		endpointUpdateSafe:       endpointUpdateSafe[Inventory]{e},
 		endpointTemplate:         endpointTemplate[Inventory]{e},
 		endpointMetadata:         endpointMetadata[MetaAttributesStatesSharedWrapper]{e},
 		endpointPositions:        endpointPositions[InventoryPosition]{e},
@@ -715,5 +739,6 @@ func NewInventoryService(client *Client) InventoryService {
 		endpointStates:           endpointStates{e},
 		endpointFiles:            endpointFiles{e},
 		endpointEvaluate:         endpointEvaluate[Inventory]{e},
+		endpointPrintDocument:    endpointPrintDocument{e},
 	}
 }
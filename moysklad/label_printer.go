@@ -0,0 +1,187 @@
+package moysklad
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"path/filepath"
+	"sync"
+)
+
+// LabelPrintItem позиция ассортимента (товар или модификация) с количеством ценников/термоэтикеток для печати.
+type LabelPrintItem struct {
+	Assortment MetaOwner // Товар или модификация
+	Count      int       // Количество ценников/термоэтикеток
+}
+
+// NewLabelPrintItem принимает товар или модификацию и количество ценников и возвращает [LabelPrintItem].
+func NewLabelPrintItem(assortment MetaOwner, count int) *LabelPrintItem {
+	return &LabelPrintItem{Assortment: assortment, Count: count}
+}
+
+// LabelPrinter позволяет напечатать ценники/термоэтикетки по одному шаблону для множества позиций ассортимента.
+//
+// Количество ценников позиции, превышающее [MaxPrintCount], разбивается на несколько запросов.
+// Запросы выполняются параллельно в пределах ограничений клиента на количество запросов.
+// Сервис печатает ценники каждой позиции отдельным файлом (PDF или XLS), а такие файлы нельзя объединить
+// записью подряд, поэтому результат для множества позиций – набор файлов ([LabelPrinter.Print])
+// или ZIP-архив с ними ([LabelPrinter.PrintZip]).
+type LabelPrinter struct {
+	client       *Client
+	organization *Organization
+	priceType    *PriceType
+	template     TemplateConverter
+	concurrency  int
+}
+
+// NewLabelPrinter принимает [Client], юрлицо, тип цен и шаблон и возвращает новый объект [LabelPrinter].
+func NewLabelPrinter(client *Client, organization *Organization, priceType *PriceType, template TemplateConverter) *LabelPrinter {
+	return &LabelPrinter{
+		client:       client,
+		organization: organization,
+		priceType:    priceType,
+		template:     template,
+		concurrency:  MaxQueriesPerUser,
+	}
+}
+
+// WithConcurrency устанавливает количество одновременно выполняемых запросов на печать.
+//
+// Диапазон значения: от 1 до [MaxQueriesPerUser].
+func (labelPrinter *LabelPrinter) WithConcurrency(concurrency int) *LabelPrinter {
+	labelPrinter.concurrency = Clamp(concurrency, 1, MaxQueriesPerUser)
+	return labelPrinter
+}
+
+// LabelPrintResult результат печати ценников для одной позиции [LabelPrintItem].
+type LabelPrintResult struct {
+	Item  *LabelPrintItem  // Позиция ассортимента
+	Files Slice[PrintFile] // Полученные файлы (несколько, если количество ценников превышает MaxPrintCount)
+	Err   error            // Ошибка печати позиции
+}
+
+// labelPrintJob отдельный запрос на печать ценников.
+type labelPrintJob struct {
+	item     int
+	endpoint endpointPrintLabel
+	id       uuid.UUID
+	count    int
+}
+
+// jobs разбивает позиции на запросы с количеством ценников не более [MaxPrintCount].
+func (labelPrinter *LabelPrinter) jobs(items []*LabelPrintItem) ([]labelPrintJob, error) {
+	var jobs []labelPrintJob
+	for i, item := range items {
+		if item == nil || item.Assortment == nil || item.Count <= 0 {
+			continue
+		}
+
+		meta := item.Assortment.GetMeta()
+		switch meta.GetType() {
+		case MetaTypeProduct, MetaTypeVariant:
+		default:
+			return nil, fmt.Errorf("print labels: unsupported assortment type %q", meta.GetType())
+		}
+
+		collection, _ := splitHref(meta.GetHref())
+		endpoint := endpointPrintLabel{NewEndpoint(labelPrinter.client, collection)}
+		for count := item.Count; count > 0; count -= MaxPrintCount {
+			jobs = append(jobs, labelPrintJob{item: i, endpoint: endpoint, id: meta.GetUUIDFromHref(), count: min(count, MaxPrintCount)})
+		}
+	}
+	return jobs, nil
+}
+
+// Print выполняет запросы на печать ценников для переданных позиций.
+//
+// Возвращает результаты в порядке следования позиций: i-й результат соответствует i-й позиции.
+// При ошибках печати возвращаются результаты всех позиций и объединённая ошибка.
+func (labelPrinter *LabelPrinter) Print(ctx context.Context, items ...*LabelPrintItem) ([]*LabelPrintResult, error) {
+	jobs, err := labelPrinter.jobs(items)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		files = make([]*PrintFile, len(jobs))
+		errs  = make([]error, len(jobs))
+		sem   = make(chan struct{}, labelPrinter.concurrency)
+		wg    sync.WaitGroup
+	)
+
+	for i, job := range jobs {
+		wg.Add(1)
+		go func(i int, job labelPrintJob) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+
+			arg := NewPrintLabelArg(labelPrinter.organization, labelPrinter.priceType, labelPrinter.template, job.count)
+			buf := new(bytes.Buffer)
+			fileName, _, err := job.endpoint.PrintLabelTo(ctx, job.id, arg, buf)
+			if err != nil {
+				errs[i] = fmt.Errorf("print labels %s/%s: %w", job.endpoint.uri, job.id, err)
+				return
+			}
+			files[i] = &PrintFile{buf, fileName}
+		}(i, job)
+	}
+
+	wg.Wait()
+
+	results := make([]*LabelPrintResult, len(items))
+	for i, item := range items {
+		results[i] = &LabelPrintResult{Item: item}
+	}
+	for i, job := range jobs {
+		result := results[job.item]
+		if errs[i] != nil {
+			result.Err = errors.Join(result.Err, errs[i])
+			continue
+		}
+		result.Files = append(result.Files, files[i])
+	}
+	return results, errors.Join(errs...)
+}
+
+// PrintZip выполняет запросы на печать ценников для переданных позиций
+// и записывает полученные файлы в w в виде ZIP-архива.
+//
+// Файлы в архиве пронумерованы в порядке следования позиций; каждый файл сохраняется без изменений
+// и может быть открыт или отправлен на печать отдельно.
+func (labelPrinter *LabelPrinter) PrintZip(ctx context.Context, w io.Writer, items ...*LabelPrintItem) error {
+	results, err := labelPrinter.Print(ctx, items...)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	var n int
+	for _, result := range results {
+		for _, file := range result.Files {
+			n++
+			name := file.FileName
+			if name == "" {
+				name = "labels"
+			}
+			entry, err := zw.Create(fmt.Sprintf("%04d_%s", n, filepath.Base(name)))
+			if err != nil {
+				return err
+			}
+			if _, err = io.Copy(entry, file); err != nil {
+				return err
+			}
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,284 @@
+// Package marking содержит средства разбора, нормализации и построения кодов маркировки
+// GS1 DataMatrix (Честный ЗНАК) для работы с [moysklad.TrackingCode].
+package marking
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/arcsub/go-moysklad/moysklad"
+	"strconv"
+	"strings"
+)
+
+const (
+	GS = "\x1d" // Разделитель групп (FNC1) кода DataMatrix
+
+	AIGTIN            = "01" // Идентификатор применения GTIN
+	AISerial          = "21" // Идентификатор применения серийного номера
+	AIVerificationKey = "91" // Идентификатор применения ключа проверки
+	AICrypto          = "92" // Идентификатор применения криптоподписи
+	AICryptoShort     = "93" // Идентификатор применения кода проверки
+	AISSCC            = "00" // Идентификатор применения SSCC транспортной упаковки
+
+	Tag1162Prefix = "444D" // Код типа маркировки «DM» в формате тега 1162
+)
+
+var (
+	ErrInvalidCode  = errors.New("marking: invalid code")
+	ErrGTINMismatch = errors.New("marking: gtin does not match barcodes")
+)
+
+// fixedLength длины значений идентификаторов применения фиксированной длины.
+var fixedLength = map[string]int{
+	"00": 18, "01": 14, "02": 14, "11": 6, "13": 6, "15": 6, "17": 6,
+}
+
+// variableLength максимальные длины значений идентификаторов применения переменной длины.
+var variableLength = map[string]int{
+	"10": 20, "21": 20, "91": 4, "92": 88, "93": 4, "240": 30, "8005": 6,
+}
+
+// serialLengths распространённые длины серийного номера, используемые
+// для разбора кодов, в которых сканер не передал разделители групп.
+var serialLengths = []int{13, 7, 6, 8, 11, 12, 20}
+
+// Code разобранный код маркировки.
+type Code struct {
+	GTIN            string            // Код товара (14 цифр)
+	Serial          string            // Серийный номер
+	VerificationKey string            // Ключ проверки (91)
+	Crypto          string            // Криптоподпись (92) или код проверки (93)
+	SSCC            string            // Код транспортной упаковки (18 цифр)
+	Extra           map[string]string // Прочие идентификаторы применения
+	cryptoAI        string            // идентификатор применения криптохвоста
+}
+
+// String реализует интерфейс [fmt.Stringer].
+func (code Code) String() string {
+	return code.Full()
+}
+
+// IsTransportPack возвращает true, если код является кодом транспортной упаковки (SSCC).
+func (code Code) IsTransportPack() bool {
+	return code.SSCC != ""
+}
+
+// Cis возвращает код маркировки в стандартном формате: GTIN и серийный номер без криптохвоста.
+//
+// Для транспортной упаковки возвращает SSCC с идентификатором применения.
+func (code Code) Cis() string {
+	if code.IsTransportPack() {
+		return AISSCC + code.SSCC
+	}
+	return AIGTIN + code.GTIN + AISerial + code.Serial
+}
+
+// Full возвращает полный код маркировки с криптохвостом и разделителями групп.
+func (code Code) Full() string {
+	var sb strings.Builder
+	sb.WriteString(code.Cis())
+	if code.VerificationKey != "" {
+		sb.WriteString(GS + AIVerificationKey + code.VerificationKey)
+	}
+	if code.Crypto != "" {
+		ai := code.cryptoAI
+		if ai == "" {
+			ai = AICrypto
+			if len(code.Crypto) <= variableLength[AICryptoShort] {
+				ai = AICryptoShort
+			}
+		}
+		sb.WriteString(GS + ai + code.Crypto)
+	}
+	return sb.String()
+}
+
+// Cis1162 возвращает код маркировки в формате тега 1162:
+// шестнадцатеричное представление кода типа маркировки, GTIN (6 байт) и серийного номера.
+//
+// Для транспортной упаковки возвращает пустую строку.
+func (code Code) Cis1162() string {
+	if code.IsTransportPack() {
+		return ""
+	}
+	gtin, _ := strconv.ParseUint(code.GTIN, 10, 64)
+	return Tag1162Prefix + fmt.Sprintf("%012X", gtin) + strings.ToUpper(hex.EncodeToString([]byte(code.Serial)))
+}
+
+// Parse разбирает отсканированный код маркировки.
+//
+// Поддерживаются коды с разделителями групп (GS, символ 29), с префиксом символики «]d2» или «]C1»,
+// коды в читаемом формате с идентификаторами применения в скобках, а также коды,
+// в которых сканер не передал разделители групп.
+func Parse(s string) (*Code, error) {
+	raw := strings.TrimSpace(s)
+	for _, prefix := range []string{"]d2", "]C1", "]Q3"} {
+		raw = strings.TrimPrefix(raw, prefix)
+	}
+	raw = strings.TrimPrefix(raw, GS)
+
+	if strings.HasPrefix(raw, "(") {
+		raw = fromHumanReadable(raw)
+	}
+
+	code := &Code{}
+	if err := code.parse(raw); err != nil {
+		return nil, fmt.Errorf("%w: %q: %v", ErrInvalidCode, s, err)
+	}
+
+	if code.IsTransportPack() {
+		return code, nil
+	}
+
+	if code.GTIN == "" || code.Serial == "" {
+		return nil, fmt.Errorf("%w: %q: gtin and serial are required", ErrInvalidCode, s)
+	}
+
+	if err := moysklad.ValidateGTIN(code.GTIN, 14); err != nil {
+		return nil, fmt.Errorf("%w: %q: %v", ErrInvalidCode, s, err)
+	}
+	return code, nil
+}
+
+// Normalize разбирает код маркировки и возвращает его полное представление с разделителями групп.
+func Normalize(s string) (string, error) {
+	code, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return code.Full(), nil
+}
+
+// fromHumanReadable преобразует код вида «(01)...(21)...» в код с разделителями групп.
+//
+// Разделитель записывается после значения переменной длины, если за ним следует другой идентификатор,
+// например «(01)04600000000001(21)ABC(17)250101» преобразуется в «010460000000000121ABC<GS>17250101».
+func fromHumanReadable(s string) string {
+	var sb strings.Builder
+	parts := strings.Split(s, "(")[1:]
+	for i, part := range parts {
+		ai, value, _ := strings.Cut(part, ")")
+		sb.WriteString(ai + value)
+		if _, fixed := fixedLength[ai]; !fixed && i < len(parts)-1 {
+			sb.WriteString(GS)
+		}
+	}
+	return sb.String()
+}
+
+// parse последовательно разбирает идентификаторы применения.
+func (code *Code) parse(s string) error {
+	for s != "" {
+		if strings.HasPrefix(s, GS) {
+			s = s[len(GS):]
+			continue
+		}
+
+		ai, ok := matchAI(s)
+		if !ok {
+			return fmt.Errorf("unknown application identifier at %q", s)
+		}
+		s = s[len(ai):]
+
+		var value string
+		if length, fixed := fixedLength[ai]; fixed {
+			if len(s) < length {
+				return fmt.Errorf("short value of (%s)", ai)
+			}
+			value, s = s[:length], s[length:]
+		} else {
+			value, s = code.cutVariable(ai, s)
+		}
+
+		code.set(ai, value)
+	}
+	return nil
+}
+
+// cutVariable отделяет значение идентификатора применения переменной длины.
+func (code *Code) cutVariable(ai string, s string) (string, string) {
+	if value, rest, found := strings.Cut(s, GS); found {
+		return value, rest
+	}
+
+	// разделители групп отсутствуют: определяем длину серийного номера по следующему идентификатору
+	if ai == AISerial {
+		for _, length := range serialLengths {
+			if len(s) > length && (strings.HasPrefix(s[length:], AIVerificationKey) || strings.HasPrefix(s[length:], AICryptoShort)) {
+				return s[:length], s[length:]
+			}
+		}
+	}
+
+	if limit := variableLength[ai]; len(s) > limit && ai != AICrypto {
+		return s[:limit], s[limit:]
+	}
+	return s, ""
+}
+
+// set устанавливает значение идентификатора применения.
+func (code *Code) set(ai, value string) {
+	switch ai {
+	case AIGTIN:
+		code.GTIN = value
+	case AISerial:
+		code.Serial = value
+	case AIVerificationKey:
+		code.VerificationKey = value
+	case AICrypto, AICryptoShort:
+		code.Crypto = value
+		code.cryptoAI = ai
+	case AISSCC:
+		code.SSCC = value
+	default:
+		if code.Extra == nil {
+			code.Extra = make(map[string]string)
+		}
+		code.Extra[ai] = value
+	}
+}
+
+// matchAI возвращает идентификатор применения в начале строки.
+func matchAI(s string) (string, bool) {
+	for _, length := range []int{4, 3, 2} {
+		if len(s) < length {
+			continue
+		}
+		ai := s[:length]
+		if _, ok := fixedLength[ai]; ok {
+			return ai, true
+		}
+		if _, ok := variableLength[ai]; ok {
+			return ai, true
+		}
+	}
+	return "", false
+}
+
+// MatchBarcodes возвращает true, если GTIN кода маркировки совпадает с одним из штрихкодов товара.
+//
+// Штрихкоды EAN13, EAN8, UPC и GTIN сравниваются после дополнения нулями слева до 14 цифр.
+func (code Code) MatchBarcodes(barcodes moysklad.Slice[moysklad.Barcode]) bool {
+	for _, barcode := range barcodes {
+		if barcode == nil {
+			continue
+		}
+		switch barcode.Type {
+		case moysklad.BarcodeEAN13, moysklad.BarcodeEAN8, moysklad.BarcodeUPC, moysklad.BarcodeGTIN:
+			if len(barcode.Value) <= 14 && strings.Repeat("0", 14-len(barcode.Value))+barcode.Value == code.GTIN {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ValidateBarcodes возвращает ошибку [ErrGTINMismatch], если GTIN кода маркировки
+// не совпадает ни с одним из штрихкодов товара.
+func (code Code) ValidateBarcodes(barcodes moysklad.Slice[moysklad.Barcode]) error {
+	if !code.MatchBarcodes(barcodes) {
+		return fmt.Errorf("%w: %s", ErrGTINMismatch, code.GTIN)
+	}
+	return nil
+}
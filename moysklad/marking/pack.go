@@ -0,0 +1,178 @@
+package marking
+
+import (
+	"fmt"
+	"github.com/arcsub/go-moysklad/moysklad"
+)
+
+// TrackingCode принимает разобранный код маркировки и возвращает [moysklad.TrackingCode]
+// с типом [moysklad.TrackingCodeTypeTrackingCode] и кодом в стандартном формате.
+//
+// Если with1162 равен true, дополнительно заполняется код в формате тега 1162.
+func (code Code) TrackingCode(with1162 bool) *moysklad.TrackingCode {
+	trackingCode := new(moysklad.TrackingCode).SetCis(code.Cis()).SetTypeTrackingCode()
+	if with1162 {
+		trackingCode.SetCis1162(code.Cis1162())
+	}
+	return trackingCode
+}
+
+// Pack узел иерархии кодов маркировки: потребительская или транспортная упаковка с вложенными кодами.
+type Pack struct {
+	code     *Code
+	packType moysklad.TrackingCodeType
+	items    []*Code
+	packs    []*Pack
+}
+
+// NewConsumerPack принимает код маркировки потребительской упаковки (групповой упаковки)
+// и возвращает новый объект [Pack].
+func NewConsumerPack(code *Code) *Pack {
+	return &Pack{code: code, packType: moysklad.TrackingCodeTypeConsumerPack}
+}
+
+// NewTransportPack принимает код транспортной упаковки (SSCC) и возвращает новый объект [Pack].
+func NewTransportPack(code *Code) *Pack {
+	return &Pack{code: code, packType: moysklad.TrackingCodeTypeTransportPack}
+}
+
+// AddCodes добавляет в упаковку коды маркировки единиц товара.
+func (pack *Pack) AddCodes(codes ...*Code) *Pack {
+	pack.items = append(pack.items, codes...)
+	return pack
+}
+
+// AddPacks добавляет в упаковку вложенные упаковки.
+func (pack *Pack) AddPacks(packs ...*Pack) *Pack {
+	pack.packs = append(pack.packs, packs...)
+	return pack
+}
+
+// TrackingCode возвращает [moysklad.TrackingCode] упаковки со всеми вложенными кодами.
+//
+// Если with1162 равен true, для кодов единиц товара дополнительно заполняется код в формате тега 1162.
+func (pack *Pack) TrackingCode(with1162 bool) *moysklad.TrackingCode {
+	trackingCode := new(moysklad.TrackingCode).SetCis(pack.code.Cis()).SetType(pack.packType)
+	for _, nested := range pack.packs {
+		trackingCode.SetTrackingCodes(nested.TrackingCode(with1162))
+	}
+	for _, item := range pack.items {
+		trackingCode.SetTrackingCodes(item.TrackingCode(with1162))
+	}
+	return trackingCode
+}
+
+// Builder собирает коды маркировки позиции документа из отсканированных строк,
+// отбрасывая повторы и проверяя GTIN по штрихкодам товара.
+//
+// Результат предназначен для передачи в метод CreateUpdatePositionTrackingCodeMany сервисов документов.
+type Builder struct {
+	barcodes moysklad.Slice[moysklad.Barcode]
+	with1162 bool
+	seen     map[string]struct{}
+	codes    []*Code
+	packs    []*Pack
+}
+
+// NewBuilder возвращает новый объект [Builder].
+func NewBuilder() *Builder {
+	return &Builder{seen: make(map[string]struct{})}
+}
+
+// WithBarcodes устанавливает штрихкоды товара позиции, с которыми сверяется GTIN кодов маркировки.
+func (builder *Builder) WithBarcodes(barcodes moysklad.Slice[moysklad.Barcode]) *Builder {
+	builder.barcodes = barcodes
+	return builder
+}
+
+// With1162 включает заполнение кодов маркировки в формате тега 1162.
+func (builder *Builder) With1162() *Builder {
+	builder.with1162 = true
+	return builder
+}
+
+// Add разбирает отсканированные коды маркировки единиц товара и добавляет их в результат.
+//
+// Повторно отсканированные коды пропускаются.
+func (builder *Builder) Add(scans ...string) error {
+	for _, scan := range scans {
+		code, err := builder.parse(scan)
+		if err != nil {
+			return err
+		}
+		if code != nil {
+			builder.codes = append(builder.codes, code)
+		}
+	}
+	return nil
+}
+
+// AddPack разбирает код упаковки и коды её содержимого и добавляет упаковку в результат.
+//
+// Тип упаковки определяется по коду: SSCC – транспортная упаковка, иначе – потребительская.
+func (builder *Builder) AddPack(packScan string, scans ...string) (*Pack, error) {
+	packCode, err := Parse(packScan)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := builder.seen[packCode.Cis()]; ok {
+		return nil, fmt.Errorf("%w: duplicate pack %s", ErrInvalidCode, packCode.Cis())
+	}
+	builder.seen[packCode.Cis()] = struct{}{}
+
+	pack := NewConsumerPack(packCode)
+	if packCode.IsTransportPack() {
+		pack = NewTransportPack(packCode)
+	}
+
+	for _, scan := range scans {
+		code, err := builder.parse(scan)
+		if err != nil {
+			return nil, err
+		}
+		if code != nil {
+			pack.AddCodes(code)
+		}
+	}
+
+	builder.packs = append(builder.packs, pack)
+	return pack, nil
+}
+
+// parse разбирает код единицы товара, проверяет его GTIN и возвращает nil для повторов.
+func (builder *Builder) parse(scan string) (*Code, error) {
+	code, err := Parse(scan)
+	if err != nil {
+		return nil, err
+	}
+
+	if code.IsTransportPack() {
+		return nil, fmt.Errorf("%w: unexpected transport pack %s", ErrInvalidCode, code.Cis())
+	}
+
+	if _, ok := builder.seen[code.Cis()]; ok {
+		return nil, nil
+	}
+
+	if builder.barcodes.Len() > 0 {
+		if err = code.ValidateBarcodes(builder.barcodes); err != nil {
+			return nil, err
+		}
+	}
+
+	builder.seen[code.Cis()] = struct{}{}
+	return code, nil
+}
+
+// Build возвращает собранные коды маркировки.
+func (builder *Builder) Build() moysklad.Slice[moysklad.TrackingCode] {
+	trackingCodes := moysklad.NewSlice[moysklad.TrackingCode]()
+	for _, pack := range builder.packs {
+		trackingCodes.Push(pack.TrackingCode(builder.with1162))
+	}
+	for _, code := range builder.codes {
+		trackingCodes.Push(code.TrackingCode(builder.with1162))
+	}
+	return trackingCodes
+}
@@ -0,0 +1,22 @@
+package marking
+
+import (
+	"context"
+	"fmt"
+	"github.com/arcsub/go-moysklad/moysklad"
+)
+
+// AddToReceipt разбирает отсканированный код маркировки и добавляет единицу товара в чек [moysklad.ReceiptBuilder].
+//
+// Код транспортной упаковки (SSCC) в чек не добавляется.
+// Код маркировки передаётся в позицию с заполненным кодом в формате тега 1162.
+func AddToReceipt(ctx context.Context, receiptBuilder *moysklad.ReceiptBuilder, scan string) (*moysklad.RetailDemandPosition, error) {
+	code, err := Parse(scan)
+	if err != nil {
+		return nil, err
+	}
+	if code.IsTransportPack() {
+		return nil, fmt.Errorf("%w: transport pack %s cannot be sold", ErrInvalidCode, code.Cis())
+	}
+	return receiptBuilder.AddMarked(ctx, code.GTIN, code.TrackingCode(true))
+}
@@ -0,0 +1,407 @@
+package moysklad
+
+import (
+	"context"
+	"errors"
+	"github.com/go-resty/resty/v2"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const (
+	DefaultResolverDepth     = 1   // Глубина разрешения ссылок по умолчанию
+	DefaultResolverBatchSize = 100 // Количество объектов, запрашиваемых одним запросом, по умолчанию
+)
+
+// MetaResolver позволяет заменить ссылки на объекты (объекты, у которых заполнено только поле [Meta]) самими объектами.
+//
+// В отличие от параметра expand, разрешение ссылок выполняется на стороне клиента:
+// ссылки группируются по типу, запрашиваются пакетами через фильтр по id и кешируются,
+// что позволяет избежать запроса [FetchMeta] для каждой строки выборки (N+1).
+//
+// Ссылки в объектах [MetaWrapper] не могут быть заполнены на месте, но их содержимое
+// доступно через функцию [ResolveMeta] после вызова [MetaResolver.Resolve].
+type MetaResolver struct {
+	client      *Client
+	cache       map[string]json.RawMessage
+	mu          sync.RWMutex
+	depth       int
+	batchSize   int
+	concurrency int
+}
+
+// NewMetaResolver принимает [Client] и возвращает новый объект [MetaResolver].
+func NewMetaResolver(client *Client) *MetaResolver {
+	return &MetaResolver{
+		client:      client,
+		cache:       make(map[string]json.RawMessage),
+		depth:       DefaultResolverDepth,
+		batchSize:   DefaultResolverBatchSize,
+		concurrency: MaxQueriesPerUser,
+	}
+}
+
+// WithDepth устанавливает глубину разрешения ссылок.
+//
+// При глубине больше 1 ссылки, содержащиеся в полученных объектах, также будут разрешены.
+func (metaResolver *MetaResolver) WithDepth(depth int) *MetaResolver {
+	metaResolver.depth = max(depth, 1)
+	return metaResolver
+}
+
+// WithBatchSize устанавливает количество объектов, запрашиваемых одним запросом.
+//
+// Диапазон значения: от 1 до 1000.
+func (metaResolver *MetaResolver) WithBatchSize(batchSize int) *MetaResolver {
+	metaResolver.batchSize = Clamp(batchSize, 1, MaxPositions)
+	return metaResolver
+}
+
+// WithConcurrency устанавливает количество одновременно выполняемых запросов.
+//
+// Диапазон значения: от 1 до [MaxQueriesPerUser].
+func (metaResolver *MetaResolver) WithConcurrency(concurrency int) *MetaResolver {
+	metaResolver.concurrency = Clamp(concurrency, 1, MaxQueriesPerUser)
+	return metaResolver
+}
+
+// Reset очищает кеш полученных объектов.
+func (metaResolver *MetaResolver) Reset() {
+	metaResolver.mu.Lock()
+	defer metaResolver.mu.Unlock()
+	metaResolver.cache = make(map[string]json.RawMessage)
+}
+
+// Resolve обходит переданный объект v (структуру, срез, [List], [MetaArray] и т.д.),
+// собирает неразрешённые ссылки, запрашивает недостающие объекты и заполняет ими исходные структуры.
+//
+// Объект v должен быть передан по указателю.
+func (metaResolver *MetaResolver) Resolve(ctx context.Context, v any) error {
+	for level := 0; level < metaResolver.depth; level++ {
+		refs := newMetaRefs()
+		refs.walk(reflect.ValueOf(v))
+
+		if len(refs.targets) == 0 && len(refs.hrefs) == 0 {
+			return nil
+		}
+
+		if err := metaResolver.fetch(ctx, refs.hrefs); err != nil {
+			return err
+		}
+
+		for _, target := range refs.targets {
+			raw, ok := metaResolver.get(target.href)
+			if !ok {
+				continue
+			}
+			if err := json.Unmarshal(raw, target.ptr); err != nil {
+				return err
+			}
+		}
+
+		// на текущем уровне нечего заполнять, следующий уровень не даст новых ссылок
+		if len(refs.targets) == 0 {
+			return nil
+		}
+	}
+	return nil
+}
+
+// ResolveMeta возвращает объект типа T, соответствующий переданному объекту [Meta], из кеша [MetaResolver].
+//
+// Возвращает nil, если объект не был получен ранее или не может быть приведён к типу T.
+func ResolveMeta[T any](metaResolver *MetaResolver, meta Meta) *T {
+	raw, ok := metaResolver.get(meta.GetHref())
+	if !ok {
+		return nil
+	}
+
+	var t T
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return nil
+	}
+	return &t
+}
+
+func (metaResolver *MetaResolver) get(href string) (json.RawMessage, bool) {
+	metaResolver.mu.RLock()
+	defer metaResolver.mu.RUnlock()
+	raw, ok := metaResolver.cache[normalizeHref(href)]
+	return raw, ok
+}
+
+func (metaResolver *MetaResolver) set(href string, raw json.RawMessage) {
+	metaResolver.mu.Lock()
+	defer metaResolver.mu.Unlock()
+	metaResolver.cache[normalizeHref(href)] = raw
+}
+
+// fetch запрашивает объекты по ссылкам, отсутствующим в кеше.
+//
+// Ссылки группируются по адресу коллекции и запрашиваются пакетами с фильтром по id.
+// Количество одновременно выполняемых запросов ограничено значением, установленным в [MetaResolver.WithConcurrency].
+// Если коллекция не поддерживает фильтрацию по id, объекты запрашиваются по одному.
+func (metaResolver *MetaResolver) fetch(ctx context.Context, hrefs map[string]struct{}) error {
+	var groups = make(map[string][]string)
+	for href := range hrefs {
+		if _, ok := metaResolver.get(href); ok {
+			continue
+		}
+		collection, id := splitHref(href)
+		groups[collection] = append(groups[collection], id)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		sem      = make(chan struct{}, metaResolver.concurrency)
+	)
+
+	for collection, ids := range groups {
+		for start := 0; start < len(ids); start += metaResolver.batchSize {
+			batch := ids[start:min(start+metaResolver.batchSize, len(ids))]
+			wg.Add(1)
+			go func(collection string, batch []string) {
+				defer wg.Done()
+
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					errOnce.Do(func() { firstErr = ctx.Err() })
+					return
+				}
+
+				if err := metaResolver.fetchBatch(ctx, collection, batch); err != nil {
+					errOnce.Do(func() { firstErr = err })
+				}
+			}(collection, batch)
+		}
+	}
+
+	wg.Wait()
+	return firstErr
+}
+
+func (metaResolver *MetaResolver) fetchBatch(ctx context.Context, collection string, ids []string) error {
+	params := NewParams().WithLimit(len(ids))
+	for _, id := range ids {
+		params.WithFilterEquals("id", id)
+	}
+
+	list, resp, err := NewRequestBuilder[List[json.RawMessage]](metaResolver.client, collection).SetParams(params).Get(ctx)
+	if err == nil {
+		if list == nil {
+			return nil
+		}
+		for _, raw := range list.Rows {
+			if raw != nil {
+				metaResolver.store(*raw, "")
+			}
+		}
+		return nil
+	}
+
+	if !isFilterNotSupported(resp, err) {
+		return err
+	}
+
+	// коллекция не поддерживает фильтрацию по id, запрашиваем объекты по одному
+	for _, id := range ids {
+		path := collection + "/" + id
+		raw, _, err := NewRequestBuilder[json.RawMessage](metaResolver.client, path).Get(ctx)
+		if err != nil {
+			return err
+		}
+		if raw != nil {
+			metaResolver.store(*raw, baseApiURL+path)
+		}
+	}
+	return nil
+}
+
+// store сохраняет объект в кеше по ссылке из его метаданных.
+//
+// Если метаданные объекта не содержат ссылку, используется ссылка href (если она не пустая).
+func (metaResolver *MetaResolver) store(raw json.RawMessage, href string) {
+	var ref struct {
+		Meta Meta `json:"meta"`
+	}
+	if err := json.Unmarshal(raw, &ref); err == nil && ref.Meta.GetHref() != "" {
+		href = ref.Meta.GetHref()
+	}
+	if href != "" {
+		metaResolver.set(href, raw)
+	}
+}
+
+// isFilterNotSupported возвращает true, если запрос списка был отклонён из-за фильтра.
+//
+// Такая ошибка возвращается в ответе со статусом 400 Bad Request. Сетевые ошибки, отмена контекста,
+// превышение лимитов (429) и ошибки сервера не считаются ошибками фильтрации.
+func isFilterNotSupported(resp *resty.Response, err error) bool {
+	var apiErrors ApiErrors
+	if resp == nil || resp.StatusCode() != http.StatusBadRequest || !errors.As(err, &apiErrors) {
+		return false
+	}
+	return len(apiErrors.ApiErrors) > 0
+}
+
+// normalizeHref удаляет параметры запроса из ссылки.
+func normalizeHref(href string) string {
+	if idx := strings.IndexByte(href, '?'); idx >= 0 {
+		return href[:idx]
+	}
+	return href
+}
+
+// splitHref разделяет ссылку на объект на относительный адрес коллекции и id объекта.
+func splitHref(href string) (string, string) {
+	path := strings.TrimPrefix(normalizeHref(href), baseApiURL)
+	idx := strings.LastIndexByte(path, '/')
+	if idx < 0 {
+		return path, ""
+	}
+	return path[:idx], path[idx+1:]
+}
+
+// metaTarget структура, ожидающая заполнения полученным объектом.
+type metaTarget struct {
+	ptr  any    // указатель на структуру
+	href string // ссылка на объект
+}
+
+// metaRefs собирает неразрешённые ссылки при обходе объекта.
+type metaRefs struct {
+	visited map[uintptr]struct{}
+	hrefs   map[string]struct{}
+	targets []metaTarget
+}
+
+func newMetaRefs() *metaRefs {
+	return &metaRefs{
+		visited: make(map[uintptr]struct{}),
+		hrefs:   make(map[string]struct{}),
+	}
+}
+
+var (
+	reflectTypeMeta        = reflect.TypeOf(Meta{})
+	reflectTypeMetaWrapper = reflect.TypeOf(MetaWrapper{})
+	reflectTypeTimestamp   = reflect.TypeOf(Timestamp{})
+	reflectTypeUUID        = reflect.TypeOf(uuid.UUID{})
+)
+
+// nullValueAccessor позволяет получить значение [NullValue] независимо от обобщённого типа.
+type nullValueAccessor interface {
+	valueAny() any
+}
+
+func (refs *metaRefs) addHref(href string) {
+	if href = normalizeHref(href); href != "" {
+		refs.hrefs[href] = struct{}{}
+	}
+}
+
+func (refs *metaRefs) walk(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		if _, ok := refs.visited[v.Pointer()]; ok {
+			return
+		}
+		refs.visited[v.Pointer()] = struct{}{}
+
+		if v.CanInterface() {
+			if accessor, ok := v.Interface().(nullValueAccessor); ok {
+				refs.walk(reflect.ValueOf(accessor.valueAny()))
+				return
+			}
+		}
+
+		elem := v.Elem()
+		if elem.Kind() == reflect.Struct && v.CanInterface() {
+			if href, ok := unresolvedHref(elem); ok {
+				refs.addHref(href)
+				refs.targets = append(refs.targets, metaTarget{ptr: v.Interface(), href: href})
+				return
+			}
+		}
+		refs.walk(elem)
+
+	case reflect.Interface:
+		if !v.IsNil() {
+			refs.walk(v.Elem())
+		}
+
+	case reflect.Struct:
+		switch v.Type() {
+		case reflectTypeMeta, reflectTypeTimestamp:
+			return
+		case reflectTypeMetaWrapper:
+			refs.addHref(v.Interface().(MetaWrapper).Meta.GetHref())
+			return
+		}
+
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				refs.walk(v.Field(i))
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		if v.Type() == reflectTypeUUID || v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			refs.walk(v.Index(i))
+		}
+
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			refs.walk(iter.Value())
+		}
+	}
+}
+
+// unresolvedHref возвращает ссылку на объект, если у структуры заполнено только поле Meta.
+func unresolvedHref(v reflect.Value) (string, bool) {
+	if v.Type() == reflectTypeMetaWrapper || v.Type() == reflectTypeMeta {
+		return "", false
+	}
+
+	var href string
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fv := v.Field(i)
+		if field.Name == "Meta" {
+			switch meta := fv.Interface().(type) {
+			case *Meta:
+				href = Deref(meta).GetHref()
+			case Meta:
+				href = meta.GetHref()
+			default:
+				return "", false
+			}
+			continue
+		}
+
+		if !fv.IsZero() {
+			return "", false
+		}
+	}
+
+	return href, href != ""
+}
@@ -0,0 +1,269 @@
+package moysklad
+
+import (
+	"context"
+	"fmt"
+	"github.com/goccy/go-json"
+	"time"
+)
+
+// DefaultMirrorOverlap перекрытие интервалов инкрементальной синхронизации по умолчанию.
+//
+// Позволяет не пропустить изменения, момент обновления которых совпал с контрольной точкой.
+const DefaultMirrorOverlap = time.Second
+
+// Mirror механизм синхронизации локальной копии данных МойСклад с хранилищем [MirrorStore].
+//
+// При первом запуске для каждого кода сущности выполняется полная загрузка всех объектов.
+// При последующих запусках загружаются только объекты, изменённые после контрольной точки (filter=updated>=),
+// а удалённые объекты и документы, перемещённые в корзину, определяются по событиям аудита и удаляются из хранилища.
+//
+// Для кодов сущности MetaTypeReportStock и MetaTypeReportStockByStore загружаются текущие остатки
+// (без разбиения по складам и с разбиением по складам соответственно), которые каждый раз загружаются полностью.
+type Mirror struct {
+	client    *Client
+	store     MirrorStore
+	metaTypes []MetaType
+	overlap   time.Duration
+	deletions bool
+}
+
+// NewMirror принимает [Client], хранилище и коды синхронизируемых сущностей и возвращает новый объект [Mirror].
+func NewMirror(client *Client, store MirrorStore, metaTypes ...MetaType) *Mirror {
+	return &Mirror{
+		client:    client,
+		store:     store,
+		metaTypes: metaTypes,
+		overlap:   DefaultMirrorOverlap,
+		deletions: true,
+	}
+}
+
+// WithOverlap устанавливает перекрытие интервалов инкрементальной синхронизации.
+func (mirror *Mirror) WithOverlap(overlap time.Duration) *Mirror {
+	mirror.overlap = overlap
+	return mirror
+}
+
+// WithDeletions включает или отключает удаление из хранилища объектов, удалённых в МойСклад.
+//
+// Для определения удалённых объектов требуется доступ к аудиту.
+func (mirror *Mirror) WithDeletions(deletions bool) *Mirror {
+	mirror.deletions = deletions
+	return mirror
+}
+
+// Sync выполняет синхронизацию всех переданных при создании кодов сущностей.
+func (mirror *Mirror) Sync(ctx context.Context) error {
+	for _, metaType := range mirror.metaTypes {
+		if err := mirror.SyncType(ctx, metaType); err != nil {
+			return fmt.Errorf("mirror %s: %w", metaType, err)
+		}
+	}
+	return nil
+}
+
+// SyncType выполняет синхронизацию объектов с указанным кодом сущности.
+//
+// Выполняет полную загрузку, если для кода сущности отсутствует контрольная точка, иначе – инкрементальную.
+func (mirror *Mirror) SyncType(ctx context.Context, metaType MetaType) error {
+	switch metaType {
+	case MetaTypeReportStock:
+		return mirrorStock[StockCurrentAll](ctx, mirror, metaType, EndpointReportStockAllCurrent, func(stock *StockCurrentAll) string {
+			return stock.AssortmentID
+		})
+	case MetaTypeReportStockByStore:
+		return mirrorStock[StockCurrentByStore](ctx, mirror, metaType, EndpointReportStockByStoreCurrent, func(stock *StockCurrentByStore) string {
+			return stock.AssortmentID + ":" + stock.StoreID
+		})
+	}
+
+	checkpoint, err := mirror.store.Checkpoint(ctx, metaType)
+	if err != nil {
+		return err
+	}
+
+	if checkpoint.IsZero() {
+		return mirror.FullLoad(ctx, metaType)
+	}
+	return mirror.incremental(ctx, metaType, checkpoint)
+}
+
+// FullLoad выполняет полную загрузку объектов с указанным кодом сущности.
+//
+// Объекты, отсутствующие в МойСклад, удаляются из хранилища.
+func (mirror *Mirror) FullLoad(ctx context.Context, metaType MetaType) error {
+	keys, err := mirror.store.Keys(ctx, metaType)
+	if err != nil {
+		return err
+	}
+
+	stale := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		stale[key] = struct{}{}
+	}
+
+	checkpoint, err := mirror.load(ctx, metaType, NewParams(), func(key string) {
+		delete(stale, key)
+	})
+	if err != nil {
+		return err
+	}
+
+	for key := range stale {
+		if err = mirror.store.Delete(ctx, metaType, key); err != nil {
+			return err
+		}
+	}
+
+	// объекты отсутствуют: устанавливаем минимальную ненулевую контрольную точку,
+	// чтобы следующая синхронизация была инкрементальной
+	if checkpoint.IsZero() {
+		checkpoint = time.Unix(0, 0).UTC()
+	}
+	return mirror.store.SetCheckpoint(ctx, metaType, checkpoint)
+}
+
+// incremental загружает объекты, изменённые после контрольной точки, и удаляет объекты, удалённые после неё.
+func (mirror *Mirror) incremental(ctx context.Context, metaType MetaType, checkpoint time.Time) error {
+	from := checkpoint.Add(-mirror.overlap)
+	params := NewParams().WithFilterGreaterOrEquals("updated", from.Format(time.DateTime))
+
+	updated, err := mirror.load(ctx, metaType, params, nil)
+	if err != nil {
+		return err
+	}
+
+	if mirror.deletions {
+		if err = mirror.applyDeletions(ctx, metaType, from); err != nil {
+			return err
+		}
+	}
+
+	if updated.After(checkpoint) {
+		checkpoint = updated
+	}
+	return mirror.store.SetCheckpoint(ctx, metaType, checkpoint)
+}
+
+// mirrorRecord поля объекта, необходимые для синхронизации.
+type mirrorRecord struct {
+	Meta    Meta      `json:"meta"`
+	ID      string    `json:"id"`
+	Updated Timestamp `json:"updated"`
+}
+
+// load постранично загружает объекты и записывает их в хранилище.
+//
+// Возвращает максимальный момент последнего обновления среди загруженных объектов
+// или нулевое время, если объекты не загружены.
+//
+// Момент обновления возвращается в часовом поясе аккаунта, поэтому не сравнивается с локальным временем.
+func (mirror *Mirror) load(ctx context.Context, metaType MetaType, params *Params, seen func(key string)) (time.Time, error) {
+	var maxUpdated time.Time
+
+	path := EndpointEntity + metaType.String()
+	err := forEachPage[json.RawMessage](ctx, mirror.client, path, params, func(rows Slice[json.RawMessage]) error {
+		for _, raw := range rows {
+			var record mirrorRecord
+			if err := json.Unmarshal(*raw, &record); err != nil {
+				return err
+			}
+
+			key := record.ID
+			if key == "" {
+				key = record.Meta.GetUUIDFromHref().String()
+			}
+
+			if err := mirror.store.Put(ctx, metaType, key, *raw); err != nil {
+				return err
+			}
+
+			if seen != nil {
+				seen(key)
+			}
+
+			if updated := record.Updated.Time(); updated.After(maxUpdated) {
+				maxUpdated = updated
+			}
+		}
+		return nil
+	})
+
+	return maxUpdated, err
+}
+
+// applyDeletions удаляет из хранилища объекты, которые были удалены или перемещены в корзину после момента from.
+func (mirror *Mirror) applyDeletions(ctx context.Context, metaType MetaType, from time.Time) error {
+	for _, eventType := range []AuditEventType{AuditEventDelete, AuditEventPutToRecycleBin} {
+		params := NewParams().
+			WithFilterGreaterOrEquals("moment", from.Format(time.DateTime)).
+			WithFilterEquals("entityType", metaType.String()).
+			WithFilterEquals("eventType", string(eventType)).
+			WithLimit(100)
+
+		err := forEachPage[Audit](ctx, mirror.client, EndpointAudit, params, func(contexts Slice[Audit]) error {
+			for _, audit := range contexts {
+				path := fmt.Sprintf(EndpointAuditEvents, audit.ID)
+				err := forEachPage[AuditEvent](ctx, mirror.client, path, NewParams().WithLimit(100), func(events Slice[AuditEvent]) error {
+					for _, event := range events {
+						if event.EntityType != metaType {
+							continue
+						}
+						if err := mirror.store.Delete(ctx, metaType, event.Entity.Meta.GetUUIDFromHref().String()); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mirrorStock загружает текущие остатки и полностью заменяет ими записи хранилища.
+func mirrorStock[T any](ctx context.Context, mirror *Mirror, metaType MetaType, path string, key func(stock *T) string) error {
+	stocks, _, err := NewRequestBuilder[Slice[T]](mirror.client, path).Get(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := mirror.store.Keys(ctx, metaType)
+	if err != nil {
+		return err
+	}
+
+	stale := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		stale[k] = struct{}{}
+	}
+
+	for _, stock := range Deref(stocks) {
+		data, err := json.Marshal(stock)
+		if err != nil {
+			return err
+		}
+
+		k := key(stock)
+		delete(stale, k)
+		if err = mirror.store.Put(ctx, metaType, k, data); err != nil {
+			return err
+		}
+	}
+
+	for k := range stale {
+		if err = mirror.store.Delete(ctx, metaType, k); err != nil {
+			return err
+		}
+	}
+
+	return mirror.store.SetCheckpoint(ctx, metaType, time.Now())
+}
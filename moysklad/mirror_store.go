@@ -0,0 +1,348 @@
+package moysklad
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/goccy/go-json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MirrorStore описывает хранилище локальной копии данных, которое заполняет [Mirror].
+//
+// Записи хранятся в разрезе кода сущности [MetaType] по ключу (как правило, ID объекта).
+// Для каждого кода сущности хранится контрольная точка – момент, начиная с которого выполняется инкрементальная синхронизация.
+type MirrorStore interface {
+	// Get возвращает запись по коду сущности и ключу.
+	// Возвращает false, если запись отсутствует.
+	Get(ctx context.Context, metaType MetaType, key string) (json.RawMessage, bool, error)
+
+	// Put создаёт или заменяет запись.
+	Put(ctx context.Context, metaType MetaType, key string, data json.RawMessage) error
+
+	// Delete удаляет запись.
+	Delete(ctx context.Context, metaType MetaType, key string) error
+
+	// Keys возвращает ключи всех записей с указанным кодом сущности.
+	Keys(ctx context.Context, metaType MetaType) ([]string, error)
+
+	// Checkpoint возвращает контрольную точку синхронизации.
+	// Возвращает нулевое время, если синхронизация ещё не выполнялась.
+	Checkpoint(ctx context.Context, metaType MetaType) (time.Time, error)
+
+	// SetCheckpoint устанавливает контрольную точку синхронизации.
+	SetCheckpoint(ctx context.Context, metaType MetaType, checkpoint time.Time) error
+}
+
+// MemoryMirrorStore хранилище локальной копии данных в памяти.
+//
+// Реализует интерфейс [MirrorStore].
+type MemoryMirrorStore struct {
+	records     map[MetaType]map[string]json.RawMessage
+	checkpoints map[MetaType]time.Time
+	mu          sync.RWMutex
+}
+
+// NewMemoryMirrorStore возвращает новое хранилище локальной копии данных в памяти.
+func NewMemoryMirrorStore() *MemoryMirrorStore {
+	return &MemoryMirrorStore{
+		records:     make(map[MetaType]map[string]json.RawMessage),
+		checkpoints: make(map[MetaType]time.Time),
+	}
+}
+
+// Get реализует интерфейс [MirrorStore].
+func (memoryMirrorStore *MemoryMirrorStore) Get(_ context.Context, metaType MetaType, key string) (json.RawMessage, bool, error) {
+	memoryMirrorStore.mu.RLock()
+	defer memoryMirrorStore.mu.RUnlock()
+	data, ok := memoryMirrorStore.records[metaType][key]
+	return data, ok, nil
+}
+
+// Put реализует интерфейс [MirrorStore].
+func (memoryMirrorStore *MemoryMirrorStore) Put(_ context.Context, metaType MetaType, key string, data json.RawMessage) error {
+	memoryMirrorStore.mu.Lock()
+	defer memoryMirrorStore.mu.Unlock()
+	memoryMirrorStore.put(metaType, key, data)
+	return nil
+}
+
+func (memoryMirrorStore *MemoryMirrorStore) put(metaType MetaType, key string, data json.RawMessage) {
+	records, ok := memoryMirrorStore.records[metaType]
+	if !ok {
+		records = make(map[string]json.RawMessage)
+		memoryMirrorStore.records[metaType] = records
+	}
+	records[key] = data
+}
+
+// Delete реализует интерфейс [MirrorStore].
+func (memoryMirrorStore *MemoryMirrorStore) Delete(_ context.Context, metaType MetaType, key string) error {
+	memoryMirrorStore.mu.Lock()
+	defer memoryMirrorStore.mu.Unlock()
+	delete(memoryMirrorStore.records[metaType], key)
+	return nil
+}
+
+// Keys реализует интерфейс [MirrorStore].
+//
+// Ключи возвращаются в отсортированном порядке.
+func (memoryMirrorStore *MemoryMirrorStore) Keys(_ context.Context, metaType MetaType) ([]string, error) {
+	memoryMirrorStore.mu.RLock()
+	defer memoryMirrorStore.mu.RUnlock()
+	keys := make([]string, 0, len(memoryMirrorStore.records[metaType]))
+	for key := range memoryMirrorStore.records[metaType] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Checkpoint реализует интерфейс [MirrorStore].
+func (memoryMirrorStore *MemoryMirrorStore) Checkpoint(_ context.Context, metaType MetaType) (time.Time, error) {
+	memoryMirrorStore.mu.RLock()
+	defer memoryMirrorStore.mu.RUnlock()
+	return memoryMirrorStore.checkpoints[metaType], nil
+}
+
+// SetCheckpoint реализует интерфейс [MirrorStore].
+func (memoryMirrorStore *MemoryMirrorStore) SetCheckpoint(_ context.Context, metaType MetaType, checkpoint time.Time) error {
+	memoryMirrorStore.mu.Lock()
+	defer memoryMirrorStore.mu.Unlock()
+	memoryMirrorStore.checkpoints[metaType] = checkpoint
+	return nil
+}
+
+// Range вызывает функцию fn для каждой записи с указанным кодом сущности.
+//
+// Обход прекращается, если fn возвращает false.
+func (memoryMirrorStore *MemoryMirrorStore) Range(metaType MetaType, fn func(key string, data json.RawMessage) bool) {
+	memoryMirrorStore.mu.RLock()
+	defer memoryMirrorStore.mu.RUnlock()
+	for key, data := range memoryMirrorStore.records[metaType] {
+		if !fn(key, data) {
+			return
+		}
+	}
+}
+
+// fileMirrorOp операция журнала [FileMirrorStore].
+type fileMirrorOp string
+
+const (
+	fileMirrorOpPut    fileMirrorOp = "put"
+	fileMirrorOpDelete fileMirrorOp = "delete"
+)
+
+// fileMirrorRecord строка журнала [FileMirrorStore].
+type fileMirrorRecord struct {
+	Op   fileMirrorOp    `json:"op"`
+	Key  string          `json:"key"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+const fileMirrorCheckpoints = "checkpoints.json"
+
+// FileMirrorStore файловое хранилище локальной копии данных.
+//
+// Для каждого кода сущности ведётся журнал изменений в формате JSON Lines (файл <metaType>.jsonl) в указанной директории.
+// Контрольные точки хранятся в файле checkpoints.json.
+// При открытии журналы считываются в память, поэтому чтение выполняется без обращения к диску.
+//
+// Реализует интерфейс [MirrorStore].
+type FileMirrorStore struct {
+	*MemoryMirrorStore
+	dir   string
+	files map[MetaType]*os.File
+	mu    sync.Mutex
+}
+
+// OpenFileMirrorStore открывает (или создаёт) файловое хранилище в директории dir.
+func OpenFileMirrorStore(dir string) (*FileMirrorStore, error) {
+	if err := os.MkdirAll(dir, 0770); err != nil {
+		return nil, err
+	}
+
+	fileMirrorStore := &FileMirrorStore{
+		MemoryMirrorStore: NewMemoryMirrorStore(),
+		dir:               dir,
+		files:             make(map[MetaType]*os.File),
+	}
+
+	if err := fileMirrorStore.load(); err != nil {
+		return nil, err
+	}
+	return fileMirrorStore, nil
+}
+
+func (fileMirrorStore *FileMirrorStore) journalPath(metaType MetaType) string {
+	return filepath.Join(fileMirrorStore.dir, metaType.String()+".jsonl")
+}
+
+// load считывает контрольные точки и журналы изменений в память.
+func (fileMirrorStore *FileMirrorStore) load() error {
+	b, err := os.ReadFile(filepath.Join(fileMirrorStore.dir, fileMirrorCheckpoints))
+	switch {
+	case err == nil:
+		if err = json.Unmarshal(b, &fileMirrorStore.checkpoints); err != nil {
+			return err
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	journals, err := filepath.Glob(filepath.Join(fileMirrorStore.dir, "*.jsonl"))
+	if err != nil {
+		return err
+	}
+
+	for _, journal := range journals {
+		metaType := MetaType(filepath.Base(journal[:len(journal)-len(".jsonl")]))
+		if err = fileMirrorStore.replay(metaType, journal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replay применяет записи журнала к хранилищу в памяти.
+func (fileMirrorStore *FileMirrorStore) replay(metaType MetaType, journal string) error {
+	f, err := os.Open(journal)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var record fileMirrorRecord
+		if err = json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("%s:%d: %w", journal, line, err)
+		}
+
+		switch record.Op {
+		case fileMirrorOpPut:
+			fileMirrorStore.MemoryMirrorStore.put(metaType, record.Key, record.Data)
+		case fileMirrorOpDelete:
+			delete(fileMirrorStore.records[metaType], record.Key)
+		}
+	}
+	return scanner.Err()
+}
+
+// append дописывает запись в журнал изменений.
+func (fileMirrorStore *FileMirrorStore) append(metaType MetaType, record fileMirrorRecord) error {
+	fileMirrorStore.mu.Lock()
+	defer fileMirrorStore.mu.Unlock()
+
+	f, ok := fileMirrorStore.files[metaType]
+	if !ok {
+		var err error
+		f, err = os.OpenFile(fileMirrorStore.journalPath(metaType), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0660)
+		if err != nil {
+			return err
+		}
+		fileMirrorStore.files[metaType] = f
+	}
+
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// Put реализует интерфейс [MirrorStore].
+func (fileMirrorStore *FileMirrorStore) Put(ctx context.Context, metaType MetaType, key string, data json.RawMessage) error {
+	if err := fileMirrorStore.append(metaType, fileMirrorRecord{Op: fileMirrorOpPut, Key: key, Data: data}); err != nil {
+		return err
+	}
+	return fileMirrorStore.MemoryMirrorStore.Put(ctx, metaType, key, data)
+}
+
+// Delete реализует интерфейс [MirrorStore].
+func (fileMirrorStore *FileMirrorStore) Delete(ctx context.Context, metaType MetaType, key string) error {
+	if err := fileMirrorStore.append(metaType, fileMirrorRecord{Op: fileMirrorOpDelete, Key: key}); err != nil {
+		return err
+	}
+	return fileMirrorStore.MemoryMirrorStore.Delete(ctx, metaType, key)
+}
+
+// SetCheckpoint реализует интерфейс [MirrorStore].
+//
+// Контрольные точки записываются во временный файл, который затем заменяет файл checkpoints.json.
+func (fileMirrorStore *FileMirrorStore) SetCheckpoint(ctx context.Context, metaType MetaType, checkpoint time.Time) error {
+	if err := fileMirrorStore.MemoryMirrorStore.SetCheckpoint(ctx, metaType, checkpoint); err != nil {
+		return err
+	}
+
+	fileMirrorStore.MemoryMirrorStore.mu.RLock()
+	b, err := json.Marshal(fileMirrorStore.checkpoints)
+	fileMirrorStore.MemoryMirrorStore.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filepath.Join(fileMirrorStore.dir, fileMirrorCheckpoints), b)
+}
+
+// Compact перезаписывает журнал изменений указанного кода сущности, оставляя только актуальные записи.
+func (fileMirrorStore *FileMirrorStore) Compact(metaType MetaType) error {
+	fileMirrorStore.mu.Lock()
+	defer fileMirrorStore.mu.Unlock()
+
+	if f, ok := fileMirrorStore.files[metaType]; ok {
+		if err := f.Close(); err != nil {
+			return err
+		}
+		delete(fileMirrorStore.files, metaType)
+	}
+
+	var buf []byte
+	var err error
+	fileMirrorStore.Range(metaType, func(key string, data json.RawMessage) bool {
+		var b []byte
+		if b, err = json.Marshal(fileMirrorRecord{Op: fileMirrorOpPut, Key: key, Data: data}); err != nil {
+			return false
+		}
+		buf = append(append(buf, b...), '\n')
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(fileMirrorStore.journalPath(metaType), buf)
+}
+
+// Close закрывает открытые файлы журналов.
+func (fileMirrorStore *FileMirrorStore) Close() error {
+	fileMirrorStore.mu.Lock()
+	defer fileMirrorStore.mu.Unlock()
+
+	var firstErr error
+	for metaType, f := range fileMirrorStore.files {
+		if err := f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(fileMirrorStore.files, metaType)
+	}
+	return firstErr
+}
+
+// writeFileAtomic записывает данные во временный файл и переименовывает его в name.
+func writeFileAtomic(name string, data []byte) error {
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0660); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
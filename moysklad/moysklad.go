@@ -9,6 +9,7 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -52,8 +53,9 @@ func (queryLimits *queryLimits) Done() {
 // Client базовый клиент для взаимодействия с API МойСклад.
 type Client struct {
 	*resty.Client
-	limits   *queryLimits
-	clientMu sync.Mutex
+	limits        *queryLimits
+	stateMachines map[MetaType]*StateMachine
+	clientMu      sync.Mutex
 }
 
 // NewClient возвращает новый клиент для работы с API МойСклад.
@@ -133,6 +135,34 @@ func (client *Client) WithBasicAuth(username, password string) *Client {
 	return client
 }
 
+// WithStateMachine подключает к клиенту машину состояний документов.
+//
+// Запросы на изменение документа с кодом сущности машины состояний, выполняемые методами Update
+// и UpdateIfUnchanged сервисов, проверяются методом [StateMachine.Validate]; после изменения
+// выполняются действия статуса, в который перешёл документ. Массовое изменение (CreateUpdateMany) не проверяется.
+func (client *Client) WithStateMachine(stateMachine *StateMachine) *Client {
+	client.clientMu.Lock()
+	defer client.clientMu.Unlock()
+
+	if client.stateMachines == nil {
+		client.stateMachines = make(map[MetaType]*StateMachine)
+	}
+	client.stateMachines[stateMachine.metaType] = stateMachine
+	return client
+}
+
+// stateMachine возвращает машину состояний, подключённую для сущностей с адресом uri, или nil.
+func (client *Client) stateMachine(uri string) *StateMachine {
+	client.clientMu.Lock()
+	defer client.clientMu.Unlock()
+
+	metaType, ok := strings.CutPrefix(uri, EndpointEntity)
+	if !ok || len(client.stateMachines) == 0 {
+		return nil
+	}
+	return client.stateMachines[MetaType(metaType)]
+}
+
 // WithDisabledWebhookContent устанавливает флаг, который отвечает
 // за формирование заголовка временного отключения уведомления вебхуков через API (X-Lognex-WebHook-Disable).
 //
@@ -707,6 +707,11 @@ type OrganizationService interface {
 	// Возвращает изменённое юрлицо.
 	Update(ctx context.Context, id uuid.UUID, organization *Organization, params ...*Params) (*Organization, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение юрлица, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Organization, organization *Organization, params ...*Params) (*Organization, *resty.Response, error)
+
 	// GetMetadata выполняет запрос на получение метаданных юрлиц.
 	// Принимает контекст.
 	// Возвращает объект метаданных MetaAttributesSharedWrapper.
@@ -0,0 +1,213 @@
+package moysklad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/goccy/go-json"
+	"strings"
+	"sync"
+)
+
+// PermissionAction действие над сущностью.
+//
+// Возможные значения:
+//   - PermissionActionView    – Смотреть
+//   - PermissionActionCreate  – Создавать
+//   - PermissionActionUpdate  – Редактировать
+//   - PermissionActionDelete  – Удалять
+//   - PermissionActionApprove – Проводить
+//   - PermissionActionPrint   – Печатать
+//   - PermissionActionDone    – Выполнять (задачи)
+type PermissionAction string
+
+const (
+	PermissionActionView    PermissionAction = "view"    // Смотреть
+	PermissionActionCreate  PermissionAction = "create"  // Создавать
+	PermissionActionUpdate  PermissionAction = "update"  // Редактировать
+	PermissionActionDelete  PermissionAction = "delete"  // Удалять
+	PermissionActionApprove PermissionAction = "approve" // Проводить
+	PermissionActionPrint   PermissionAction = "print"   // Печатать
+	PermissionActionDone    PermissionAction = "done"    // Выполнять (задачи)
+)
+
+var (
+	// ErrPermissionDenied ошибка отсутствия прав на действие.
+	ErrPermissionDenied = errors.New("permission checker: permission denied")
+
+	// ErrPermissionUnknown ошибка отсутствия сведений о правах на действие с сущностью.
+	ErrPermissionUnknown = errors.New("permission checker: unknown permission")
+)
+
+// PermissionRequirement действие над сущностью, необходимое для выполнения сценария.
+type PermissionRequirement struct {
+	Action   PermissionAction // Действие
+	MetaType MetaType         // Код сущности
+}
+
+// NewPermissionRequirement возвращает требование права на действие над сущностью.
+func NewPermissionRequirement(action PermissionAction, metaType MetaType) PermissionRequirement {
+	return PermissionRequirement{Action: action, MetaType: metaType}
+}
+
+// String реализует интерфейс [fmt.Stringer].
+func (permissionRequirement PermissionRequirement) String() string {
+	return fmt.Sprintf("%s %s", permissionRequirement.Action, permissionRequirement.MetaType)
+}
+
+// PermissionChecker проверяет права сотрудника, от имени которого выполняются запросы,
+// до выполнения сценария.
+//
+// Права определяются по контексту сотрудника ([ContextEmployee.Permissions]), который отражает
+// действующие права его роли. Контекст загружается при первой проверке и кешируется;
+// для обновления используйте [PermissionChecker.Load].
+//
+// Права с ограничением по владельцу (только свои, свои и отдела и т.п.) считаются предоставленными;
+// уровень доступа возвращает [PermissionChecker.Scope].
+type PermissionChecker struct {
+	client      *Client
+	employee    *ContextEmployee
+	permissions map[string]map[string]string
+	mu          sync.Mutex
+}
+
+// NewPermissionChecker принимает [Client] и возвращает новый объект [PermissionChecker].
+func NewPermissionChecker(client *Client) *PermissionChecker {
+	return &PermissionChecker{client: client}
+}
+
+// Load выполняет запрос на получение контекста сотрудника и обновляет сведения о правах.
+func (permissionChecker *PermissionChecker) Load(ctx context.Context) error {
+	employee, _, err := NewContextEmployeeService(permissionChecker.client).Get(ctx)
+	if err != nil {
+		return fmt.Errorf("permission checker: load context employee: %w", err)
+	}
+
+	data, err := json.Marshal(employee.Permissions)
+	if err != nil {
+		return fmt.Errorf("permission checker: %w", err)
+	}
+	var permissions map[string]map[string]string
+	if err = json.Unmarshal(data, &permissions); err != nil {
+		return fmt.Errorf("permission checker: %w", err)
+	}
+
+	permissionChecker.mu.Lock()
+	permissionChecker.employee = employee
+	permissionChecker.permissions = permissions
+	permissionChecker.mu.Unlock()
+	return nil
+}
+
+// load загружает контекст сотрудника, если он ещё не загружен.
+func (permissionChecker *PermissionChecker) load(ctx context.Context) error {
+	permissionChecker.mu.Lock()
+	loaded := permissionChecker.permissions != nil
+	permissionChecker.mu.Unlock()
+
+	if loaded {
+		return nil
+	}
+	return permissionChecker.Load(ctx)
+}
+
+// Employee возвращает контекст сотрудника, от имени которого выполняются запросы.
+func (permissionChecker *PermissionChecker) Employee(ctx context.Context) (*ContextEmployee, error) {
+	if err := permissionChecker.load(ctx); err != nil {
+		return nil, err
+	}
+
+	permissionChecker.mu.Lock()
+	defer permissionChecker.mu.Unlock()
+	return permissionChecker.employee, nil
+}
+
+// Security выполняет запрос на получение роли и прав сотрудника, от имени которого выполняются запросы.
+//
+// Для запроса сотрудник должен иметь право просмотра сотрудников.
+func (permissionChecker *PermissionChecker) Security(ctx context.Context) (*EmployeePermission, error) {
+	employee, err := permissionChecker.Employee(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	security, _, err := NewEmployeeService(permissionChecker.client).GetPermissions(ctx, employee.GetMeta().GetUUIDFromHref())
+	if err != nil {
+		return nil, fmt.Errorf("permission checker: load employee permissions: %w", err)
+	}
+	return security, nil
+}
+
+// IsAdmin возвращает true, если сотрудник является администратором.
+func (permissionChecker *PermissionChecker) IsAdmin(ctx context.Context) (bool, error) {
+	if err := permissionChecker.load(ctx); err != nil {
+		return false, err
+	}
+
+	permissionChecker.mu.Lock()
+	defer permissionChecker.mu.Unlock()
+	return permissionChecker.isAdmin(), nil
+}
+
+// isAdmin возвращает true, если сотрудник является администратором.
+func (permissionChecker *PermissionChecker) isAdmin() bool {
+	return isGranted(permissionChecker.permissions["admin"][string(PermissionActionView)])
+}
+
+// isGranted возвращает true, если значение права предоставляет доступ хотя бы к части объектов.
+func isGranted(value string) bool {
+	return value != "" && value != string(PermissionNo) && value != string(ScriptPermissionValueNo)
+}
+
+// Scope возвращает уровень доступа сотрудника для действия над сущностью.
+//
+// Для задач значение соответствует [ScriptPermissionValue].
+// Если действие для сущности не предусмотрено, возвращается ошибка [ErrPermissionUnknown].
+func (permissionChecker *PermissionChecker) Scope(ctx context.Context, action PermissionAction, metaType MetaType) (PermissionValue, error) {
+	if err := permissionChecker.load(ctx); err != nil {
+		return PermissionNone, err
+	}
+
+	permissionChecker.mu.Lock()
+	defer permissionChecker.mu.Unlock()
+
+	value, ok := permissionChecker.permissions[string(metaType)][string(action)]
+	if !ok {
+		if permissionChecker.isAdmin() {
+			return PermissionAll, nil
+		}
+		return PermissionNone, fmt.Errorf("%w: %s %s", ErrPermissionUnknown, action, metaType)
+	}
+	return PermissionValue(value), nil
+}
+
+// Can возвращает true, если сотрудник может выполнить действие над сущностью (хотя бы над частью объектов).
+func (permissionChecker *PermissionChecker) Can(ctx context.Context, action PermissionAction, metaType MetaType) (bool, error) {
+	scope, err := permissionChecker.Scope(ctx, action, metaType)
+	if err != nil {
+		return false, err
+	}
+	return isGranted(string(scope)), nil
+}
+
+// Check проверяет все требования сценария и возвращает ошибку [ErrPermissionDenied]
+// со списком недостающих прав, если хотя бы одно требование не выполнено.
+//
+// Требования с неизвестными правами также считаются невыполненными.
+func (permissionChecker *PermissionChecker) Check(ctx context.Context, requirements ...PermissionRequirement) error {
+	var denied []string
+	for _, requirement := range requirements {
+		ok, err := permissionChecker.Can(ctx, requirement.Action, requirement.MetaType)
+		if err != nil && !errors.Is(err, ErrPermissionUnknown) {
+			return err
+		}
+		if !ok {
+			denied = append(denied, requirement.String())
+		}
+	}
+
+	if len(denied) > 0 {
+		return fmt.Errorf("%w: %s", ErrPermissionDenied, strings.Join(denied, ", "))
+	}
+	return nil
+}
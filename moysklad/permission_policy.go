@@ -0,0 +1,626 @@
+package moysklad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/goccy/go-json"
+	"github.com/goccy/go-yaml"
+	"github.com/google/uuid"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Системные роли сотрудников.
+const (
+	PolicyRoleAdmin      = "admin"      // Администратор
+	PolicyRoleIndividual = "individual" // Индивидуальная роль
+	PolicyRoleCashier    = "cashier"    // Кассир
+	PolicyRoleWorker     = "worker"     // Сотрудник производства
+)
+
+// systemRoles системные роли сотрудников.
+var systemRoles = map[string]bool{
+	PolicyRoleAdmin:      true,
+	PolicyRoleIndividual: true,
+	PolicyRoleCashier:    true,
+	PolicyRoleWorker:     true,
+}
+
+// ErrInvalidPermissionPolicy ошибка проверки политики прав.
+var ErrInvalidPermissionPolicy = errors.New("permission policy: invalid policy")
+
+// PolicyRole пользовательская роль в политике прав.
+//
+// Права, не указанные в политике, считаются отсутствующими.
+type PolicyRole struct {
+	Name        string              `json:"name"`        // Наименование пользовательской роли
+	Permissions EmployeePermissions `json:"permissions"` // Права роли
+}
+
+// PolicyEmployee назначение роли сотруднику в политике прав.
+type PolicyEmployee struct {
+	UID         string               `json:"uid"`                   // Логин сотрудника
+	Role        string               `json:"role"`                  // Наименование пользовательской роли или системная роль (admin, individual, cashier, worker)
+	Group       string               `json:"group,omitempty"`       // Наименование отдела (если не указан, не изменяется)
+	Permissions *EmployeePermissions `json:"permissions,omitempty"` // Права индивидуальной роли
+}
+
+// PermissionPolicy декларативное описание пользовательских ролей и назначений ролей сотрудникам.
+//
+// Политика хранится в формате JSON или YAML; права описываются полями [EmployeePermissions]:
+//
+//	{
+//	  "roles": [
+//	    {"name": "Менеджер", "permissions": {"customerOrder": {"view": "ALL", "create": "ALL"}, "viewDashboard": true}}
+//	  ],
+//	  "employees": [
+//	    {"uid": "ivanov@company", "role": "Менеджер", "group": "Основной"}
+//	  ]
+//	}
+//
+// Та же политика в формате YAML:
+//
+//	roles:
+//	  - name: Менеджер
+//	    permissions:
+//	      customerOrder: {view: ALL, create: ALL}
+//	      viewDashboard: true
+//	employees:
+//	  - {uid: ivanov@company, role: Менеджер, group: Основной}
+//
+// Пустое значение права равнозначно его отсутствию ([PermissionNo], [ScriptPermissionValueNo]).
+type PermissionPolicy struct {
+	Roles     []*PolicyRole     `json:"roles,omitempty"`     // Пользовательские роли
+	Employees []*PolicyEmployee `json:"employees,omitempty"` // Назначения ролей сотрудникам
+}
+
+// ParsePermissionPolicy разбирает политику прав в формате JSON и проверяет её.
+func ParsePermissionPolicy(data []byte) (*PermissionPolicy, error) {
+	policy := new(PermissionPolicy)
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("permission policy: %w", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// ParsePermissionPolicyYAML разбирает политику прав в формате YAML и проверяет её.
+//
+// Имена полей совпадают с именами полей формата JSON.
+func ParsePermissionPolicyYAML(data []byte) (*PermissionPolicy, error) {
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("permission policy: %w", err)
+	}
+	return ParsePermissionPolicy(data)
+}
+
+// LoadPermissionPolicy читает политику прав из файла и проверяет её.
+//
+// Файлы с расширением .yaml или .yml разбираются в формате YAML, остальные – в формате JSON.
+func LoadPermissionPolicy(filePath string) (*PermissionPolicy, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("permission policy: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".yaml", ".yml":
+		return ParsePermissionPolicyYAML(data)
+	default:
+		return ParsePermissionPolicy(data)
+	}
+}
+
+// Marshal возвращает политику прав в формате JSON.
+func (permissionPolicy PermissionPolicy) Marshal() ([]byte, error) {
+	return json.MarshalIndent(permissionPolicy, "", "  ")
+}
+
+// MarshalYAML возвращает политику прав в формате YAML.
+func (permissionPolicy PermissionPolicy) MarshalYAML() ([]byte, error) {
+	data, err := permissionPolicy.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return yaml.JSONToYAML(data)
+}
+
+// Role возвращает пользовательскую роль политики по наименованию или nil.
+func (permissionPolicy PermissionPolicy) Role(name string) *PolicyRole {
+	for _, role := range permissionPolicy.Roles {
+		if role.Name == name {
+			return role
+		}
+	}
+	return nil
+}
+
+// Validate проверяет уникальность ролей и сотрудников и ссылки на роли.
+func (permissionPolicy PermissionPolicy) Validate() error {
+	var errs []string
+
+	roles := make(map[string]bool)
+	for _, role := range permissionPolicy.Roles {
+		switch {
+		case role.Name == "":
+			errs = append(errs, "role without name")
+		case systemRoles[role.Name]:
+			errs = append(errs, fmt.Sprintf("role %q: system role name is reserved", role.Name))
+		case roles[role.Name]:
+			errs = append(errs, fmt.Sprintf("role %q: duplicate", role.Name))
+		}
+		roles[role.Name] = true
+	}
+
+	employees := make(map[string]bool)
+	for _, employee := range permissionPolicy.Employees {
+		switch {
+		case employee.UID == "":
+			errs = append(errs, "employee without uid")
+		case employees[employee.UID]:
+			errs = append(errs, fmt.Sprintf("employee %q: duplicate", employee.UID))
+		case !roles[employee.Role] && !systemRoles[employee.Role]:
+			errs = append(errs, fmt.Sprintf("employee %q: unknown role %q", employee.UID, employee.Role))
+		case employee.Permissions != nil && employee.Role != PolicyRoleIndividual:
+			errs = append(errs, fmt.Sprintf("employee %q: permissions are allowed for individual role only", employee.UID))
+		}
+		employees[employee.UID] = true
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidPermissionPolicy, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// PermissionChangeKind тип изменения прав.
+//
+// Возможные значения:
+//   - PermissionChangeCreateRole – Создание пользовательской роли
+//   - PermissionChangeUpdateRole – Изменение прав пользовательской роли
+//   - PermissionChangeDeleteRole – Удаление пользовательской роли
+//   - PermissionChangeEmployee   – Изменение роли, отдела или индивидуальных прав сотрудника
+type PermissionChangeKind string
+
+const (
+	PermissionChangeCreateRole PermissionChangeKind = "createrole" // Создание пользовательской роли
+	PermissionChangeUpdateRole PermissionChangeKind = "updaterole" // Изменение прав пользовательской роли
+	PermissionChangeDeleteRole PermissionChangeKind = "deleterole" // Удаление пользовательской роли
+	PermissionChangeEmployee   PermissionChangeKind = "employee"   // Изменение роли, отдела или индивидуальных прав сотрудника
+)
+
+// PermissionFieldChange изменение отдельного права.
+type PermissionFieldChange struct {
+	Field string // Путь к праву (например, customerOrder.view)
+	From  string // Текущее значение
+	To    string // Значение по политике
+}
+
+// PermissionChange изменение роли или назначения сотрудника.
+type PermissionChange struct {
+	Kind    PermissionChangeKind    // Тип изменения
+	Name    string                  // Наименование роли или логин сотрудника
+	Fields  []PermissionFieldChange // Изменённые права
+	role    *Role
+	policy  *PolicyEmployee
+	current *EmployeePermission
+	id      uuid.UUID
+}
+
+// String реализует интерфейс [fmt.Stringer].
+func (permissionChange PermissionChange) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %s", permissionChange.Kind, permissionChange.Name))
+	for _, field := range permissionChange.Fields {
+		sb.WriteString(fmt.Sprintf("\n  %s: %q -> %q", field.Field, field.From, field.To))
+	}
+	return sb.String()
+}
+
+// PermissionPlan расхождения текущих прав с политикой.
+type PermissionPlan struct {
+	Changes []*PermissionChange // Изменения: создание и изменение ролей, сотрудники, удаление ролей
+}
+
+// IsEmpty возвращает true, если текущие права соответствуют политике.
+func (permissionPlan PermissionPlan) IsEmpty() bool {
+	return len(permissionPlan.Changes) == 0
+}
+
+// String реализует интерфейс [fmt.Stringer].
+func (permissionPlan PermissionPlan) String() string {
+	lines := make([]string, 0, len(permissionPlan.Changes))
+	for _, change := range permissionPlan.Changes {
+		lines = append(lines, change.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// permissionState текущее состояние ролей, отделов и прав сотрудников.
+type permissionState struct {
+	roles       map[string]*Role
+	roleNames   map[uuid.UUID]string
+	systemRoles map[string]*Role
+	groups      map[string]*Group
+	employees   map[string]*Employee
+}
+
+// roleName возвращает наименование пользовательской роли или системную роль по метаданным.
+func (permissionState permissionState) roleName(role Role) string {
+	base := path.Base(role.GetMeta().GetHref())
+	if systemRoles[base] {
+		return base
+	}
+	return permissionState.roleNames[role.GetMeta().GetUUIDFromHref()]
+}
+
+// PermissionPolicyManager сравнивает политику прав с текущими ролями и правами сотрудников учётной записи
+// и применяет изменения.
+//
+// Сотрудники и пользовательские роли, не описанные в политике, не изменяются;
+// с [PermissionPolicyManager.WithPrune] пользовательские роли, отсутствующие в политике, удаляются.
+type PermissionPolicyManager struct {
+	client *Client
+	policy *PermissionPolicy
+	prune  bool
+}
+
+// NewPermissionPolicyManager принимает [Client] и политику прав и возвращает новый объект [PermissionPolicyManager].
+func NewPermissionPolicyManager(client *Client, policy *PermissionPolicy) *PermissionPolicyManager {
+	return &PermissionPolicyManager{client: client, policy: policy}
+}
+
+// WithPrune включает удаление пользовательских ролей, отсутствующих в политике.
+func (permissionPolicyManager *PermissionPolicyManager) WithPrune() *PermissionPolicyManager {
+	permissionPolicyManager.prune = true
+	return permissionPolicyManager
+}
+
+// load загружает пользовательские и системные роли, отделы и сотрудников.
+func (permissionPolicyManager *PermissionPolicyManager) load(ctx context.Context) (*permissionState, error) {
+	client := permissionPolicyManager.client
+	state := &permissionState{
+		roles:       make(map[string]*Role),
+		roleNames:   make(map[uuid.UUID]string),
+		systemRoles: make(map[string]*Role),
+		groups:      make(map[string]*Group),
+		employees:   make(map[string]*Employee),
+	}
+
+	err := forEachPage[Role](ctx, client, EndpointRole, NewParams(), func(rows Slice[Role]) error {
+		for _, role := range rows {
+			state.roles[role.GetName()] = role
+			state.roleNames[role.GetID()] = role.GetName()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("permission policy: load roles: %w", err)
+	}
+
+	err = forEachPage[Group](ctx, client, EndpointGroup, NewParams(), func(rows Slice[Group]) error {
+		for _, group := range rows {
+			state.groups[group.GetName()] = group
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("permission policy: load groups: %w", err)
+	}
+
+	err = forEachPage[Employee](ctx, client, EndpointEmployee, NewParams(), func(rows Slice[Employee]) error {
+		for _, employee := range rows {
+			state.employees[employee.GetUID()] = employee
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("permission policy: load employees: %w", err)
+	}
+
+	roleService := NewRoleService(client)
+	admin, _, err := roleService.GetAdminRole(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("permission policy: load admin role: %w", err)
+	}
+	individual, _, err := roleService.GetIndividualRole(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("permission policy: load individual role: %w", err)
+	}
+	cashier, _, err := roleService.GetCashierRole(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("permission policy: load cashier role: %w", err)
+	}
+	worker, _, err := roleService.GetWorkerRole(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("permission policy: load worker role: %w", err)
+	}
+	state.systemRoles[PolicyRoleAdmin] = &Role{Meta: &admin.Meta}
+	state.systemRoles[PolicyRoleIndividual] = &Role{Meta: &individual.Meta}
+	state.systemRoles[PolicyRoleCashier] = &Role{Meta: &cashier.Meta}
+	state.systemRoles[PolicyRoleWorker] = &Role{Meta: &worker.Meta}
+	return state, nil
+}
+
+// Diff загружает текущее состояние учётной записи и возвращает расхождения с политикой.
+func (permissionPolicyManager *PermissionPolicyManager) Diff(ctx context.Context) (*PermissionPlan, error) {
+	policy := permissionPolicyManager.policy
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	state, err := permissionPolicyManager.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := new(PermissionPlan)
+	for _, policyRole := range policy.Roles {
+		permissions := normalizePermissions(policyRole.Permissions)
+		current, ok := state.roles[policyRole.Name]
+		if !ok {
+			role := new(Role).SetName(policyRole.Name).SetPermissions(&permissions)
+			plan.Changes = append(plan.Changes, &PermissionChange{
+				Kind:   PermissionChangeCreateRole,
+				Name:   policyRole.Name,
+				Fields: diffPermissions(EmployeePermissions{}, permissions),
+				role:   role,
+			})
+			continue
+		}
+
+		if fields := diffPermissions(current.GetPermissions(), permissions); len(fields) > 0 {
+			plan.Changes = append(plan.Changes, &PermissionChange{
+				Kind:   PermissionChangeUpdateRole,
+				Name:   policyRole.Name,
+				Fields: fields,
+				role:   &Role{ID: current.ID, Meta: current.Meta, Name: current.Name, Permissions: &permissions},
+			})
+		}
+	}
+
+	employeeService := NewEmployeeService(permissionPolicyManager.client)
+	for _, policyEmployee := range policy.Employees {
+		employee, ok := state.employees[policyEmployee.UID]
+		if !ok {
+			return nil, fmt.Errorf("permission policy: employee %q not found", policyEmployee.UID)
+		}
+		if policyEmployee.Group != "" && state.groups[policyEmployee.Group] == nil {
+			return nil, fmt.Errorf("permission policy: employee %q: group %q not found", policyEmployee.UID, policyEmployee.Group)
+		}
+
+		current, _, err := employeeService.GetPermissions(ctx, employee.GetID())
+		if err != nil {
+			return nil, fmt.Errorf("permission policy: load permissions of %q: %w", policyEmployee.UID, err)
+		}
+
+		var fields []PermissionFieldChange
+		if roleName := state.roleName(current.GetRole()); roleName != policyEmployee.Role {
+			fields = append(fields, PermissionFieldChange{Field: "role", From: roleName, To: policyEmployee.Role})
+		}
+		if group := current.GetGroup().Name; policyEmployee.Group != "" && group != policyEmployee.Group {
+			fields = append(fields, PermissionFieldChange{Field: "group", From: group, To: policyEmployee.Group})
+		}
+		if policyEmployee.Permissions != nil {
+			fields = append(fields, diffPermissions(current.GetRole().GetPermissions(), *policyEmployee.Permissions)...)
+		}
+
+		if len(fields) > 0 {
+			plan.Changes = append(plan.Changes, &PermissionChange{
+				Kind:    PermissionChangeEmployee,
+				Name:    policyEmployee.UID,
+				Fields:  fields,
+				policy:  policyEmployee,
+				current: current,
+				id:      employee.GetID(),
+			})
+		}
+	}
+
+	// роли удаляются после переназначения сотрудников
+	if permissionPolicyManager.prune {
+		var names []string
+		for name := range state.roles {
+			if policy.Role(name) == nil {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			plan.Changes = append(plan.Changes, &PermissionChange{Kind: PermissionChangeDeleteRole, Name: name, role: state.roles[name]})
+		}
+	}
+	return plan, nil
+}
+
+// Apply применяет изменения плана в порядке их следования: создаёт и изменяет пользовательские роли,
+// назначает роли и отделы сотрудникам, удаляет пользовательские роли.
+//
+// План должен быть получен методом [PermissionPolicyManager.Diff] непосредственно перед применением.
+func (permissionPolicyManager *PermissionPolicyManager) Apply(ctx context.Context, plan *PermissionPlan) error {
+	client := permissionPolicyManager.client
+	roleService := NewRoleService(client)
+
+	var state *permissionState
+	for _, change := range plan.Changes {
+		switch change.Kind {
+		case PermissionChangeCreateRole:
+			if _, _, err := roleService.Create(ctx, change.role); err != nil {
+				return fmt.Errorf("permission policy: create role %q: %w", change.Name, err)
+			}
+		case PermissionChangeUpdateRole:
+			if _, _, err := roleService.Update(ctx, change.role.GetID(), change.role); err != nil {
+				return fmt.Errorf("permission policy: update role %q: %w", change.Name, err)
+			}
+		case PermissionChangeDeleteRole:
+			if _, _, err := roleService.DeleteByID(ctx, change.role.GetID()); err != nil {
+				return fmt.Errorf("permission policy: delete role %q: %w", change.Name, err)
+			}
+		case PermissionChangeEmployee:
+			// роли, созданные выше, должны быть известны при назначении
+			if state == nil {
+				var err error
+				if state, err = permissionPolicyManager.load(ctx); err != nil {
+					return err
+				}
+			}
+			if err := permissionPolicyManager.applyEmployee(ctx, state, change); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applyEmployee назначает сотруднику роль, отдел и индивидуальные права по политике.
+func (permissionPolicyManager *PermissionPolicyManager) applyEmployee(ctx context.Context, state *permissionState, change *PermissionChange) error {
+	policyEmployee := change.policy
+	role := state.systemRoles[policyEmployee.Role]
+	if role == nil {
+		if role = state.roles[policyEmployee.Role]; role == nil {
+			return fmt.Errorf("permission policy: employee %q: role %q not found", change.Name, policyEmployee.Role)
+		}
+	}
+
+	permission := change.current
+	permissions := permission.GetRole().GetPermissions()
+	permission.Role = role.Clean()
+	if policyEmployee.Role == PolicyRoleIndividual {
+		if policyEmployee.Permissions != nil {
+			permissions = normalizePermissions(*policyEmployee.Permissions)
+		}
+		permission.Role.SetPermissions(&permissions)
+	}
+	if policyEmployee.Group != "" {
+		permission.SetGroup(state.groups[policyEmployee.Group])
+	}
+
+	_, _, err := NewEmployeeService(permissionPolicyManager.client).UpdatePermissions(ctx, change.id, permission)
+	if err != nil {
+		return fmt.Errorf("permission policy: update permissions of %q: %w", change.Name, err)
+	}
+	return nil
+}
+
+// Export загружает текущие пользовательские роли и назначения ролей сотрудникам с доступом к сервису
+// и возвращает их в виде политики прав.
+func (permissionPolicyManager *PermissionPolicyManager) Export(ctx context.Context) (*PermissionPolicy, error) {
+	state, err := permissionPolicyManager.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := new(PermissionPolicy)
+	for _, role := range state.roles {
+		policy.Roles = append(policy.Roles, &PolicyRole{Name: role.GetName(), Permissions: role.GetPermissions()})
+	}
+	sort.Slice(policy.Roles, func(i, j int) bool { return policy.Roles[i].Name < policy.Roles[j].Name })
+
+	employeeService := NewEmployeeService(permissionPolicyManager.client)
+	for uid, employee := range state.employees {
+		current, _, err := employeeService.GetPermissions(ctx, employee.GetID())
+		if err != nil {
+			return nil, fmt.Errorf("permission policy: load permissions of %q: %w", uid, err)
+		}
+		if !current.GetActive() {
+			continue
+		}
+
+		policyEmployee := &PolicyEmployee{UID: uid, Role: state.roleName(current.GetRole()), Group: current.GetGroup().Name}
+		if policyEmployee.Role == PolicyRoleIndividual {
+			permissions := current.GetRole().GetPermissions()
+			policyEmployee.Permissions = &permissions
+		}
+		policy.Employees = append(policy.Employees, policyEmployee)
+	}
+	sort.Slice(policy.Employees, func(i, j int) bool { return policy.Employees[i].UID < policy.Employees[j].UID })
+	return policy, nil
+}
+
+// diffPermissions возвращает изменённые права в порядке наименований.
+//
+// Пустые значения прав считаются отсутствием прав.
+func diffPermissions(current, desired EmployeePermissions) []PermissionFieldChange {
+	from, to := flattenPermissions(current), flattenPermissions(desired)
+
+	var fields []PermissionFieldChange
+	for field, value := range to {
+		if from[field] != value {
+			fields = append(fields, PermissionFieldChange{Field: field, From: from[field], To: value})
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return fields
+}
+
+// flattenPermissions возвращает права в виде плоского словаря «путь – значение».
+func flattenPermissions(permissions EmployeePermissions) map[string]string {
+	flat := make(map[string]string)
+	data, err := json.Marshal(permissions)
+	if err != nil {
+		return flat
+	}
+
+	var values map[string]any
+	if err = json.Unmarshal(data, &values); err != nil {
+		return flat
+	}
+
+	for name, value := range values {
+		if nested, ok := value.(map[string]any); ok {
+			for action, v := range nested {
+				flat[name+"."+action] = fmt.Sprint(normalizePermissionValue(v))
+			}
+			continue
+		}
+		flat[name] = fmt.Sprint(value)
+	}
+	return flat
+}
+
+// normalizePermissions возвращает права, в которых пустые значения заменены на [PermissionNo]
+// (для прав на задачи – на [ScriptPermissionValueNo]).
+func normalizePermissions(permissions EmployeePermissions) EmployeePermissions {
+	data, err := json.Marshal(permissions)
+	if err != nil {
+		return permissions
+	}
+
+	var values map[string]any
+	if err = json.Unmarshal(data, &values); err != nil {
+		return permissions
+	}
+	for _, value := range values {
+		if nested, ok := value.(map[string]any); ok {
+			for action, v := range nested {
+				nested[action] = normalizePermissionValue(v)
+			}
+		}
+	}
+
+	if data, err = json.Marshal(values); err != nil {
+		return permissions
+	}
+	var normalized EmployeePermissions
+	if err = json.Unmarshal(data, &normalized); err != nil {
+		return permissions
+	}
+	return normalized
+}
+
+// normalizePermissionValue возвращает [PermissionNo] для пустого значения права.
+//
+// Значения [PermissionNo] и [ScriptPermissionValueNo] совпадают.
+func normalizePermissionValue(value any) any {
+	if value == string(PermissionNone) {
+		return string(PermissionNo)
+	}
+	return value
+}
@@ -0,0 +1,456 @@
+package moysklad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/goccy/go-json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PriceRounding способ округления рассчитанной цены.
+type PriceRounding int
+
+const (
+	PriceRoundingNone  PriceRounding = iota // Без округления (до копеек)
+	PriceRoundingWhole                      // До целых рублей
+	PriceRounding99                         // До ближайшей большей цены, оканчивающейся на 99 копеек
+)
+
+// round округляет цену в копейках.
+func (priceRounding PriceRounding) round(value float64) float64 {
+	switch priceRounding {
+	case PriceRoundingWhole:
+		return math.Round(value/100) * 100
+	case PriceRounding99:
+		return math.Ceil((math.Round(value)+1)/100)*100 - 1
+	default:
+		return math.Round(value)
+	}
+}
+
+// PriceRule правило расчёта цены продажи.
+//
+// Если задана наценка, цена рассчитывается от закупочной цены; иначе – от текущей цены продажи.
+// Позиции без закупочной цены при расчёте по наценке пропускаются.
+type PriceRule struct {
+	Markup      *float64      // Наценка на закупочную цену (в процентах)
+	Change      *float64      // Изменение цены (в процентах, отрицательное значение – снижение)
+	Rounding    PriceRounding // Способ округления
+	UseMinPrice bool          // Цена не ниже минимальной цены позиции
+}
+
+// NewMarkupRule возвращает правило расчёта цены как закупочной цены с наценкой percent процентов.
+func NewMarkupRule(percent float64) *PriceRule {
+	return &PriceRule{Markup: &percent}
+}
+
+// NewChangeRule возвращает правило изменения текущей цены на percent процентов.
+func NewChangeRule(percent float64) *PriceRule {
+	return &PriceRule{Change: &percent}
+}
+
+// WithRounding устанавливает способ округления.
+func (priceRule *PriceRule) WithRounding(rounding PriceRounding) *PriceRule {
+	priceRule.Rounding = rounding
+	return priceRule
+}
+
+// WithMinPrice ограничивает цену снизу минимальной ценой позиции.
+func (priceRule *PriceRule) WithMinPrice() *PriceRule {
+	priceRule.UseMinPrice = true
+	return priceRule
+}
+
+// apply рассчитывает новую цену. Возвращает false, если цена не может быть рассчитана.
+func (priceRule PriceRule) apply(current, buyPrice, minPrice float64) (float64, bool) {
+	value := current
+	if priceRule.Markup != nil {
+		if buyPrice <= 0 {
+			return 0, false
+		}
+		value = buyPrice * (1 + *priceRule.Markup/100)
+	}
+	if priceRule.Change != nil {
+		value *= 1 + *priceRule.Change/100
+	}
+
+	value = priceRule.Rounding.round(value)
+	if priceRule.UseMinPrice && value < minPrice {
+		value = minPrice
+	}
+	return value, true
+}
+
+// PriceChange изменение цены продажи позиции.
+type PriceChange struct {
+	Meta      Meta    // Метаданные товара/модификации
+	Name      string  // Наименование позиции
+	Folder    string  // Путь группы товаров
+	PriceType string  // Наименование типа цены
+	Old       float64 // Текущая цена (в копейках)
+	New       float64 // Новая цена (в копейках)
+}
+
+// Delta возвращает изменение цены в процентах.
+func (priceChange PriceChange) Delta() float64 {
+	if priceChange.Old == 0 {
+		return 0
+	}
+	return (priceChange.New - priceChange.Old) / priceChange.Old * 100
+}
+
+// PriceReport результат расчёта цен.
+type PriceReport struct {
+	Changes []*PriceChange // Изменения цен, упорядоченные по наименованию позиции и типу цены
+	Skipped int            // Количество позиций, цена которых не может быть рассчитана
+	prices  map[string]*priceEntity
+}
+
+// Write записывает изменения цен в [RowWriter]. Цены записываются в рублях.
+func (priceReport PriceReport) Write(rowWriter RowWriter) error {
+	if err := rowWriter.WriteRow([]string{"type", "name", "folder", "priceType", "old", "new", "delta"}); err != nil {
+		return err
+	}
+
+	for _, change := range priceReport.Changes {
+		err := rowWriter.WriteRow([]string{
+			change.Meta.GetType().String(),
+			change.Name,
+			change.Folder,
+			change.PriceType,
+			strconv.FormatFloat(change.Old/100, 'f', 2, 64),
+			strconv.FormatFloat(change.New/100, 'f', 2, 64),
+			strconv.FormatFloat(change.Delta(), 'f', 2, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return rowWriter.Close()
+}
+
+// priceEntity цены продажи товара или модификации, которые необходимо сохранить.
+type priceEntity struct {
+	meta       Meta
+	salePrices Slice[SalePrice]
+}
+
+// priceRaw поля позиции ассортимента, необходимые для расчёта цен.
+type priceRaw struct {
+	Meta          Meta             `json:"meta"`
+	ID            string           `json:"id"`
+	Name          string           `json:"name"`
+	SalePrices    Slice[SalePrice] `json:"salePrices"`
+	BuyPrice      *BuyPrice        `json:"buyPrice"`
+	MinPrice      *MinPrice        `json:"minPrice"`
+	ProductFolder *MetaWrapper     `json:"productFolder"`
+	Product       *MetaWrapper     `json:"product"`
+}
+
+// priceFolderRule правило расчёта цен для группы товаров.
+type priceFolderRule struct {
+	path string
+	rule *PriceRule
+}
+
+// PriceEngine выполняет массовый пересчёт цен продажи товаров и модификаций по правилам.
+//
+// Правило группы товаров применяется к позициям группы и её подгрупп;
+// при нескольких подходящих правилах используется правило наиболее вложенной группы.
+// Модификации относятся к группе своего товара.
+type PriceEngine struct {
+	client        *Client
+	rule          *PriceRule
+	folderRules   []priceFolderRule
+	priceTypes    []string
+	priceListName string
+}
+
+// NewPriceEngine принимает [Client] и правило по умолчанию и возвращает новый объект [PriceEngine].
+//
+// Если правило по умолчанию равно nil, пересчитываются только позиции групп с установленными правилами.
+func NewPriceEngine(client *Client, rule *PriceRule) *PriceEngine {
+	return &PriceEngine{client: client, rule: rule}
+}
+
+// WithPriceTypes ограничивает пересчёт типами цен с указанными наименованиями.
+//
+// По умолчанию пересчитываются все типы цен.
+func (priceEngine *PriceEngine) WithPriceTypes(names ...string) *PriceEngine {
+	priceEngine.priceTypes = names
+	return priceEngine
+}
+
+// WithFolderRule устанавливает правило для группы товаров с указанным путём (через «/») и её подгрупп.
+func (priceEngine *PriceEngine) WithFolderRule(folderPath string, rule *PriceRule) *PriceEngine {
+	priceEngine.folderRules = append(priceEngine.folderRules, priceFolderRule{path: strings.Trim(folderPath, FolderPathSeparator), rule: rule})
+	return priceEngine
+}
+
+// WithPriceList включает создание документа [PriceList] с наименованием name при сохранении цен.
+//
+// Прайс-лист содержит столбец для каждого изменённого типа цены и позицию для каждой изменённой позиции.
+func (priceEngine *PriceEngine) WithPriceList(name string) *PriceEngine {
+	priceEngine.priceListName = name
+	return priceEngine
+}
+
+// ruleFor возвращает правило для группы товаров.
+func (priceEngine *PriceEngine) ruleFor(folderPath string) *PriceRule {
+	rule, depth := priceEngine.rule, -1
+	for _, folderRule := range priceEngine.folderRules {
+		if !strings.EqualFold(folderPath, folderRule.path) &&
+			!strings.HasPrefix(strings.ToLower(folderPath), strings.ToLower(folderRule.path)+FolderPathSeparator) {
+			continue
+		}
+		if d := folderDepth(folderRule.path); d > depth {
+			rule, depth = folderRule.rule, d
+		}
+	}
+	return rule
+}
+
+// matchPriceType возвращает true, если тип цены подлежит пересчёту.
+func (priceEngine *PriceEngine) matchPriceType(name string) bool {
+	if len(priceEngine.priceTypes) == 0 {
+		return true
+	}
+	for _, priceType := range priceEngine.priceTypes {
+		if strings.EqualFold(priceType, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Preview рассчитывает новые цены без изменения данных.
+func (priceEngine *PriceEngine) Preview(ctx context.Context) (*PriceReport, error) {
+	folders := make(map[string]string)
+	err := forEachPage[ProductFolder](ctx, priceEngine.client, EndpointProductFolder, NewParams(), func(rows Slice[ProductFolder]) error {
+		for _, row := range rows {
+			path := row.GetName()
+			if pathName := row.GetPathName(); pathName != "" {
+				path = pathName + FolderPathSeparator + path
+			}
+			folders[row.GetID().String()] = path
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &PriceReport{prices: make(map[string]*priceEntity)}
+	params := NewParams().
+		WithFilterEquals("type", MetaTypeProduct.String()).
+		WithFilterEquals("type", MetaTypeVariant.String())
+
+	// группы товаров для модификаций
+	productFolders := make(map[string]string)
+	var variants []*priceRaw
+
+	err = forEachPage[AssortmentPosition](ctx, priceEngine.client, EndpointAssortment, params, func(positions Slice[AssortmentPosition]) error {
+		for _, position := range positions {
+			var raw priceRaw
+			if err := json.Unmarshal(position.Raw(), &raw); err != nil {
+				return err
+			}
+
+			if raw.Meta.GetType() == MetaTypeVariant {
+				variants = append(variants, &raw)
+				continue
+			}
+
+			var folder string
+			if raw.ProductFolder != nil {
+				folder = folders[raw.ProductFolder.Meta.GetUUIDFromHref().String()]
+			}
+			productFolders[raw.ID] = folder
+			priceEngine.calculate(report, &raw, folder)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, raw := range variants {
+		var folder string
+		if raw.Product != nil {
+			folder = productFolders[raw.Product.Meta.GetUUIDFromHref().String()]
+		}
+		priceEngine.calculate(report, raw, folder)
+	}
+
+	sort.SliceStable(report.Changes, func(i, j int) bool {
+		if report.Changes[i].Name != report.Changes[j].Name {
+			return report.Changes[i].Name < report.Changes[j].Name
+		}
+		return report.Changes[i].PriceType < report.Changes[j].PriceType
+	})
+	return report, nil
+}
+
+// calculate рассчитывает цены позиции и добавляет изменения в отчёт.
+func (priceEngine *PriceEngine) calculate(report *PriceReport, raw *priceRaw, folder string) {
+	rule := priceEngine.ruleFor(folder)
+	if rule == nil {
+		return
+	}
+
+	entity := &priceEntity{meta: raw.Meta}
+	var changed bool
+	for _, salePrice := range raw.SalePrices {
+		price := &SalePrice{Value: Float(salePrice.GetValue()), Currency: salePrice.Currency, PriceType: salePrice.PriceType}
+		entity.salePrices.Push(price)
+
+		priceType := salePrice.GetPriceType().GetName()
+		if !priceEngine.matchPriceType(priceType) {
+			continue
+		}
+
+		value, ok := rule.apply(salePrice.GetValue(), Deref(raw.BuyPrice).GetValue(), Deref(raw.MinPrice).GetValue())
+		if !ok {
+			report.Skipped++
+			return
+		}
+		if value == salePrice.GetValue() {
+			continue
+		}
+
+		price.Value = Float(value)
+		changed = true
+		report.Changes = append(report.Changes, &PriceChange{
+			Meta:      raw.Meta,
+			Name:      raw.Name,
+			Folder:    folder,
+			PriceType: priceType,
+			Old:       salePrice.GetValue(),
+			New:       value,
+		})
+	}
+
+	if changed {
+		report.prices[raw.Meta.GetHref()] = entity
+	}
+}
+
+// PriceApplyResult результат сохранения цен методом [PriceEngine.Apply].
+type PriceApplyResult struct {
+	Applied   []*PriceChange // Сохранённые изменения цен
+	Failed    []*PriceChange // Изменения цен позиций, которые не удалось сохранить
+	PriceList *PriceList     // Созданный прайс-лист (nil, если создание прайс-листа не включено)
+}
+
+// Apply сохраняет цены, рассчитанные методом [PriceEngine.Preview].
+//
+// Товары и модификации изменяются запросами массового изменения, содержащими только цены продажи.
+// Ошибки сохранения отдельных позиций не прерывают применение: изменения цен таких позиций попадают
+// в поле Failed результата, а ошибки возвращаются объединённой ошибкой.
+// Если включено создание прайс-листа, он создаётся после сохранения цен и содержит только сохранённые изменения.
+func (priceEngine *PriceEngine) Apply(ctx context.Context, report *PriceReport) (*PriceApplyResult, error) {
+	var products Slice[Product]
+	var variants Slice[Variant]
+	for _, entity := range report.prices {
+		meta := entity.meta
+		switch meta.GetType() {
+		case MetaTypeProduct:
+			products.Push(&Product{Meta: &meta, SalePrices: entity.salePrices})
+		case MetaTypeVariant:
+			variants.Push(&Variant{Meta: &meta, SalePrices: entity.salePrices})
+		}
+	}
+
+	failed := make(map[string]struct{})
+	var errs []error
+	for start := 0; start < len(products); start += MaxPositions {
+		chunk := products[start:min(start+MaxPositions, len(products))]
+		_, itemErrs, err := createUpdateEach(ctx, priceEngine.client, EndpointProduct, chunk)
+		if err != nil {
+			return nil, fmt.Errorf("price engine: update products: %w", err)
+		}
+		for i, product := range chunk {
+			if itemErrs[i] != nil {
+				failed[product.GetMeta().GetHref()] = struct{}{}
+				errs = append(errs, fmt.Errorf("price engine: update product %s: %w", product.GetMeta().GetUUIDFromHref(), itemErrs[i]))
+			}
+		}
+	}
+
+	for start := 0; start < len(variants); start += MaxPositions {
+		chunk := variants[start:min(start+MaxPositions, len(variants))]
+		_, itemErrs, err := createUpdateEach(ctx, priceEngine.client, EndpointVariant, chunk)
+		if err != nil {
+			return nil, fmt.Errorf("price engine: update variants: %w", err)
+		}
+		for i, variant := range chunk {
+			if itemErrs[i] != nil {
+				failed[variant.GetMeta().GetHref()] = struct{}{}
+				errs = append(errs, fmt.Errorf("price engine: update variant %s: %w", variant.GetMeta().GetUUIDFromHref(), itemErrs[i]))
+			}
+		}
+	}
+
+	result := new(PriceApplyResult)
+	for _, change := range report.Changes {
+		if _, ok := failed[change.Meta.GetHref()]; ok {
+			result.Failed = append(result.Failed, change)
+		} else {
+			result.Applied = append(result.Applied, change)
+		}
+	}
+
+	if priceEngine.priceListName != "" && len(result.Applied) > 0 {
+		priceList, err := priceEngine.createPriceList(ctx, result.Applied)
+		if err != nil {
+			return result, errors.Join(append(errs, err)...)
+		}
+		result.PriceList = priceList
+	}
+	return result, errors.Join(errs...)
+}
+
+// createPriceList создаёт прайс-лист с новыми ценами.
+func (priceEngine *PriceEngine) createPriceList(ctx context.Context, changes []*PriceChange) (*PriceList, error) {
+	var columns Slice[PriceListColumn]
+	var order []string
+	positions := make(map[string]*PriceListPosition)
+	priceTypes := make(map[string]struct{})
+
+	for _, change := range changes {
+		if _, ok := priceTypes[change.PriceType]; !ok {
+			priceTypes[change.PriceType] = struct{}{}
+			columns.Push(new(PriceListColumn).SetName(change.PriceType))
+		}
+
+		href := change.Meta.GetHref()
+		position, ok := positions[href]
+		if !ok {
+			position = &PriceListPosition{Assortment: &AssortmentPosition{Meta: change.Meta}}
+			positions[href] = position
+			order = append(order, href)
+		}
+		position.SetCells(new(PriceListCell).SetColumn(change.PriceType).SetSum(change.New))
+	}
+
+	service := NewPriceListService(priceEngine.client)
+	priceList, _, err := service.Create(ctx, new(PriceList).SetName(priceEngine.priceListName).SetColumns(columns...))
+	if err != nil {
+		return nil, fmt.Errorf("price engine: create price list: %w", err)
+	}
+
+	var rows Slice[PriceListPosition]
+	for _, href := range order {
+		rows.Push(positions[href])
+	}
+	for start := 0; start < len(rows); start += MaxPositions {
+		if _, _, err = service.CreatePositionMany(ctx, priceList.GetID(), rows[start:min(start+MaxPositions, len(rows))]...); err != nil {
+			return nil, fmt.Errorf("price engine: create price list positions: %w", err)
+		}
+	}
+	return priceList, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/go-resty/resty/v2"
 	"github.com/google/uuid"
+	"io"
 	"time"
 )
 
@@ -603,6 +604,11 @@ type ProcessingService interface {
 	// Возвращает изменённую техоперацию.
 	Update(ctx context.Context, id uuid.UUID, processing *Processing, params ...*Params) (*Processing, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение техоперации, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Processing, processing *Processing, params ...*Params) (*Processing, *resty.Response, error)
+
 	// Template выполняет запрос на получение предзаполненной техоперации со стандартными полями без связи с какими-либо другими документами.
 	// Принимает контекст.
 	// Возвращает предзаполненную техоперацию.
@@ -756,6 +762,21 @@ type ProcessingService interface {
 	// Принимает контекст, ID сущности/документа и множество файлов.
 	// Возвращает объект DeleteManyResponse, содержащий информацию об успешном удалении или ошибку.
 	DeleteFileMany(ctx context.Context, id uuid.UUID, files ...*File) (*DeleteManyResponse, *resty.Response, error)
+
+	// PrintDocument выполняет запрос на печать отдельного документа по шаблону печатной формы.
+	// Принимает контекст, ID документа и объект PrintDocumentArg.
+	// Возвращает объект PrintFile.
+	PrintDocument(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg) (*PrintFile, *resty.Response, error)
+
+	// PrintDocumentTo выполняет запрос на печать отдельного документа по шаблону печатной формы.
+	// Принимает контекст, ID документа, объект PrintDocumentArg и io.Writer, в который записывается файл без полной буферизации.
+	// Возвращает название файла.
+	PrintDocumentTo(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg, w io.Writer) (string, *resty.Response, error)
+
+	// PrintDocumentMany выполняет асинхронный запрос на массовую печать документов.
+	// Принимает контекст, объект MassPrintArg и io.Writer, в который записывается полученный файл.
+	// Возвращает объект MassPrintResult, содержащий название файла и ошибки печати отдельных документов.
+	PrintDocumentMany(ctx context.Context, massPrintArg *MassPrintArg, w io.Writer) (*MassPrintResult, *resty.Response, error)
 }
 
 const (
@@ -776,6 +797,7 @@ type processingService struct {
 	endpointDelete[Processing]
 	endpointGetByID[Processing]
 	endpointUpdate[Processing]
+	endpointUpdateSafe[Processing]
 	endpointTemplate[Processing]
 	endpointTemplateBased[Processing]
 	endpointMetadata[MetaAttributesStatesSharedWrapper]
@@ -784,6 +806,7 @@ type processingService struct {
 	endpointTrash
 	endpointStates
 	endpointFiles
+	endpointPrintDocument
 }
 
 func (service *processingService) GetMaterialList(ctx context.Context, id uuid.UUID) (*List[ProcessingPlanMaterial], *resty.Response, error) {
@@ -859,6 +882,7 @@ func NewProcessingService(client *Client) ProcessingService {
 		endpointDelete:           endpointDelete[Processing]{e},
 		endpointGetByID:          endpointGetByID[Processing]{e},
 		endpointUpdate:           endpointUpdate[Processing]{e},
+		endpointUpdateSafe:       endpointUpdateSafe[Processing]{e},
 		endpointMetadata:         endpointMetadata[MetaAttributesStatesSharedWrapper]{e},
 		endpointAttributes:       endpointAttributes{e},
 		endpointSyncID:           endpointSyncID[Processing]{e},
@@ -867,5 +891,6 @@ func NewProcessingService(client *Client) ProcessingService {
 		endpointFiles:            endpointFiles{e},
 		endpointTemplate:         endpointTemplate[Processing]{e},
 		endpointTemplateBased:    endpointTemplateBased[Processing]{e},
+		endpointPrintDocument:    endpointPrintDocument{e},
 	}
 }
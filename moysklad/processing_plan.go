@@ -437,6 +437,7 @@ type ProcessingPlanService interface {
 	Delete(ctx context.Context, entity *ProcessingPlan) (bool, *resty.Response, error)
 	GetByID(ctx context.Context, id uuid.UUID, params ...*Params) (*ProcessingPlan, *resty.Response, error)
 	Update(ctx context.Context, id uuid.UUID, processingPlan *ProcessingPlan, params ...*Params) (*ProcessingPlan, *resty.Response, error)
+	UpdateIfUnchanged(ctx context.Context, expected *ProcessingPlan, processingPlan *ProcessingPlan, params ...*Params) (*ProcessingPlan, *resty.Response, error)
 
 	// GetPositionList выполняет запрос на получение списка позиций документа.
 	// Принимает контекст, ID документа и опционально объект параметров запроса Params.
@@ -527,6 +528,7 @@ type processingPlanService struct {
 	endpointDelete[ProcessingPlan]
 	endpointGetByID[ProcessingPlan]
 	endpointUpdate[ProcessingPlan]
+	endpointUpdateSafe[ProcessingPlan]
 	endpointPositions[ProcessingPlanProduct]
 	endpointTrash
 }
@@ -608,6 +610,7 @@ func NewProcessingPlanService(client *Client) ProcessingPlanService {
 		endpointDelete:           endpointDelete[ProcessingPlan]{e},
 		endpointGetByID:          endpointGetByID[ProcessingPlan]{e},
 		endpointUpdate:           endpointUpdate[ProcessingPlan]{e},
+		endpointUpdateSafe:       endpointUpdateSafe[ProcessingPlan]{e},
 		endpointPositions:        endpointPositions[ProcessingPlanProduct]{e},
 		endpointTrash:            endpointTrash{e},
 	}
@@ -174,6 +174,11 @@ type ProcessingPlanFolderService interface {
 	Delete(ctx context.Context, entity *ProcessingPlanFolder) (bool, *resty.Response, error)
 	GetByID(ctx context.Context, id uuid.UUID, params ...*Params) (*ProcessingPlanFolder, *resty.Response, error)
 	Update(ctx context.Context, id uuid.UUID, processingPlanFolder *ProcessingPlanFolder, params ...*Params) (*ProcessingPlanFolder, *resty.Response, error)
+
+	// UpdateIfUnchanged выполняет запрос на изменение группы техкарт, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *ProcessingPlanFolder, processingPlanFolder *ProcessingPlanFolder, params ...*Params) (*ProcessingPlanFolder, *resty.Response, error)
 	GetMetadata(ctx context.Context) (*MetaAttributesStatesSharedWrapper, *resty.Response, error)
 
 	// GetNamedFilterList выполняет запрос на получение списка фильтров.
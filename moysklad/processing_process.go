@@ -236,6 +236,11 @@ type ProcessingProcessService interface {
 	GetByID(ctx context.Context, id uuid.UUID, params ...*Params) (*ProcessingProcess, *resty.Response, error)
 	Update(ctx context.Context, id uuid.UUID, processingProcess *ProcessingProcess, params ...*Params) (*ProcessingProcess, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение техпроцесса, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *ProcessingProcess, processingProcess *ProcessingProcess, params ...*Params) (*ProcessingProcess, *resty.Response, error)
+
 	// GetPositionList выполняет запрос на получение списка позиций документа.
 	// Принимает контекст, ID документа и опционально объект параметров запроса Params.
 	// Возвращает объект List.
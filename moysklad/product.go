@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/go-resty/resty/v2"
 	"github.com/google/uuid"
+	"io"
 	"time"
 )
 
@@ -795,6 +796,11 @@ type ProductService interface {
 	// Возвращает изменённый товар.
 	Update(ctx context.Context, id uuid.UUID, product *Product, params ...*Params) (*Product, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение товара, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Product, product *Product, params ...*Params) (*Product, *resty.Response, error)
+
 	// GetMetadata выполняет запрос на получение метаданных товаров.
 	// Принимает контекст.
 	// Возвращает объект метаданных MetaAttributesSharedWrapper.
@@ -892,6 +898,11 @@ type ProductService interface {
 	// Возвращает объект PrintFile.
 	PrintLabel(ctx context.Context, id uuid.UUID, PrintLabelArg *PrintLabelArg) (*PrintFile, *resty.Response, error)
 
+	// PrintLabelTo выполняет запрос на печать этикеток и ценников.
+	// Принимает контекст, ID товара, объект PrintLabelArg и io.Writer, в который записывается файл без полной буферизации.
+	// Возвращает название файла.
+	PrintLabelTo(ctx context.Context, id uuid.UUID, PrintLabelArg *PrintLabelArg, w io.Writer) (string, *resty.Response, error)
+
 	// GetFileList выполняет запрос на получение файлов в виде списка.
 	// Принимает контекст и ID сущности/документа.
 	// Возвращает объект List.
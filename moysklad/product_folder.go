@@ -327,6 +327,11 @@ type ProductFolderService interface {
 	// Возвращает изменённую группу товаров.
 	Update(ctx context.Context, id uuid.UUID, productFolder *ProductFolder, params ...*Params) (*ProductFolder, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение группы товаров, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *ProductFolder, productFolder *ProductFolder, params ...*Params) (*ProductFolder, *resty.Response, error)
+
 	// GetMetadata выполняет запрос на получение метаданных групп товаров.
 	// Принимает контекст.
 	// Возвращает объект метаданных MetaAttributesWrapper.
@@ -0,0 +1,585 @@
+package moysklad
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// importFilterBatchSize количество значений поля сопоставления в одном запросе поиска существующих товаров.
+const importFilterBatchSize = 100
+
+// ImportField поле товара, в которое загружается значение столбца таблицы.
+//
+// Возможные значения:
+//   - ImportFieldName           – Наименование
+//   - ImportFieldArticle        – Артикул
+//   - ImportFieldCode           – Код
+//   - ImportFieldExternalCode   – Внешний код
+//   - ImportFieldDescription    – Описание
+//   - ImportFieldBarcode        – Штрихкод (тип указывается в Target, по умолчанию EAN13 или Code128)
+//   - ImportFieldSalePrice      – Цена продажи (наименование типа цены указывается в Target)
+//   - ImportFieldBuyPrice       – Закупочная цена
+//   - ImportFieldFolder         – Группа товаров (наименование или путь через «/»)
+//   - ImportFieldUom            – Единица измерения (наименование)
+//   - ImportFieldAttribute      – Доп. поле (наименование доп. поля указывается в Target)
+//   - ImportFieldVat            – НДС %
+//   - ImportFieldWeight         – Вес
+//   - ImportFieldMinimumBalance – Неснижаемый остаток
+type ImportField string
+
+const (
+	ImportFieldName           ImportField = "name"           // Наименование
+	ImportFieldArticle        ImportField = "article"        // Артикул
+	ImportFieldCode           ImportField = "code"           // Код
+	ImportFieldExternalCode   ImportField = "externalCode"   // Внешний код
+	ImportFieldDescription    ImportField = "description"    // Описание
+	ImportFieldBarcode        ImportField = "barcode"        // Штрихкод
+	ImportFieldSalePrice      ImportField = "salePrice"      // Цена продажи
+	ImportFieldBuyPrice       ImportField = "buyPrice"       // Закупочная цена
+	ImportFieldFolder         ImportField = "productFolder"  // Группа товаров
+	ImportFieldUom            ImportField = "uom"            // Единица измерения
+	ImportFieldAttribute      ImportField = "attribute"      // Доп. поле
+	ImportFieldVat            ImportField = "vat"            // НДС %
+	ImportFieldWeight         ImportField = "weight"         // Вес
+	ImportFieldMinimumBalance ImportField = "minimumBalance" // Неснижаемый остаток
+)
+
+// ImportColumn соответствие столбца таблицы полю товара.
+type ImportColumn struct {
+	Column string      // Заголовок столбца
+	Field  ImportField // Поле товара
+	Target string      // Уточнение поля: тип цены, доп. поле или тип штрихкода
+}
+
+// ImportMapping декларативное описание загрузки таблицы в товары.
+//
+// Цены в таблице указываются в рублях и переводятся в копейки при загрузке.
+// Дробная часть чисел может отделяться точкой или запятой.
+type ImportMapping struct {
+	Columns []ImportColumn // Соответствия столбцов полям товара
+	MatchBy ImportField    // Поле сопоставления с существующими товарами
+}
+
+// NewImportMapping принимает поле сопоставления с существующими товарами и возвращает новый объект [ImportMapping].
+//
+// Допустимые поля сопоставления: [ImportFieldArticle], [ImportFieldCode], [ImportFieldBarcode], [ImportFieldExternalCode].
+func NewImportMapping(matchBy ImportField) *ImportMapping {
+	return &ImportMapping{MatchBy: matchBy}
+}
+
+// Map добавляет соответствие столбца полю товара.
+func (importMapping *ImportMapping) Map(column string, field ImportField) *ImportMapping {
+	importMapping.Columns = append(importMapping.Columns, ImportColumn{Column: column, Field: field})
+	return importMapping
+}
+
+// MapSalePrice добавляет соответствие столбца цене продажи с указанным наименованием типа цены.
+func (importMapping *ImportMapping) MapSalePrice(column, priceTypeName string) *ImportMapping {
+	importMapping.Columns = append(importMapping.Columns, ImportColumn{column, ImportFieldSalePrice, priceTypeName})
+	return importMapping
+}
+
+// MapAttribute добавляет соответствие столбца доп. полю товара с указанным наименованием.
+func (importMapping *ImportMapping) MapAttribute(column, attributeName string) *ImportMapping {
+	importMapping.Columns = append(importMapping.Columns, ImportColumn{column, ImportFieldAttribute, attributeName})
+	return importMapping
+}
+
+// MapBarcode добавляет соответствие столбца штрихкоду указанного типа.
+func (importMapping *ImportMapping) MapBarcode(column string, barcodeType BarcodeType) *ImportMapping {
+	importMapping.Columns = append(importMapping.Columns, ImportColumn{column, ImportFieldBarcode, string(barcodeType)})
+	return importMapping
+}
+
+// ImportAction результат обработки строки таблицы.
+//
+// Возможные значения:
+//   - ImportActionCreated – Товар создан
+//   - ImportActionUpdated – Товар изменён
+//   - ImportActionSkipped – Строка пропущена
+//   - ImportActionFailed  – Ошибка обработки строки
+type ImportAction string
+
+const (
+	ImportActionCreated ImportAction = "created" // Товар создан
+	ImportActionUpdated ImportAction = "updated" // Товар изменён
+	ImportActionSkipped ImportAction = "skipped" // Строка пропущена
+	ImportActionFailed  ImportAction = "failed"  // Ошибка обработки строки
+)
+
+// ImportRowResult результат обработки строки таблицы.
+type ImportRowResult struct {
+	Row     int          // Номер строки таблицы (с учётом строки заголовков, начиная с 1)
+	Action  ImportAction // Результат обработки
+	Product *Product     // Созданный или изменённый товар
+	Err     error        // Ошибка обработки строки
+}
+
+// ImportReport отчёт о загрузке таблицы.
+type ImportReport struct {
+	Rows []*ImportRowResult // Результаты по строкам в порядке следования
+}
+
+// Count возвращает количество строк с указанным результатом обработки.
+func (importReport ImportReport) Count(action ImportAction) int {
+	var count int
+	for _, row := range importReport.Rows {
+		if row.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// Failed возвращает результаты строк, обработанных с ошибкой.
+func (importReport ImportReport) Failed() []*ImportRowResult {
+	var rows []*ImportRowResult
+	for _, row := range importReport.Rows {
+		if row.Action == ImportActionFailed {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// ProductImporter загружает товары из таблицы (CSV или XLSX) по декларативному описанию [ImportMapping].
+//
+// Группы товаров, типы цен, единицы измерения и доп. поля определяются по наименованию.
+// Существующие товары сопоставляются по полю ImportMapping.MatchBy и изменяются,
+// остальные товары создаются. Товары отправляются пакетами методом CreateUpdateMany.
+type ProductImporter struct {
+	client    *Client
+	mapping   *ImportMapping
+	chunkSize int
+	dryRun    bool
+
+	folders    map[string]*ProductFolder
+	priceTypes map[string]*PriceType
+	uoms       map[string]*Uom
+	attributes map[string]*Attribute
+}
+
+// NewProductImporter принимает [Client] и описание загрузки и возвращает новый объект [ProductImporter].
+func NewProductImporter(client *Client, mapping *ImportMapping) *ProductImporter {
+	return &ProductImporter{client: client, mapping: mapping, chunkSize: MaxPositions}
+}
+
+// WithChunkSize устанавливает количество товаров в одном запросе на создание/изменение.
+//
+// Диапазон значения: от 1 до [MaxPositions].
+func (productImporter *ProductImporter) WithChunkSize(chunkSize int) *ProductImporter {
+	productImporter.chunkSize = Clamp(chunkSize, 1, MaxPositions)
+	return productImporter
+}
+
+// WithDryRun включает режим проверки: строки разбираются и сопоставляются с существующими товарами,
+// но запросы на создание/изменение не выполняются.
+func (productImporter *ProductImporter) WithDryRun() *ProductImporter {
+	productImporter.dryRun = true
+	return productImporter
+}
+
+// importRow строка таблицы, подготовленная к отправке.
+type importRow struct {
+	result *ImportRowResult
+	key    string
+}
+
+// Import загружает строки таблицы и возвращает отчёт по строкам.
+//
+// Ошибки отдельных строк отражаются в отчёте; ошибка возвращается, если загрузка не может быть выполнена.
+func (productImporter *ProductImporter) Import(ctx context.Context, table *Table) (*ImportReport, error) {
+	columns := make([]int, len(productImporter.mapping.Columns))
+	for i, column := range productImporter.mapping.Columns {
+		if columns[i] = table.Column(column.Column); columns[i] < 0 {
+			return nil, fmt.Errorf("import: column %q not found", column.Column)
+		}
+	}
+
+	if err := productImporter.loadReferences(ctx); err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{}
+	var rows []*importRow
+	var keys = make(map[string]int)
+	for i := range table.Rows {
+		result := &ImportRowResult{Row: table.RowNumber(i)}
+		report.Rows = append(report.Rows, result)
+
+		product, key, err := productImporter.parseRow(table, i, columns)
+		switch {
+		case err != nil:
+			result.Action, result.Err = ImportActionFailed, err
+		case product == nil:
+			result.Action = ImportActionSkipped
+		default:
+			// повторное значение поля сопоставления привело бы к созданию дубликата товара
+			if row, ok := keys[key]; ok {
+				result.Action = ImportActionFailed
+				result.Err = fmt.Errorf("duplicate match field %q value %q (row %d)", productImporter.mapping.MatchBy, key, row)
+				continue
+			}
+			keys[key] = result.Row
+			result.Product = product
+			rows = append(rows, &importRow{result, key})
+		}
+	}
+
+	for start := 0; start < len(rows); start += productImporter.chunkSize {
+		chunk := rows[start:min(start+productImporter.chunkSize, len(rows))]
+		if err := productImporter.upsert(ctx, chunk); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// parseRow заполняет товар значениями строки таблицы.
+//
+// Возвращает nil, если все сопоставленные ячейки строки пусты.
+func (productImporter *ProductImporter) parseRow(table *Table, row int, columns []int) (*Product, string, error) {
+	product := new(Product)
+	var key string
+	var empty = true
+
+	for i, column := range productImporter.mapping.Columns {
+		value := table.Value(row, columns[i])
+		if value == "" {
+			continue
+		}
+		empty = false
+
+		if column.Field == productImporter.mapping.MatchBy && key == "" {
+			key = value
+		}
+
+		if err := productImporter.setField(product, column, value); err != nil {
+			return nil, "", fmt.Errorf("column %q: %w", column.Column, err)
+		}
+	}
+
+	if empty {
+		return nil, "", nil
+	}
+	if key == "" {
+		return nil, "", fmt.Errorf("empty match field %q", productImporter.mapping.MatchBy)
+	}
+	return product, key, nil
+}
+
+// setField устанавливает значение поля товара.
+func (productImporter *ProductImporter) setField(product *Product, column ImportColumn, value string) error {
+	switch column.Field {
+	case ImportFieldName:
+		product.SetName(value)
+	case ImportFieldArticle:
+		product.SetArticle(value)
+	case ImportFieldCode:
+		product.SetCode(value)
+	case ImportFieldExternalCode:
+		product.SetExternalCode(value)
+	case ImportFieldDescription:
+		product.SetDescription(value)
+	case ImportFieldBarcode:
+		barcode := &Barcode{BarcodeType(column.Target), value}
+		if barcode.Type == "" {
+			barcode.Type = BarcodeCode128
+			if ValidateGTIN(value, 13) == nil {
+				barcode.Type = BarcodeEAN13
+			}
+		}
+		product.Barcodes.Push(barcode)
+	case ImportFieldSalePrice:
+		priceType, ok := productImporter.priceTypes[strings.ToLower(column.Target)]
+		if !ok {
+			return fmt.Errorf("price type %q not found", column.Target)
+		}
+		price, err := parseImportFloat(value)
+		if err != nil {
+			return err
+		}
+		product.SetSalePrices(new(SalePrice).SetValue(math.Round(price * 100)).SetPriceType(priceType.Clean()))
+	case ImportFieldBuyPrice:
+		price, err := parseImportFloat(value)
+		if err != nil {
+			return err
+		}
+		product.SetBuyPrice(new(BuyPrice).SetValue(Float(math.Round(price * 100))))
+	case ImportFieldFolder:
+		folder, ok := productImporter.folders[strings.ToLower(strings.Trim(value, "/ "))]
+		if !ok {
+			return fmt.Errorf("product folder %q not found", value)
+		}
+		product.SetProductFolder(folder.Clean())
+	case ImportFieldUom:
+		uom, ok := productImporter.uoms[strings.ToLower(value)]
+		if !ok {
+			return fmt.Errorf("uom %q not found", value)
+		}
+		product.SetUom(uom.Clean())
+	case ImportFieldAttribute:
+		attribute, ok := productImporter.attributes[strings.ToLower(column.Target)]
+		if !ok {
+			return fmt.Errorf("attribute %q not found", column.Target)
+		}
+		attributeValue, err := parseImportAttribute(attribute.Type, value)
+		if err != nil {
+			return err
+		}
+		product.SetAttributes(new(Attribute).SetMeta(attribute.Meta).SetValue(attributeValue))
+	case ImportFieldVat:
+		vat, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+		if err != nil {
+			return err
+		}
+		product.SetVat(vat).SetVatEnabled(true)
+	case ImportFieldWeight:
+		weight, err := parseImportFloat(value)
+		if err != nil {
+			return err
+		}
+		product.SetWeight(weight)
+	case ImportFieldMinimumBalance:
+		minimumBalance, err := parseImportFloat(value)
+		if err != nil {
+			return err
+		}
+		product.SetMinimumBalance(minimumBalance)
+	default:
+		return fmt.Errorf("unknown field %q", column.Field)
+	}
+	return nil
+}
+
+// parseImportFloat разбирает число с разделителем дробной части точкой или запятой.
+func parseImportFloat(value string) (float64, error) {
+	value = strings.NewReplacer(" ", "", " ", "", ",", ".").Replace(value)
+	return strconv.ParseFloat(value, 64)
+}
+
+// parseImportAttribute преобразует значение ячейки в значение доп. поля указанного типа.
+func parseImportAttribute(attributeType AttributeType, value string) (any, error) {
+	switch attributeType {
+	case AttributeTypeString, AttributeTypeText, AttributeTypeLink:
+		return value, nil
+	case AttributeTypeLong:
+		return strconv.ParseInt(value, 10, 64)
+	case AttributeTypeDouble:
+		return parseImportFloat(value)
+	case AttributeTypeBoolean:
+		switch strings.ToLower(value) {
+		case "1", "true", "да", "+":
+			return true, nil
+		case "0", "false", "нет", "-":
+			return false, nil
+		}
+		return nil, fmt.Errorf("invalid boolean %q", value)
+	case AttributeTypeTime:
+		for _, layout := range []string{time.DateTime, time.DateOnly, "02.01.2006 15:04:05", "02.01.2006"} {
+			if t, err := time.Parse(layout, value); err == nil {
+				return NewTimestamp(t), nil
+			}
+		}
+		return nil, fmt.Errorf("invalid time %q", value)
+	}
+	return nil, fmt.Errorf("unsupported attribute type %q", attributeType)
+}
+
+// loadReferences загружает справочники, на которые ссылаются столбцы таблицы.
+func (productImporter *ProductImporter) loadReferences(ctx context.Context) error {
+	var needs = make(map[ImportField]bool)
+	for _, column := range productImporter.mapping.Columns {
+		needs[column.Field] = true
+	}
+
+	if needs[ImportFieldFolder] && productImporter.folders == nil {
+		productImporter.folders = make(map[string]*ProductFolder)
+		err := forEachPage[ProductFolder](ctx, productImporter.client, EndpointProductFolder, NewParams(), func(folders Slice[ProductFolder]) error {
+			for _, folder := range folders {
+				name := strings.ToLower(folder.GetName())
+				if _, ok := productImporter.folders[name]; !ok {
+					productImporter.folders[name] = folder
+				}
+				if pathName := folder.GetPathName(); pathName != "" {
+					productImporter.folders[strings.ToLower(pathName+"/"+folder.GetName())] = folder
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if needs[ImportFieldSalePrice] && productImporter.priceTypes == nil {
+		priceTypes, _, err := NewContextCompanySettingsService(productImporter.client).GetPriceTypes(ctx)
+		if err != nil {
+			return err
+		}
+		productImporter.priceTypes = make(map[string]*PriceType)
+		for _, priceType := range Deref(priceTypes) {
+			productImporter.priceTypes[strings.ToLower(priceType.GetName())] = priceType
+		}
+	}
+
+	if needs[ImportFieldUom] && productImporter.uoms == nil {
+		productImporter.uoms = make(map[string]*Uom)
+		err := forEachPage[Uom](ctx, productImporter.client, EndpointUom, NewParams(), func(uoms Slice[Uom]) error {
+			for _, uom := range uoms {
+				productImporter.uoms[strings.ToLower(uom.GetName())] = uom
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if needs[ImportFieldAttribute] && productImporter.attributes == nil {
+		attributes, _, err := NewProductService(productImporter.client).GetAttributeList(ctx)
+		if err != nil {
+			return err
+		}
+		productImporter.attributes = make(map[string]*Attribute)
+		for _, attribute := range attributes.Rows {
+			productImporter.attributes[strings.ToLower(attribute.GetName())] = attribute
+		}
+	}
+	return nil
+}
+
+// upsert сопоставляет товары пакета с существующими и выполняет запрос на создание/изменение.
+func (productImporter *ProductImporter) upsert(ctx context.Context, rows []*importRow) error {
+	existing, err := productImporter.findExisting(ctx, rows)
+	if err != nil {
+		return err
+	}
+
+	var products = Slice[Product]{}
+	for _, row := range rows {
+		row.result.Action = ImportActionCreated
+		if product, ok := existing[row.key]; ok {
+			mergeImportProduct(row.result.Product, product)
+			row.result.Action = ImportActionUpdated
+		}
+		products.Push(row.result.Product)
+	}
+
+	if productImporter.dryRun {
+		return nil
+	}
+
+	saved, errs, err := createUpdateEach(ctx, productImporter.client, EndpointProduct, products)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		for _, row := range rows {
+			row.result.Action, row.result.Err = ImportActionFailed, err
+		}
+		return nil
+	}
+
+	for i, row := range rows {
+		if errs[i] != nil {
+			row.result.Action, row.result.Err = ImportActionFailed, errs[i]
+			continue
+		}
+		row.result.Product = saved[i]
+	}
+	return nil
+}
+
+// mergeImportProduct связывает товар из таблицы с существующим товаром.
+//
+// Цены продажи и штрихкоды заменяются сервисом целиком, поэтому цены по типам цен
+// и штрихкоды существующего товара, отсутствующие в таблице, сохраняются.
+func mergeImportProduct(product, existing *Product) {
+	product.Meta = existing.Meta
+	product.ID = existing.ID
+
+	if len(product.SalePrices) > 0 {
+		var priceTypes = make(map[uuid.UUID]struct{}, len(product.SalePrices))
+		for _, salePrice := range product.SalePrices {
+			priceTypes[salePrice.GetPriceType().GetMeta().GetUUIDFromHref()] = struct{}{}
+		}
+		for _, salePrice := range existing.SalePrices {
+			if _, ok := priceTypes[salePrice.GetPriceType().GetMeta().GetUUIDFromHref()]; !ok {
+				product.SalePrices.Push(salePrice)
+			}
+		}
+	}
+
+	if len(product.Barcodes) > 0 {
+		var values = make(map[string]struct{}, len(product.Barcodes))
+		for _, barcode := range product.Barcodes {
+			values[barcode.Value] = struct{}{}
+		}
+		for _, barcode := range existing.Barcodes {
+			if _, ok := values[barcode.Value]; !ok {
+				product.Barcodes.Push(barcode)
+			}
+		}
+	}
+}
+
+// findExisting выполняет поиск существующих товаров по значениям поля сопоставления.
+func (productImporter *ProductImporter) findExisting(ctx context.Context, rows []*importRow) (map[string]*Product, error) {
+	var filter string
+	switch productImporter.mapping.MatchBy {
+	case ImportFieldArticle, ImportFieldCode, ImportFieldExternalCode, ImportFieldBarcode:
+		filter = string(productImporter.mapping.MatchBy)
+	default:
+		return nil, fmt.Errorf("import: unsupported match field %q", productImporter.mapping.MatchBy)
+	}
+
+	existing := make(map[string]*Product, len(rows))
+	for start := 0; start < len(rows); start += importFilterBatchSize {
+		params := NewParams().WithFilterEquals("type", string(MetaTypeProduct))
+		for _, row := range rows[start:min(start+importFilterBatchSize, len(rows))] {
+			params.WithFilterEquals(filter, row.key)
+		}
+
+		err := forEachPage[AssortmentPosition](ctx, productImporter.client, EndpointAssortment, params, func(positions Slice[AssortmentPosition]) error {
+			for _, position := range positions {
+				product := position.AsProduct()
+				if product == nil {
+					continue
+				}
+				for _, key := range importMatchKeys(product, productImporter.mapping.MatchBy) {
+					existing[key] = product
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return existing, nil
+}
+
+// importMatchKeys возвращает значения поля сопоставления товара.
+func importMatchKeys(product *Product, matchBy ImportField) []string {
+	switch matchBy {
+	case ImportFieldArticle:
+		return []string{product.GetArticle()}
+	case ImportFieldCode:
+		return []string{product.GetCode()}
+	case ImportFieldExternalCode:
+		return []string{product.GetExternalCode()}
+	case ImportFieldBarcode:
+		var keys []string
+		for _, barcode := range product.Barcodes {
+			keys = append(keys, barcode.Value)
+		}
+		return keys
+	}
+	return nil
+}
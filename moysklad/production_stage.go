@@ -190,6 +190,7 @@ func (ProductionTaskMaterial) MetaType() MetaType {
 // Сервис для работы с производственными этапами
 type ProductionStageService interface {
 	Update(ctx context.Context, id uuid.UUID, productionStage *ProductionStage, params ...*Params) (*ProductionStage, *resty.Response, error)
+	UpdateIfUnchanged(ctx context.Context, expected *ProductionStage, productionStage *ProductionStage, params ...*Params) (*ProductionStage, *resty.Response, error)
 	GetProductStages(ctx context.Context, productionTaskID uuid.UUID, params ...*Params) (*MetaArray[ProductionStage], *resty.Response, error)
 	GetMaterials(ctx context.Context, id uuid.UUID, params ...*Params) (*MetaArray[ProductionTaskMaterial], *resty.Response, error)
 	CreateMaterial(ctx context.Context, id uuid.UUID, productionTaskMaterial *ProductionTaskMaterial, params ...*Params) (*ProductionTaskMaterial, *resty.Response, error)
@@ -206,6 +207,7 @@ const (
 type productionStageService struct {
 	Endpoint
 	endpointUpdate[ProductionStage]
+	endpointUpdateSafe[ProductionStage]
 }
 
 // GetProductStages Получить список Производственных этапов Производственного задания.
@@ -268,7 +270,8 @@ func (service *productionStageService) DeleteMaterial(ctx context.Context, id uu
 func NewProductionStageService(client *Client) ProductionStageService {
 	e := NewEndpoint(client, EndpointProductionStage)
 	return &productionStageService{
-		Endpoint:       e,
-		endpointUpdate: endpointUpdate[ProductionStage]{e},
+		Endpoint:           e,
+		endpointUpdate:     endpointUpdate[ProductionStage]{e},
+		endpointUpdateSafe: endpointUpdateSafe[ProductionStage]{e},
 	}
 }
@@ -357,6 +357,7 @@ type ProductionStageCompletionService interface {
 	Delete(ctx context.Context, entity *ProductionStageCompletion) (bool, *resty.Response, error)
 	GetByID(ctx context.Context, id uuid.UUID, params ...*Params) (*ProductionStageCompletion, *resty.Response, error)
 	Update(ctx context.Context, id uuid.UUID, productionStageCompletion *ProductionStageCompletion, params ...*Params) (*ProductionStageCompletion, *resty.Response, error)
+	UpdateIfUnchanged(ctx context.Context, expected *ProductionStageCompletion, productionStageCompletion *ProductionStageCompletion, params ...*Params) (*ProductionStageCompletion, *resty.Response, error)
 	GetMaterials(ctx context.Context, id uuid.UUID, params ...*Params) (*MetaArray[ProductionStageCompletionMaterial], *resty.Response, error)
 	CreateMaterial(ctx context.Context, id uuid.UUID, productionStageCompletionMaterial *ProductionStageCompletionMaterial, params ...*Params) (*ProductionStageCompletionMaterial, *resty.Response, error)
 	UpdateMaterial(ctx context.Context, id uuid.UUID, materialID uuid.UUID, productionStageCompletionMaterial *ProductionStageCompletionMaterial, params ...*Params) (*ProductionStageCompletionMaterial, *resty.Response, error)
@@ -382,6 +383,7 @@ type productionStageCompletionService struct {
 	endpointDelete[ProductionStageCompletion]
 	endpointGetByID[ProductionStageCompletion]
 	endpointUpdate[ProductionStageCompletion]
+	endpointUpdateSafe[ProductionStageCompletion]
 }
 
 // GetMaterials Получить Материалы выполнения этапа производства.
@@ -446,5 +448,6 @@ func NewProductionStageCompletionService(client *Client) ProductionStageCompleti
 		endpointDelete:           endpointDelete[ProductionStageCompletion]{e},
 		endpointGetByID:          endpointGetByID[ProductionStageCompletion]{e},
 		endpointUpdate:           endpointUpdate[ProductionStageCompletion]{e},
+		endpointUpdateSafe:       endpointUpdateSafe[ProductionStageCompletion]{e},
 	}
 }
@@ -479,6 +479,7 @@ type ProductionTaskService interface {
 	DeleteAttributeMany(ctx context.Context, attributes ...*Attribute) (*DeleteManyResponse, *resty.Response, error)
 	GetByID(ctx context.Context, id uuid.UUID, params ...*Params) (*ProductionTask, *resty.Response, error)
 	Update(ctx context.Context, id uuid.UUID, productionTask *ProductionTask, params ...*Params) (*ProductionTask, *resty.Response, error)
+	UpdateIfUnchanged(ctx context.Context, expected *ProductionTask, productionTask *ProductionTask, params ...*Params) (*ProductionTask, *resty.Response, error)
 	DeleteByID(ctx context.Context, id uuid.UUID) (bool, *resty.Response, error)
 
 	// Delete выполняет запрос на удаление производственного задания.
@@ -581,6 +582,7 @@ type productionTaskService struct {
 	endpointAttributes
 	endpointGetByID[ProductionTask]
 	endpointUpdate[ProductionTask]
+	endpointUpdateSafe[ProductionTask]
 	endpointDeleteByID
 	endpointDelete[ProductionTask]
 	endpointPositions[ProductionRow]
@@ -659,6 +661,7 @@ func NewProductionTaskService(client *Client) ProductionTaskService {
 		endpointMetadata:         endpointMetadata[MetaAttributesStatesSharedWrapper]{e},
 		endpointGetByID:          endpointGetByID[ProductionTask]{e},
 		endpointUpdate:           endpointUpdate[ProductionTask]{e},
+		endpointUpdateSafe:       endpointUpdateSafe[ProductionTask]{e},
 		endpointPositions:        endpointPositions[ProductionRow]{e},
 		endpointDeleteByID:       endpointDeleteByID{e},
 		endpointDelete:           endpointDelete[ProductionTask]{e},
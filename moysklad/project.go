@@ -241,6 +241,11 @@ type ProjectService interface {
 	// Возвращает изменённый проект.
 	Update(ctx context.Context, id uuid.UUID, project *Project, params ...*Params) (*Project, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение проекта, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Project, project *Project, params ...*Params) (*Project, *resty.Response, error)
+
 	// GetMetadata выполняет запрос на получение метаданных проектов.
 	// Принимает контекст.
 	// Возвращает объект метаданных MetaAttributesSharedWrapper.
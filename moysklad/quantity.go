@@ -0,0 +1,246 @@
+package moysklad
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"math"
+)
+
+// quantityPrecision количество знаков после запятой, до которого округляются количества.
+const quantityPrecision = 4
+
+// DivisibleUomCodes коды ОКЕИ единиц измерения, допускающих дробное количество.
+//
+// Для остальных единиц измерения (например, «шт» с кодом 796) дробное количество допускается
+// только у весовых товаров.
+var DivisibleUomCodes = map[string]bool{
+	"006": true, // м
+	"055": true, // м2
+	"111": true, // мл
+	"112": true, // л
+	"113": true, // м3
+	"163": true, // г
+	"166": true, // кг
+	"168": true, // т
+}
+
+var (
+	// ErrFractionalQuantity ошибка дробного количества для единицы измерения, не допускающей дробного количества.
+	ErrFractionalQuantity = errors.New("quantity: fractional quantity is not allowed")
+
+	// ErrPackNotFound ошибка отсутствия упаковки у товара.
+	ErrPackNotFound = errors.New("quantity: pack not found")
+)
+
+// QuantityConverter выполняет перевод количества и цены позиции между упаковками и базовой единицей измерения товара.
+//
+// Количество позиции документа с упаковкой указывается в упаковках, цена – за упаковку.
+// Для проверки дробного количества по коду единицы измерения товар должен быть получен
+// с раскрытием единиц измерения (expand=uom,packs.uom); если код единицы измерения неизвестен,
+// дробное количество допускается.
+type QuantityConverter struct {
+	assortment AssortmentConverter
+	uom        Uom
+	weighted   bool
+	packs      Slice[Pack]
+	packIDs    map[uuid.UUID]uuid.UUID // ID упаковок модификации и соответствующие им ID упаковок товара
+}
+
+// NewQuantityConverter принимает товар и возвращает новый объект [QuantityConverter].
+func NewQuantityConverter(product *Product) *QuantityConverter {
+	return &QuantityConverter{
+		assortment: product,
+		uom:        product.GetUom(),
+		weighted:   product.GetWeighted(),
+		packs:      product.GetPacks(),
+	}
+}
+
+// ForVariant возвращает копию [QuantityConverter] для модификации товара.
+//
+// Модификация использует единицу измерения своего товара. Упаковки модификации ссылаются на упаковки товара
+// и переопределяют их штрихкоды, поэтому упаковки товара дополняются штрихкодами упаковок модификации,
+// а метод [QuantityConverter.PackByID] находит упаковку как по ID упаковки товара, так и по ID упаковки модификации.
+func (quantityConverter QuantityConverter) ForVariant(variant *Variant) *QuantityConverter {
+	quantityConverter.assortment = variant
+	quantityConverter.packIDs = make(map[uuid.UUID]uuid.UUID)
+
+	overrides := make(map[uuid.UUID]*VariantPack)
+	for _, variantPack := range variant.GetPacks() {
+		if variantPack.ParentPack == nil {
+			continue
+		}
+		// родительская упаковка может быть передана только метаданными
+		parentID := variantPack.ParentPack.GetID()
+		if parentID == uuid.Nil {
+			parentID = variantPack.ParentPack.GetMeta().GetUUIDFromHref()
+		}
+		overrides[parentID] = variantPack
+		quantityConverter.packIDs[variantPack.GetID()] = parentID
+	}
+
+	packs := make(Slice[Pack], 0, len(quantityConverter.packs))
+	for _, pack := range quantityConverter.packs {
+		packCopy := *pack
+		if variantPack, ok := overrides[pack.GetID()]; ok && len(variantPack.Barcodes) > 0 {
+			packCopy.Barcodes = variantPack.Barcodes
+		}
+		packs = append(packs, &packCopy)
+	}
+	quantityConverter.packs = packs
+	return &quantityConverter
+}
+
+// Uom возвращает базовую единицу измерения товара.
+func (quantityConverter QuantityConverter) Uom() Uom {
+	return quantityConverter.uom
+}
+
+// Packs возвращает упаковки товара.
+func (quantityConverter QuantityConverter) Packs() Slice[Pack] {
+	return quantityConverter.packs
+}
+
+// PackByID возвращает упаковку товара с указанным ID.
+//
+// Для модификации допускается ID упаковки модификации: возвращается соответствующая ей упаковка товара.
+func (quantityConverter QuantityConverter) PackByID(id uuid.UUID) (*Pack, error) {
+	if parentID, ok := quantityConverter.packIDs[id]; ok {
+		id = parentID
+	}
+	for _, pack := range quantityConverter.packs {
+		if pack.GetID() == id {
+			return pack, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: id %s", ErrPackNotFound, id)
+}
+
+// PackByUom возвращает упаковку товара с единицей измерения, имеющей указанный ID.
+func (quantityConverter QuantityConverter) PackByUom(uomID uuid.UUID) (*Pack, error) {
+	for _, pack := range quantityConverter.packs {
+		uom := pack.GetUom()
+		if uom.GetID() == uomID || uom.GetMeta().GetUUIDFromHref() == uomID {
+			return pack, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: uom %s", ErrPackNotFound, uomID)
+}
+
+// IsDivisible возвращает true, если количество товара в базовых единицах может быть дробным.
+func (quantityConverter QuantityConverter) IsDivisible() bool {
+	return quantityConverter.weighted || isDivisibleUom(quantityConverter.uom)
+}
+
+// isDivisibleUom возвращает true, если единица измерения допускает дробное количество или её код неизвестен.
+func isDivisibleUom(uom Uom) bool {
+	code := uom.GetCode()
+	return code == "" || DivisibleUomCodes[code]
+}
+
+// packQuantity возвращает количество базовых единиц в упаковке (1 без упаковки).
+func packQuantity(pack *Pack) float64 {
+	if pack == nil || pack.GetQuantity() <= 0 {
+		return 1
+	}
+	return pack.GetQuantity()
+}
+
+// roundQuantity округляет количество для устранения погрешностей вычислений с плавающей точкой.
+func roundQuantity(quantity float64) float64 {
+	scale := math.Pow10(quantityPrecision)
+	return math.Round(quantity*scale) / scale
+}
+
+// ToBase переводит количество в упаковках pack в количество в базовых единицах.
+//
+// Если pack равен nil, количество возвращается без изменений.
+func (quantityConverter QuantityConverter) ToBase(quantity float64, pack *Pack) float64 {
+	return roundQuantity(quantity * packQuantity(pack))
+}
+
+// FromBase переводит количество в базовых единицах в количество упаковок pack.
+//
+// Если pack равен nil, количество возвращается без изменений.
+func (quantityConverter QuantityConverter) FromBase(quantity float64, pack *Pack) float64 {
+	if pack == nil {
+		return quantity
+	}
+	return roundQuantity(quantity / packQuantity(pack))
+}
+
+// PackPrice переводит цену за базовую единицу в цену за упаковку pack, округлённую до копеек.
+//
+// Если pack равен nil, цена возвращается без изменений.
+func (quantityConverter QuantityConverter) PackPrice(price float64, pack *Pack) float64 {
+	if pack == nil {
+		return price
+	}
+	return math.Round(price * packQuantity(pack))
+}
+
+// BasePrice переводит цену за упаковку pack в цену за базовую единицу.
+//
+// Если pack равен nil, цена возвращается без изменений.
+func (quantityConverter QuantityConverter) BasePrice(price float64, pack *Pack) float64 {
+	return price / packQuantity(pack)
+}
+
+// Validate проверяет количество в упаковках pack (или в базовых единицах, если pack равен nil).
+//
+// Количество должно быть положительным. Для единиц измерения, не допускающих дробного количества,
+// количество упаковок и количество в базовых единицах должны быть целыми.
+func (quantityConverter QuantityConverter) Validate(quantity float64, pack *Pack) error {
+	if quantity <= 0 {
+		return fmt.Errorf("quantity: %v must be positive", quantity)
+	}
+	if quantityConverter.weighted {
+		return nil
+	}
+	if pack != nil && !isDivisibleUom(pack.GetUom()) && !isWholeQuantity(quantity) {
+		return fmt.Errorf("%w: %v packs of %q", ErrFractionalQuantity, quantity, pack.GetUom().GetName())
+	}
+	if base := quantityConverter.ToBase(quantity, pack); !quantityConverter.IsDivisible() && !isWholeQuantity(base) {
+		return fmt.Errorf("%w: %v %s", ErrFractionalQuantity, base, quantityConverter.uom.GetName())
+	}
+	return nil
+}
+
+// isWholeQuantity возвращает true, если количество целое с учётом точности.
+func isWholeQuantity(quantity float64) bool {
+	return roundQuantity(quantity) == math.Trunc(roundQuantity(quantity))
+}
+
+// packedPosition рассчитывает количество упаковок и цену за упаковку для количества в базовых единицах.
+func (quantityConverter QuantityConverter) packedPosition(baseQuantity, basePrice float64, pack *Pack) (float64, float64, error) {
+	quantity := quantityConverter.FromBase(baseQuantity, pack)
+	if err := quantityConverter.Validate(quantity, pack); err != nil {
+		return 0, 0, err
+	}
+	return quantity, quantityConverter.PackPrice(basePrice, pack), nil
+}
+
+// NewDemandPosition возвращает позицию отгрузки на количество baseQuantity в базовых единицах по цене basePrice
+// за базовую единицу (в копейках).
+//
+// Если передана упаковка, количество и цена позиции пересчитываются в упаковки.
+func (quantityConverter QuantityConverter) NewDemandPosition(baseQuantity, basePrice float64, pack *Pack) (*DemandPosition, error) {
+	position := new(DemandPosition).SetAssortment(quantityConverter.assortment)
+	if err := position.SetBaseQuantity(&quantityConverter, baseQuantity, basePrice, pack); err != nil {
+		return nil, err
+	}
+	return position, nil
+}
+
+// NewSupplyPosition возвращает позицию приёмки на количество baseQuantity в базовых единицах по цене basePrice
+// за базовую единицу (в копейках).
+//
+// Если передана упаковка, количество и цена позиции пересчитываются в упаковки.
+func (quantityConverter QuantityConverter) NewSupplyPosition(baseQuantity, basePrice float64, pack *Pack) (*SupplyPosition, error) {
+	position := new(SupplyPosition).SetAssortment(quantityConverter.assortment)
+	if err := position.SetBaseQuantity(&quantityConverter, baseQuantity, basePrice, pack); err != nil {
+		return nil, err
+	}
+	return position, nil
+}
@@ -0,0 +1,385 @@
+package moysklad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"math"
+	"strings"
+)
+
+var (
+	// ErrReceiptItemNotFound ошибка поиска позиции ассортимента по штрихкоду.
+	ErrReceiptItemNotFound = errors.New("receipt: item not found")
+
+	// ErrReceiptMarkingRequired ошибка продажи маркированного товара без кода маркировки.
+	ErrReceiptMarkingRequired = errors.New("receipt: marking code required")
+
+	// ErrReceiptDuplicateMarking ошибка повторного сканирования кода маркировки.
+	ErrReceiptDuplicateMarking = errors.New("receipt: duplicate marking code")
+
+	// ErrReceiptMarkingRejected ошибка продажи по коду маркировки, не прошедшему проверку.
+	ErrReceiptMarkingRejected = errors.New("receipt: marking code rejected")
+
+	// ErrReceiptNoPrice ошибка продажи позиции без цены по типу цен точки продаж.
+	ErrReceiptNoPrice = errors.New("receipt: item has no price")
+
+	// ErrReceiptCustomPrice ошибка продажи по свободной цене, если она запрещена точкой продаж.
+	ErrReceiptCustomPrice = errors.New("receipt: custom price is not allowed")
+
+	// ErrReceiptPayment ошибка несоответствия оплаты сумме чека.
+	ErrReceiptPayment = errors.New("receipt: payment does not match receipt sum")
+)
+
+// MarkingCheckStatus результат проверки кода маркировки.
+type MarkingCheckStatus int
+
+const (
+	MarkingCheckUnchecked MarkingCheckStatus = iota // Проверка не выполнялась или не удалась
+	MarkingCheckValid                               // Код маркировки прошёл проверку
+	MarkingCheckInvalid                             // Код маркировки не прошёл проверку
+)
+
+// MarkingChecker проверяет код маркировки перед продажей (например, в системе маркировки).
+type MarkingChecker func(ctx context.Context, cis string) (MarkingCheckStatus, error)
+
+// receiptItem поля позиции ассортимента, необходимые для продажи.
+type receiptItem struct {
+	Meta         Meta             `json:"meta"`
+	Name         string           `json:"name"`
+	SalePrices   Slice[SalePrice] `json:"salePrices"`
+	TrackingType TrackingType     `json:"trackingType"`
+	Product      *struct {
+		TrackingType TrackingType `json:"trackingType"`
+	} `json:"product"`
+}
+
+// isMarked возвращает true, если позиция подлежит маркировке.
+func (item receiptItem) isMarked() bool {
+	trackingType := item.TrackingType
+	if trackingType == "" && item.Product != nil {
+		trackingType = item.Product.TrackingType
+	}
+	return trackingType != "" && trackingType != TrackingTypeNotTracked
+}
+
+// ReceiptBuilder формирует розничную продажу по отсканированным штрихкодам и кодам маркировки.
+//
+// Цены позиций берутся из типа цен точки продаж; позиции без цены не добавляются. Позиции одного товара без кодов маркировки объединяются.
+// Правила точки продаж:
+//   - свободная цена позиции допускается, только если разрешена продажа по свободной цене;
+//   - маркированный товар продаётся только по коду маркировки;
+//   - коды маркировки допускаются в соответствии с режимом продажи маркированной продукции:
+//     [MarkingSellingModeCorrectMarksOnly] – только прошедшие проверку,
+//     [MarkingSellingModeWithoutErrors] – прошедшие проверку и непроверенные,
+//     [MarkingSellingModeAll] – все.
+//
+// Для разбора кодов маркировки используйте пакет marking.
+type ReceiptBuilder struct {
+	client         *Client
+	retailStore    *RetailStore
+	retailShift    *RetailShift
+	agent          *Counterparty
+	markingChecker MarkingChecker
+	barcodeRules   *BarcodeRules
+	positions      Slice[RetailDemandPosition]
+	items          map[*RetailDemandPosition]*receiptItem
+	cis            map[string]struct{}
+	cash           float64
+	noCash         float64
+	qr             float64
+}
+
+// NewReceiptBuilder принимает [Client], точку продаж и открытую розничную смену
+// и возвращает новый объект [ReceiptBuilder].
+func NewReceiptBuilder(client *Client, retailStore *RetailStore, retailShift *RetailShift) *ReceiptBuilder {
+	return &ReceiptBuilder{
+		client:      client,
+		retailStore: retailStore,
+		retailShift: retailShift,
+		items:       make(map[*RetailDemandPosition]*receiptItem),
+		cis:         make(map[string]struct{}),
+	}
+}
+
+// WithAgent устанавливает покупателя (обязательно, например, розничного покупателя по умолчанию).
+func (receiptBuilder *ReceiptBuilder) WithAgent(agent *Counterparty) *ReceiptBuilder {
+	receiptBuilder.agent = agent
+	return receiptBuilder
+}
+
+// WithMarkingChecker устанавливает функцию проверки кодов маркировки.
+//
+// Если функция не установлена, коды маркировки считаются непроверенными.
+func (receiptBuilder *ReceiptBuilder) WithMarkingChecker(markingChecker MarkingChecker) *ReceiptBuilder {
+	receiptBuilder.markingChecker = markingChecker
+	return receiptBuilder
+}
+
+// Positions возвращает позиции чека.
+func (receiptBuilder *ReceiptBuilder) Positions() Slice[RetailDemandPosition] {
+	return receiptBuilder.positions
+}
+
+// loadStore загружает точку продаж, если у переданного объекта не заполнены тип цен, склад или организация.
+func (receiptBuilder *ReceiptBuilder) loadStore(ctx context.Context) error {
+	retailStore := receiptBuilder.retailStore
+	if retailStore.PriceType != nil && retailStore.Store != nil && retailStore.Organization != nil {
+		return nil
+	}
+
+	id := retailStore.GetID()
+	if id == uuid.Nil {
+		id = retailStore.GetMeta().GetUUIDFromHref()
+	}
+	retailStore, _, err := NewRetailStoreService(receiptBuilder.client).GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("receipt: get retail store: %w", err)
+	}
+	receiptBuilder.retailStore = retailStore
+	return nil
+}
+
+// loadBarcodeRules загружает правила штрихкодов справочника один раз за время жизни [ReceiptBuilder].
+func (receiptBuilder *ReceiptBuilder) loadBarcodeRules(ctx context.Context) error {
+	if receiptBuilder.barcodeRules != nil {
+		return nil
+	}
+
+	settings, _, err := NewAssortmentService(receiptBuilder.client).GetSettings(ctx)
+	if err != nil {
+		return fmt.Errorf("receipt: get assortment settings: %w", err)
+	}
+	barcodeRules := settings.GetBarcodeRules()
+	receiptBuilder.barcodeRules = &barcodeRules
+	return nil
+}
+
+// find выполняет поиск позиции ассортимента по штрихкоду.
+func (receiptBuilder *ReceiptBuilder) find(ctx context.Context, barcode string) (*receiptItem, float64, error) {
+	if err := receiptBuilder.loadStore(ctx); err != nil {
+		return nil, 0, err
+	}
+	if err := receiptBuilder.loadBarcodeRules(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	positions, weight, _, err := NewAssortmentService(receiptBuilder.client).FindByBarcode(ctx, *receiptBuilder.barcodeRules, barcode)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(positions) == 0 {
+		return nil, 0, fmt.Errorf("%w: %s", ErrReceiptItemNotFound, barcode)
+	}
+
+	var item receiptItem
+	if err = json.Unmarshal(positions[0].Raw(), &item); err != nil {
+		return nil, 0, err
+	}
+	return &item, weight, nil
+}
+
+// price возвращает цену позиции по типу цен точки продаж.
+//
+// Если цена не указана или равна нулю, возвращается ошибка [ErrReceiptNoPrice].
+func (receiptBuilder *ReceiptBuilder) price(item *receiptItem) (float64, error) {
+	priceTypeID := receiptBuilder.retailStore.GetPriceType().GetMeta().GetUUIDFromHref()
+	for _, salePrice := range item.SalePrices {
+		if salePrice.GetPriceType().GetMeta().GetUUIDFromHref() == priceTypeID && salePrice.GetValue() > 0 {
+			return salePrice.GetValue(), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrReceiptNoPrice, item.Name)
+}
+
+// AddBarcode добавляет в чек товар по штрихкоду в количестве quantity.
+//
+// Для штрихкодов весовых товаров количество определяется весом из штрихкода.
+// Маркированный товар по штрихкоду не добавляется: возвращается ошибка [ErrReceiptMarkingRequired].
+// Если у товара нет цены по типу цен точки продаж, возвращается ошибка [ErrReceiptNoPrice].
+func (receiptBuilder *ReceiptBuilder) AddBarcode(ctx context.Context, barcode string, quantity float64) (*RetailDemandPosition, error) {
+	item, weight, err := receiptBuilder.find(ctx, barcode)
+	if err != nil {
+		return nil, err
+	}
+	if item.isMarked() {
+		return nil, fmt.Errorf("%w: %s", ErrReceiptMarkingRequired, item.Name)
+	}
+	if weight > 0 {
+		quantity = weight
+	}
+
+	// позиция того же товара без кодов маркировки объединяется
+	for _, position := range receiptBuilder.positions {
+		if existing := receiptBuilder.items[position]; existing != nil && existing.Meta.GetHref() == item.Meta.GetHref() {
+			position.SetQuantity(roundQuantity(position.GetQuantity() + quantity))
+			return position, nil
+		}
+	}
+	return receiptBuilder.add(item, quantity)
+}
+
+// AddMarked добавляет в чек единицу маркированного товара с кодом маркировки trackingCode.
+//
+// Товар определяется по GTIN кода маркировки. Код маркировки проверяется только для найденного товара с ценой.
+func (receiptBuilder *ReceiptBuilder) AddMarked(ctx context.Context, gtin string, trackingCode *TrackingCode) (*RetailDemandPosition, error) {
+	cis := trackingCode.GetCis()
+	if _, ok := receiptBuilder.cis[cis]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrReceiptDuplicateMarking, cis)
+	}
+
+	item, _, err := receiptBuilder.find(ctx, gtin)
+	if errors.Is(err, ErrReceiptItemNotFound) && strings.HasPrefix(gtin, "0") {
+		// штрихкод EAN13 хранится без ведущего нуля GTIN
+		item, _, err = receiptBuilder.find(ctx, gtin[1:])
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err = receiptBuilder.price(item); err != nil {
+		return nil, err
+	}
+
+	status := MarkingCheckUnchecked
+	if receiptBuilder.markingChecker != nil {
+		if status, err = receiptBuilder.markingChecker(ctx, cis); err != nil {
+			status = MarkingCheckUnchecked
+		}
+	}
+	if !receiptBuilder.markingAllowed(status) {
+		return nil, fmt.Errorf("%w: %s (%s)", ErrReceiptMarkingRejected, cis, receiptBuilder.retailStore.MarkingSellingMode)
+	}
+
+	position, err := receiptBuilder.add(item, 1)
+	if err != nil {
+		return nil, err
+	}
+	position.SetTrackingCodes(trackingCode)
+	receiptBuilder.cis[cis] = struct{}{}
+	return position, nil
+}
+
+// markingAllowed возвращает true, если код маркировки с результатом проверки status допускается к продаже.
+func (receiptBuilder *ReceiptBuilder) markingAllowed(status MarkingCheckStatus) bool {
+	switch receiptBuilder.retailStore.MarkingSellingMode {
+	case MarkingSellingModeCorrectMarksOnly:
+		return status == MarkingCheckValid
+	case MarkingSellingModeAll:
+		return true
+	default:
+		return status != MarkingCheckInvalid
+	}
+}
+
+// add добавляет позицию в чек по цене точки продаж.
+func (receiptBuilder *ReceiptBuilder) add(item *receiptItem, quantity float64) (*RetailDemandPosition, error) {
+	price, err := receiptBuilder.price(item)
+	if err != nil {
+		return nil, err
+	}
+
+	position := new(RetailDemandPosition).
+		SetQuantity(quantity).
+		SetPrice(price)
+	position.Assortment = &AssortmentPosition{Meta: item.Meta}
+	receiptBuilder.positions.Push(position)
+	receiptBuilder.items[position] = item
+	return position, nil
+}
+
+// SetPrice устанавливает свободную цену позиции (в копейках).
+//
+// Если точка продаж не разрешает продажу по свободной цене, возвращается ошибка [ErrReceiptCustomPrice].
+func (receiptBuilder *ReceiptBuilder) SetPrice(position *RetailDemandPosition, price float64) error {
+	if !receiptBuilder.retailStore.GetAllowCustomPrice() {
+		return ErrReceiptCustomPrice
+	}
+	position.SetPrice(price)
+	return nil
+}
+
+// Remove удаляет позицию из чека.
+func (receiptBuilder *ReceiptBuilder) Remove(position *RetailDemandPosition) {
+	for i, p := range receiptBuilder.positions {
+		if p == position {
+			receiptBuilder.positions = append(receiptBuilder.positions[:i], receiptBuilder.positions[i+1:]...)
+			break
+		}
+	}
+	for _, trackingCode := range position.TrackingCodes {
+		delete(receiptBuilder.cis, trackingCode.GetCis())
+	}
+	delete(receiptBuilder.items, position)
+}
+
+// Sum возвращает сумму чека с учётом скидок позиций (в копейках).
+func (receiptBuilder *ReceiptBuilder) Sum() float64 {
+	var sum float64
+	for _, position := range receiptBuilder.positions {
+		sum += math.Round(position.GetPrice() * position.GetQuantity() * (1 - position.GetDiscount()/100))
+	}
+	return sum
+}
+
+// Pay устанавливает оплату наличными, картой и по QR-коду (в копейках).
+func (receiptBuilder *ReceiptBuilder) Pay(cash, noCash, qr float64) *ReceiptBuilder {
+	receiptBuilder.cash, receiptBuilder.noCash, receiptBuilder.qr = cash, noCash, qr
+	return receiptBuilder
+}
+
+// Change возвращает сдачу с оплаты наличными (в копейках).
+func (receiptBuilder *ReceiptBuilder) Change() float64 {
+	return max(receiptBuilder.cash+receiptBuilder.noCash+receiptBuilder.qr-receiptBuilder.Sum(), 0)
+}
+
+// Build проверяет оплату и возвращает розничную продажу.
+//
+// Безналичная оплата не может превышать сумму чека; наличная оплата сверх суммы чека считается сдачей.
+func (receiptBuilder *ReceiptBuilder) Build() (*RetailDemand, error) {
+	if len(receiptBuilder.positions) == 0 {
+		return nil, fmt.Errorf("receipt: no positions")
+	}
+	if receiptBuilder.agent == nil {
+		return nil, fmt.Errorf("receipt: agent is required")
+	}
+
+	sum := receiptBuilder.Sum()
+	cashless := receiptBuilder.noCash + receiptBuilder.qr
+	if cashless > sum || receiptBuilder.cash+cashless < sum {
+		return nil, fmt.Errorf("%w: sum %.2f, paid %.2f", ErrReceiptPayment, sum/100, (receiptBuilder.cash+cashless)/100)
+	}
+
+	retailStore := receiptBuilder.retailStore
+	retailDemand := new(RetailDemand).
+		SetRetailStore(retailStore).
+		SetRetailShift(receiptBuilder.retailShift).
+		SetAgent(receiptBuilder.agent).
+		SetOrganization(retailStore.Organization).
+		SetStore(retailStore.Store).
+		SetPositions(receiptBuilder.positions...).
+		SetCashSum(sum - cashless).
+		SetNoCashSum(receiptBuilder.noCash).
+		SetQRSum(receiptBuilder.qr)
+
+	return retailDemand, nil
+}
+
+// Create проверяет оплату и выполняет запрос на создание розничной продажи.
+func (receiptBuilder *ReceiptBuilder) Create(ctx context.Context) (*RetailDemand, error) {
+	if err := receiptBuilder.loadStore(ctx); err != nil {
+		return nil, err
+	}
+
+	retailDemand, err := receiptBuilder.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	created, _, err := NewRetailDemandService(receiptBuilder.client).Create(ctx, retailDemand)
+	if err != nil {
+		return nil, fmt.Errorf("receipt: create retail demand: %w", err)
+	}
+	return created, nil
+}
@@ -2,9 +2,11 @@ package moysklad
 
 import (
 	"context"
+	"fmt"
 	"github.com/go-resty/resty/v2"
 	"github.com/goccy/go-json"
 	"github.com/google/go-querystring/query"
+	"io"
 	"log"
 	"net/http"
 	"reflect"
@@ -202,6 +204,60 @@ func (requestBuilder *RequestBuilder[T]) Delete(ctx context.Context) (bool, *res
 	return resp.StatusCode() == http.StatusOK, resp, err
 }
 
+// Stream выполняет запрос и записывает тело ответа в w без полной буферизации в памяти.
+//
+// В случае ответа с ошибкой тело ответа разбирается как [ApiErrors].
+func (requestBuilder *RequestBuilder[T]) Stream(ctx context.Context, method string, body any, w io.Writer) (*resty.Response, error) {
+	// Ограничения на количество запросов
+	requestBuilder.client.limits.Wait()
+	defer requestBuilder.client.limits.Done()
+
+	resp, err := requestBuilder.req.SetContext(ctx).SetBody(body).SetDoNotParseResponse(true).Execute(method, requestBuilder.uri)
+	if err != nil {
+		return resp, err
+	}
+
+	rawBody := resp.RawBody()
+	defer func() {
+		_ = rawBody.Close()
+	}()
+
+	if resp.StatusCode() >= http.StatusBadRequest {
+		var apiErrors ApiErrors
+		if err = json.NewDecoder(rawBody).Decode(&apiErrors); err != nil {
+			return resp, err
+		}
+		return resp, apiErrors
+	}
+
+	_, err = io.Copy(w, rawBody)
+	return resp, err
+}
+
+// AsyncPost выполняет запрос методом POST на создание асинхронной задачи.
+func (requestBuilder *RequestBuilder[T]) AsyncPost(ctx context.Context, body any) (AsyncResultService[T], *resty.Response, error) {
+	// Ограничения на количество запросов
+	requestBuilder.client.limits.Wait()
+	defer requestBuilder.client.limits.Done()
+
+	// устанавливаем флаг async=true на создание асинхронной операции
+	resp, err := requestBuilder.req.SetContext(ctx).SetQueryParam("async", "true").SetBody(body).Post(requestBuilder.uri)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	if resp.StatusCode() >= http.StatusBadRequest {
+		var apiErrors ApiErrors
+		if err = json.Unmarshal(resp.Body(), &apiErrors); err != nil {
+			return nil, resp, err
+		}
+		return nil, resp, apiErrors
+	}
+
+	async := NewAsyncResultService[T](requestBuilder.client, resp)
+	return async, resp, nil
+}
+
 func (requestBuilder *RequestBuilder[T]) Async(ctx context.Context) (AsyncResultService[T], *resty.Response, error) {
 	// Ограничения на количество запросов
 	requestBuilder.client.limits.Wait()
@@ -225,6 +281,75 @@ func FetchMeta[T any](ctx context.Context, client *Client, meta Meta, params ...
 	return NewRequestBuilder[T](client, strings.ReplaceAll(meta.GetHref(), baseApiURL, "")).SetParams(params...).Get(ctx)
 }
 
+// forEachPage выполняет постраничные запросы списка объектов T по адресу path и передаёт каждую страницу в функцию fn.
+//
+// Параметры запроса params копируются, значения limit и offset устанавливаются автоматически.
+func forEachPage[T any](ctx context.Context, client *Client, path string, params *Params, fn func(rows Slice[T]) error) error {
+	params = params.Clone()
+	if params.Limit == 0 {
+		params.WithLimit(MaxPositions)
+	}
+
+	for offset := 0; ; offset += params.Limit {
+		list, _, err := NewRequestBuilder[List[T]](client, path).SetParams(params.WithOffset(offset)).Get(ctx)
+		if err != nil {
+			return err
+		}
+
+		if list == nil {
+			return nil
+		}
+
+		if err = fn(list.Rows); err != nil {
+			return err
+		}
+
+		if list.Len() < params.Limit || offset+list.Len() >= list.Size() {
+			return nil
+		}
+	}
+}
+
+// createUpdateEach выполняет запрос на массовое создание и/или изменение объектов T по адресу path
+// и возвращает результат по каждому объекту в порядке следования.
+//
+// В отличие от метода CreateUpdateMany, ошибки отдельных объектов не объединяются:
+// i-й элемент errs содержит ошибку i-го объекта или nil. Ошибка запроса целиком возвращается в err.
+func createUpdateEach[T any](ctx context.Context, client *Client, path string, entities Slice[T]) ([]*T, []error, error) {
+	_, resp, err := NewRequestBuilder[Slice[T]](client, path).Post(ctx, entities)
+	if resp == nil {
+		return nil, nil, err
+	}
+
+	var raws []json.RawMessage
+	if json.Unmarshal(resp.Body(), &raws) != nil || len(raws) != len(entities) {
+		if err == nil {
+			err = fmt.Errorf("create update many: unexpected response for %d entities", len(entities))
+		}
+		return nil, nil, err
+	}
+
+	var (
+		results = make([]*T, len(raws))
+		errs    = make([]error, len(raws))
+	)
+	for i, raw := range raws {
+		var apiErrors ApiErrors
+		if json.Unmarshal(raw, &apiErrors) == nil && len(apiErrors.ApiErrors) > 0 {
+			errs[i] = apiErrors
+			continue
+		}
+
+		var entity T
+		if err = json.Unmarshal(raw, &entity); err != nil {
+			errs[i] = err
+			continue
+		}
+		results[i] = &entity
+	}
+	return results, errs, nil
+}
+
 // Context объект, содержащий метаданные о выполнившем запрос сотруднике.
 type Context struct {
 	Employee MetaWrapper `json:"employee,omitempty"`
@@ -647,18 +647,19 @@ func (retailDemand *RetailDemand) Delete(ctx context.Context, client *Client) (b
 //
 // [Документация МойСклад]: https://dev.moysklad.ru/doc/api/remap/1.2/documents/#dokumenty-roznichnaq-prodazha-roznichnye-prodazhi-pozicii-roznichnoj-prodazhi
 type RetailDemandPosition struct {
-	AccountID  *uuid.UUID          `json:"accountId,omitempty"`  // ID учётной записи
-	Assortment *AssortmentPosition `json:"assortment,omitempty"` // Метаданные товара/услуги/серии/модификации, которую представляет собой позиция
-	Cost       *float64            `json:"cost,omitempty"`       // Себестоимость (только для услуг)
-	Discount   *float64            `json:"discount,omitempty"`   // Процент скидки или наценки. Наценка указывается отрицательным числом, т.е. -10 создаст наценку в 10%
-	ID         *uuid.UUID          `json:"id,omitempty"`         // ID позиции
-	Pack       *Pack               `json:"pack,omitempty"`       // Упаковка Товара
-	Price      *float64            `json:"price,omitempty"`      // Цена товара/услуги в копейках
-	Quantity   *float64            `json:"quantity,omitempty"`   // Количество товаров/услуг данного вида в позиции. Если позиция - товар, у которого включен учет по серийным номерам, то значение в этом поле всегда будет равно количеству серийных номеров для данной позиции в документе.
-	Vat        *int                `json:"vat,omitempty"`        // НДС, которым облагается текущая позиция
-	VatEnabled *bool               `json:"vatEnabled,omitempty"` // Включен ли НДС для позиции. С помощью этого флага для позиции можно выставлять НДС = 0 или НДС = "без НДС". (vat = 0, vatEnabled = false) -> vat = "без НДС", (vat = 0, vatEnabled = true) -> vat = 0%.
-	Stock      *Stock              `json:"stock,omitempty"`      // Остатки и себестоимость позиции (указывается при наличии параметра запроса `fields=stock`)
-	Things     Slice[string]       `json:"things,omitempty"`     // Серийные номера. Значение данного атрибута игнорируется, если товар позиции не находится на серийном учете. В ином случае количество товаров в позиции будет равно количеству серийных номеров, переданных в значении атрибута.
+	AccountID     *uuid.UUID          `json:"accountId,omitempty"`     // ID учётной записи
+	Assortment    *AssortmentPosition `json:"assortment,omitempty"`    // Метаданные товара/услуги/серии/модификации, которую представляет собой позиция
+	Cost          *float64            `json:"cost,omitempty"`          // Себестоимость (только для услуг)
+	Discount      *float64            `json:"discount,omitempty"`      // Процент скидки или наценки. Наценка указывается отрицательным числом, т.е. -10 создаст наценку в 10%
+	ID            *uuid.UUID          `json:"id,omitempty"`            // ID позиции
+	Pack          *Pack               `json:"pack,omitempty"`          // Упаковка Товара
+	Price         *float64            `json:"price,omitempty"`         // Цена товара/услуги в копейках
+	Quantity      *float64            `json:"quantity,omitempty"`      // Количество товаров/услуг данного вида в позиции. Если позиция - товар, у которого включен учет по серийным номерам, то значение в этом поле всегда будет равно количеству серийных номеров для данной позиции в документе.
+	Vat           *int                `json:"vat,omitempty"`           // НДС, которым облагается текущая позиция
+	VatEnabled    *bool               `json:"vatEnabled,omitempty"`    // Включен ли НДС для позиции. С помощью этого флага для позиции можно выставлять НДС = 0 или НДС = "без НДС". (vat = 0, vatEnabled = false) -> vat = "без НДС", (vat = 0, vatEnabled = true) -> vat = 0%.
+	Stock         *Stock              `json:"stock,omitempty"`         // Остатки и себестоимость позиции (указывается при наличии параметра запроса `fields=stock`)
+	Things        Slice[string]       `json:"things,omitempty"`        // Серийные номера. Значение данного атрибута игнорируется, если товар позиции не находится на серийном учете. В ином случае количество товаров в позиции будет равно количеству серийных номеров, переданных в значении атрибута.
+	TrackingCodes Slice[TrackingCode] `json:"trackingCodes,omitempty"` // Коды маркировки товаров и транспортных упаковок
 }
 
 // GetAccountID возвращает ID учётной записи.
@@ -714,6 +715,11 @@ func (retailDemandPosition RetailDemandPosition) GetThings() Slice[string] {
 	return retailDemandPosition.Things
 }
 
+// GetTrackingCodes возвращает Коды маркировки товаров и транспортных упаковок.
+func (retailDemandPosition RetailDemandPosition) GetTrackingCodes() Slice[TrackingCode] {
+	return retailDemandPosition.TrackingCodes
+}
+
 // GetVat возвращает НДС, которым облагается текущая позиция.
 func (retailDemandPosition RetailDemandPosition) GetVat() int {
 	return Deref(retailDemandPosition.Vat)
@@ -797,6 +803,14 @@ func (retailDemandPosition *RetailDemandPosition) SetThings(things ...string) *R
 	return retailDemandPosition
 }
 
+// SetTrackingCodes устанавливает Коды маркировки товаров и транспортных упаковок.
+//
+// Принимает множество объектов [TrackingCode].
+func (retailDemandPosition *RetailDemandPosition) SetTrackingCodes(trackingCodes ...*TrackingCode) *RetailDemandPosition {
+	retailDemandPosition.TrackingCodes.Push(trackingCodes...)
+	return retailDemandPosition
+}
+
 // AsRetailSalesReturnPosition преобразует позицию розничной продажи в позицию розничного возврата.
 //
 // Копирует все поля позиции, кроме ID и AccountID.
@@ -871,6 +885,11 @@ type RetailDemandService interface {
 	// Возвращает изменённую розничную продажу.
 	Update(ctx context.Context, id uuid.UUID, retailDemand *RetailDemand, params ...*Params) (*RetailDemand, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение розничной продажи, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *RetailDemand, retailDemand *RetailDemand, params ...*Params) (*RetailDemand, *resty.Response, error)
+
 	// Template выполняет запрос на получение предзаполненной розничной продажи со стандартными полями без связи с какими-либо другими документами.
 	// Принимает контекст.
 	// Возвращает предзаполненную розничную продажу.
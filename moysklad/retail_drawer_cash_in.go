@@ -374,6 +374,11 @@ type RetailDrawerCashInService interface {
 	// Возвращает изменённое внесение денег.
 	Update(ctx context.Context, id uuid.UUID, retailDrawerCashIn *RetailDrawerCashIn, params ...*Params) (*RetailDrawerCashIn, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение внесения денег, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *RetailDrawerCashIn, retailDrawerCashIn *RetailDrawerCashIn, params ...*Params) (*RetailDrawerCashIn, *resty.Response, error)
+
 	// Template выполняет запрос на получение предзаполненного внесения денег со стандартными полями без связи с какими-либо другими документами.
 	// Принимает контекст.
 	// Возвращает предзаполненное внесение денег.
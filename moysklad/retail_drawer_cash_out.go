@@ -386,6 +386,11 @@ type RetailDrawerCashOutService interface {
 	// Возвращает изменённую выплату денег.
 	Update(ctx context.Context, id uuid.UUID, retailDrawerCashOut *RetailDrawerCashOut, params ...*Params) (*RetailDrawerCashOut, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение выплаты денег, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *RetailDrawerCashOut, retailDrawerCashOut *RetailDrawerCashOut, params ...*Params) (*RetailDrawerCashOut, *resty.Response, error)
+
 	// Template выполняет запрос на получение предзаполненной выплаты денег со стандартными полями без связи с какими-либо другими документами.
 	// Принимает контекст.
 	// Возвращает предзаполненную выплату денег.
@@ -28,6 +28,7 @@ type RetailSalesReturn struct {
 	Demand              *RetailDemand                         `json:"demand,omitempty"`              // Метаданные розничной продажи, по которой произошел возврат
 	Description         *string                               `json:"description,omitempty"`         // Комментарий Розничного возврата
 	ExternalCode        *string                               `json:"externalCode,omitempty"`        // Внешний код Розничного возврата
+	Fiscal              *bool                                 `json:"fiscal,omitempty"`              // Отметка о том, был ли использован ФР
 	Group               *Group                                `json:"group,omitempty"`               // Отдел сотрудника
 	ID                  *uuid.UUID                            `json:"id,omitempty"`                  // ID Розничного возврата
 	Meta                *Meta                                 `json:"meta,omitempty"`                // Метаданные Розничного возврата
@@ -137,6 +138,11 @@ func (retailSalesReturn RetailSalesReturn) GetExternalCode() string {
 	return Deref(retailSalesReturn.ExternalCode)
 }
 
+// GetFiscal возвращает Отметку о том, был ли использован ФР.
+func (retailSalesReturn RetailSalesReturn) GetFiscal() bool {
+	return Deref(retailSalesReturn.Fiscal)
+}
+
 // GetGroup возвращает Отдел сотрудника.
 func (retailSalesReturn RetailSalesReturn) GetGroup() Group {
 	return Deref(retailSalesReturn.Group)
@@ -752,6 +758,11 @@ type RetailSalesReturnService interface {
 	// Возвращает изменённый розничный возврат.
 	Update(ctx context.Context, id uuid.UUID, retailSalesReturn *RetailSalesReturn, params ...*Params) (*RetailSalesReturn, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение розничного возврата, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *RetailSalesReturn, retailSalesReturn *RetailSalesReturn, params ...*Params) (*RetailSalesReturn, *resty.Response, error)
+
 	// TemplateBased выполняет запрос на получение шаблона розничного возврата на основе других документов.
 	// Основание, на котором может быть создано:
 	//	- Розничная смена (RetailShift)
@@ -568,6 +568,11 @@ type RetailShiftService interface {
 	// Возвращает изменённую розничную смену.
 	Update(ctx context.Context, id uuid.UUID, retailShift *RetailShift, params ...*Params) (*RetailShift, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение розничной смены, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *RetailShift, retailShift *RetailShift, params ...*Params) (*RetailShift, *resty.Response, error)
+
 	// GetMetadata выполняет запрос на получение метаданных розничных смен.
 	// Принимает контекст.
 	// Возвращает объект метаданных MetaAttributesStatesSharedWrapper.
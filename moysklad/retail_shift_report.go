@@ -0,0 +1,272 @@
+package moysklad
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"math"
+	"time"
+)
+
+// retailShiftTolerance допустимое расхождение сумм (в копейках).
+const retailShiftTolerance = 1
+
+// ShiftMismatchKind вид расхождения при сверке розничной смены.
+type ShiftMismatchKind string
+
+const (
+	ShiftMismatchProceedsCash     ShiftMismatchKind = "proceedsCash"     // Выручка наличными смены не совпадает с суммой документов
+	ShiftMismatchProceedsNoCash   ShiftMismatchKind = "proceedsNoCash"   // Безналичная выручка смены не совпадает с суммой документов
+	ShiftMismatchBankCommission   ShiftMismatchKind = "bankCommission"   // Комиссия эквайера по картам не совпадает с расчётной
+	ShiftMismatchQRBankCommission ShiftMismatchKind = "qrBankCommission" // Комиссия эквайера по QR-коду не совпадает с расчётной
+	ShiftMismatchChequeCount      ShiftMismatchKind = "chequeCount"      // Количество фискальных чеков не совпадает с данными ККТ
+	ShiftMismatchOutOfRange       ShiftMismatchKind = "outOfRange"       // Дата документа вне периода смены ККТ
+	ShiftMismatchNotApplied       ShiftMismatchKind = "notApplied"       // Документ смены не проведён
+	ShiftMismatchPaymentSum       ShiftMismatchKind = "paymentSum"       // Сумма оплат документа не совпадает с его суммой
+)
+
+// ShiftMismatch расхождение при сверке розничной смены.
+type ShiftMismatch struct {
+	Kind     ShiftMismatchKind // Вид расхождения
+	Expected float64           // Ожидаемое значение
+	Actual   float64           // Фактическое значение
+	Document *Meta             // Метаданные документа (для расхождений по документу)
+}
+
+// String реализует интерфейс [fmt.Stringer].
+func (shiftMismatch ShiftMismatch) String() string {
+	if shiftMismatch.Document != nil {
+		return fmt.Sprintf("%s: %s expected %.2f, actual %.2f", shiftMismatch.Kind, shiftMismatch.Document.GetUUIDFromHref(), shiftMismatch.Expected, shiftMismatch.Actual)
+	}
+	return fmt.Sprintf("%s: expected %.2f, actual %.2f", shiftMismatch.Kind, shiftMismatch.Expected, shiftMismatch.Actual)
+}
+
+// ShiftPayments суммы документов смены по видам оплаты (в копейках).
+type ShiftPayments struct {
+	Count  int     // Количество документов
+	Fiscal int     // Количество фискальных документов
+	Sum    float64 // Сумма документов
+	Cash   float64 // Оплачено наличными
+	NoCash float64 // Оплачено картой
+	QR     float64 // Оплачено по QR-коду
+}
+
+// add учитывает документ.
+func (shiftPayments *ShiftPayments) add(sum, cash, noCash, qr float64, fiscal bool) {
+	shiftPayments.Count++
+	if fiscal {
+		shiftPayments.Fiscal++
+	}
+	shiftPayments.Sum += sum
+	shiftPayments.Cash += cash
+	shiftPayments.NoCash += noCash
+	shiftPayments.QR += qr
+}
+
+// RetailShiftReport сверка розничной смены (аналог Z-отчёта).
+//
+// Все суммы указаны в копейках.
+type RetailShiftReport struct {
+	Shift            *RetailShift    // Розничная смена
+	Sales            ShiftPayments   // Проведённые розничные продажи
+	Returns          ShiftPayments   // Проведённые розничные возвраты
+	CashIn           float64         // Сумма внесений денег
+	CashOut          float64         // Сумма выплат денег
+	BankPercent      float64         // Комиссия эквайера по картам (в процентах)
+	QRBankPercent    float64         // Комиссия эквайера по QR-коду (в процентах)
+	BankCommission   float64         // Расчётная комиссия эквайера по картам
+	QRBankCommission float64         // Расчётная комиссия эквайера по QR-коду
+	Mismatches       []ShiftMismatch // Расхождения
+}
+
+// ProceedsCash возвращает выручку наличными: продажи за вычетом возвратов.
+func (retailShiftReport RetailShiftReport) ProceedsCash() float64 {
+	return retailShiftReport.Sales.Cash - retailShiftReport.Returns.Cash
+}
+
+// ProceedsNoCash возвращает безналичную выручку (по картам и QR-коду): продажи за вычетом возвратов.
+func (retailShiftReport RetailShiftReport) ProceedsNoCash() float64 {
+	return retailShiftReport.Sales.NoCash + retailShiftReport.Sales.QR - retailShiftReport.Returns.NoCash - retailShiftReport.Returns.QR
+}
+
+// DrawerCash возвращает изменение наличных в кассе за смену с учётом внесений и выплат.
+func (retailShiftReport RetailShiftReport) DrawerCash() float64 {
+	return retailShiftReport.ProceedsCash() + retailShiftReport.CashIn - retailShiftReport.CashOut
+}
+
+// NetNoCash возвращает безналичную выручку за вычетом комиссий эквайеров.
+func (retailShiftReport RetailShiftReport) NetNoCash() float64 {
+	return retailShiftReport.ProceedsNoCash() - retailShiftReport.BankCommission - retailShiftReport.QRBankCommission
+}
+
+// IsReconciled возвращает true, если расхождения не обнаружены.
+func (retailShiftReport RetailShiftReport) IsReconciled() bool {
+	return len(retailShiftReport.Mismatches) == 0
+}
+
+// mismatch добавляет расхождение сумм, если они различаются больше допустимого.
+func (retailShiftReport *RetailShiftReport) mismatch(kind ShiftMismatchKind, expected, actual float64, document *Meta) {
+	if math.Abs(expected-actual) > retailShiftTolerance {
+		retailShiftReport.flag(kind, expected, actual, document)
+	}
+}
+
+// flag добавляет расхождение.
+func (retailShiftReport *RetailShiftReport) flag(kind ShiftMismatchKind, expected, actual float64, document *Meta) {
+	retailShiftReport.Mismatches = append(retailShiftReport.Mismatches, ShiftMismatch{Kind: kind, Expected: expected, Actual: actual, Document: document})
+}
+
+// retailShiftDocument общие поля розничных документов смены.
+type retailShiftDocument struct {
+	meta                  *Meta
+	applicable            bool
+	fiscal                bool
+	moment                time.Time
+	sum, cash, noCash, qr float64
+	prepay                float64
+}
+
+// RetailShiftReconciler выполняет сверку розничной смены с её документами.
+type RetailShiftReconciler struct {
+	client *Client
+}
+
+// NewRetailShiftReconciler принимает [Client] и возвращает новый объект [RetailShiftReconciler].
+func NewRetailShiftReconciler(client *Client) *RetailShiftReconciler {
+	return &RetailShiftReconciler{client: client}
+}
+
+// Reconcile загружает розничные продажи, возвраты, внесения и выплаты денег смены и выполняет сверку.
+//
+// Проверяются:
+//   - выручка наличными и безналичная выручка смены против сумм документов;
+//   - комиссии эквайеров против процентов смены (или точки продаж, если в смене не указаны);
+//   - количество фискальных чеков против данных ККТ о закрытии смены;
+//   - даты документов против периода смены ККТ;
+//   - проведение документов и соответствие сумм оплат сумме документа.
+func (retailShiftReconciler *RetailShiftReconciler) Reconcile(ctx context.Context, retailShift *RetailShift) (*RetailShiftReport, error) {
+	id := retailShift.GetID()
+	if id == uuid.Nil {
+		id = retailShift.GetMeta().GetUUIDFromHref()
+	}
+
+	shift, _, err := NewRetailShiftService(retailShiftReconciler.client).GetByID(ctx, id, NewParams().WithExpand("retailStore"))
+	if err != nil {
+		return nil, fmt.Errorf("retail shift report: get shift: %w", err)
+	}
+
+	report := &RetailShiftReport{Shift: shift}
+	filter := NewParams().WithFilterEquals("retailShift", shift.GetMeta().GetHref())
+
+	var documents []*retailShiftDocument
+	err = forEachPage[RetailDemand](ctx, retailShiftReconciler.client, EndpointRetailDemand, filter.Clone(), func(rows Slice[RetailDemand]) error {
+		for _, row := range rows {
+			document := &retailShiftDocument{
+				meta: row.Meta, applicable: row.GetApplicable(), fiscal: row.GetFiscal(), moment: row.GetMoment(),
+				sum: row.GetSum(), cash: row.GetCashSum(), noCash: row.GetNoCashSum(), qr: row.GetQRSum(),
+				prepay: row.GetPrepaymentCashSum() + row.GetPrepaymentNoCashSum() + row.GetPrepaymentQRSum(),
+			}
+			if document.applicable {
+				report.Sales.add(document.sum, document.cash, document.noCash, document.qr, document.fiscal)
+			}
+			documents = append(documents, document)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("retail shift report: load retail demands: %w", err)
+	}
+
+	err = forEachPage[RetailSalesReturn](ctx, retailShiftReconciler.client, EndpointRetailSalesReturn, filter.Clone(), func(rows Slice[RetailSalesReturn]) error {
+		for _, row := range rows {
+			document := &retailShiftDocument{
+				meta: row.Meta, applicable: row.GetApplicable(), fiscal: row.GetFiscal(), moment: row.GetMoment(),
+				sum: row.GetSum(), cash: row.GetCashSum(), noCash: row.GetNoCashSum(), qr: row.GetQRSum(),
+			}
+			if document.applicable {
+				report.Returns.add(document.sum, document.cash, document.noCash, document.qr, document.fiscal)
+			}
+			documents = append(documents, document)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("retail shift report: load retail sales returns: %w", err)
+	}
+
+	err = forEachPage[RetailDrawerCashIn](ctx, retailShiftReconciler.client, EndpointRetailDrawerCashIn, filter.Clone(), func(rows Slice[RetailDrawerCashIn]) error {
+		for _, row := range rows {
+			report.CashIn += row.GetSum()
+			if !row.GetApplicable() {
+				report.flag(ShiftMismatchNotApplied, 1, 0, row.Meta)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("retail shift report: load retail drawer cash in: %w", err)
+	}
+
+	err = forEachPage[RetailDrawerCashOut](ctx, retailShiftReconciler.client, EndpointRetailDrawerCashOut, filter.Clone(), func(rows Slice[RetailDrawerCashOut]) error {
+		for _, row := range rows {
+			report.CashOut += row.GetSum()
+			if !row.GetApplicable() {
+				report.flag(ShiftMismatchNotApplied, 1, 0, row.Meta)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("retail shift report: load retail drawer cash out: %w", err)
+	}
+
+	retailShiftReconciler.check(report, documents)
+	return report, nil
+}
+
+// check выполняет проверки смены и документов.
+func (retailShiftReconciler *RetailShiftReconciler) check(report *RetailShiftReport, documents []*retailShiftDocument) {
+	shift := report.Shift
+	cheque := shift.GetCheque()
+	start, end := cheque.Start.Time.Time(), cheque.End.Time.Time()
+
+	for _, document := range documents {
+		if !document.applicable {
+			report.flag(ShiftMismatchNotApplied, 1, 0, document.meta)
+		}
+		report.mismatch(ShiftMismatchPaymentSum, document.sum, document.cash+document.noCash+document.qr+document.prepay, document.meta)
+
+		if document.fiscal && !start.IsZero() && document.moment.Before(start) {
+			report.flag(ShiftMismatchOutOfRange, float64(start.Unix()), float64(document.moment.Unix()), document.meta)
+		}
+		if document.fiscal && !end.IsZero() && document.moment.After(end) {
+			report.flag(ShiftMismatchOutOfRange, float64(end.Unix()), float64(document.moment.Unix()), document.meta)
+		}
+	}
+
+	report.mismatch(ShiftMismatchProceedsCash, report.ProceedsCash(), shift.GetProceedsCash(), nil)
+	report.mismatch(ShiftMismatchProceedsNoCash, report.ProceedsNoCash(), shift.GetProceedsNoCash(), nil)
+
+	// проценты комиссии: из смены или, если не указаны, из точки продаж
+	retailStore := shift.GetRetailStore()
+	report.BankPercent, report.QRBankPercent = shift.GetBankPercent(), shift.GetQRBankPercent()
+	if shift.BankPercent == nil {
+		report.BankPercent = retailStore.GetBankPercent()
+	}
+	if shift.QRBankPercent == nil {
+		report.QRBankPercent = retailStore.GetQRBankPercent()
+	}
+
+	report.BankCommission = math.Round((report.Sales.NoCash - report.Returns.NoCash) * report.BankPercent / 100)
+	report.QRBankCommission = math.Round((report.Sales.QR - report.Returns.QR) * report.QRBankPercent / 100)
+	if shift.BankCommission != nil {
+		report.mismatch(ShiftMismatchBankCommission, report.BankCommission, shift.GetBankCommission(), nil)
+	}
+	if shift.QRBankCommission != nil {
+		report.mismatch(ShiftMismatchQRBankCommission, report.QRBankCommission, shift.GetQRBankCommission(), nil)
+	}
+
+	if total, count := cheque.End.ChequesTotal, float64(report.Sales.Fiscal+report.Returns.Fiscal); total > 0 && total != count {
+		report.flag(ShiftMismatchChequeCount, total, count, nil)
+	}
+}
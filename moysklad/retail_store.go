@@ -1366,6 +1366,11 @@ type RetailStoreService interface {
 	// Возвращает изменённую точку продаж.
 	Update(ctx context.Context, id uuid.UUID, entity *RetailStore, params ...*Params) (*RetailStore, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение точки продаж, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *RetailStore, entity *RetailStore, params ...*Params) (*RetailStore, *resty.Response, error)
+
 	// GetNamedFilterList выполняет запрос на получение списка фильтров.
 	// Принимает контекст и опционально объект параметров запроса Params.
 	// Возвращает объект List.
@@ -1403,6 +1408,7 @@ type retailStoreService struct {
 	endpointDelete[RetailStore]
 	endpointGetByID[RetailStore]
 	endpointUpdate[RetailStore]
+	endpointUpdateSafe[RetailStore]
 	endpointNamedFilter
 }
 
@@ -1429,6 +1435,7 @@ func NewRetailStoreService(client *Client) RetailStoreService {
 		endpointDelete:           endpointDelete[RetailStore]{e},
 		endpointGetByID:          endpointGetByID[RetailStore]{e},
 		endpointUpdate:           endpointUpdate[RetailStore]{e},
+		endpointUpdateSafe:       endpointUpdateSafe[RetailStore]{e},
 		endpointNamedFilter:      endpointNamedFilter{e},
 	}
 }
@@ -0,0 +1,287 @@
+package moysklad
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+// DefaultRetailStoreHealthInterval интервал между проверками точек продаж по умолчанию.
+const DefaultRetailStoreHealthInterval = 15 * time.Minute
+
+// RetailStoreAlertKind тип предупреждения о состоянии точки продаж.
+//
+// Возможные значения:
+//   - RetailStoreAlertNoState              – Точка продаж не передаёт информацию о статусе
+//   - RetailStoreAlertSyncStale            – Давно не было синхронизации
+//   - RetailStoreAlertSyncError            – Ошибка синхронизации
+//   - RetailStoreAlertFiscalMemoryExpiring – Срок действия фискального накопителя истекает
+//   - RetailStoreAlertFiscalMemoryError    – Ошибка фискального накопителя
+//   - RetailStoreAlertOFDBacklog           – Документы не отправляются в ОФД
+type RetailStoreAlertKind string
+
+const (
+	RetailStoreAlertNoState              RetailStoreAlertKind = "nostate"              // Точка продаж не передаёт информацию о статусе
+	RetailStoreAlertSyncStale            RetailStoreAlertKind = "syncstale"            // Давно не было синхронизации
+	RetailStoreAlertSyncError            RetailStoreAlertKind = "syncerror"            // Ошибка синхронизации
+	RetailStoreAlertFiscalMemoryExpiring RetailStoreAlertKind = "fiscalmemoryexpiring" // Срок действия фискального накопителя истекает
+	RetailStoreAlertFiscalMemoryError    RetailStoreAlertKind = "fiscalmemoryerror"    // Ошибка фискального накопителя
+	RetailStoreAlertOFDBacklog           RetailStoreAlertKind = "ofdbacklog"           // Документы не отправляются в ОФД
+)
+
+// RetailStoreAlertSeverity важность предупреждения о состоянии точки продаж.
+type RetailStoreAlertSeverity string
+
+const (
+	RetailStoreAlertWarning  RetailStoreAlertSeverity = "warning"  // Требует внимания
+	RetailStoreAlertCritical RetailStoreAlertSeverity = "critical" // Продажи невозможны или будут невозможны в ближайшее время
+)
+
+// RetailStoreAlert предупреждение о состоянии точки продаж.
+type RetailStoreAlert struct {
+	RetailStore *RetailStore             // Точка продаж
+	Kind        RetailStoreAlertKind     // Тип предупреждения
+	Severity    RetailStoreAlertSeverity // Важность
+	Message     string                   // Описание
+}
+
+// String реализует интерфейс [fmt.Stringer].
+func (retailStoreAlert RetailStoreAlert) String() string {
+	return fmt.Sprintf("[%s] %s: %s", retailStoreAlert.Severity, retailStoreAlert.RetailStore.GetName(), retailStoreAlert.Message)
+}
+
+// key возвращает ключ предупреждения для отслеживания повторов.
+func (retailStoreAlert RetailStoreAlert) key() string {
+	return retailStoreAlert.RetailStore.GetID().String() + "/" + string(retailStoreAlert.Kind) + "/" + string(retailStoreAlert.Severity)
+}
+
+// RetailStoreHealthThresholds пороговые значения проверок точки продаж.
+//
+// Нулевое значение порога отключает соответствующую проверку.
+type RetailStoreHealthThresholds struct {
+	SyncStale                time.Duration // Допустимое время с последней синхронизации
+	FiscalMemoryWarning      time.Duration // Срок до окончания действия ФН для предупреждения
+	FiscalMemoryCritical     time.Duration // Срок до окончания действия ФН для критического предупреждения
+	OFDBacklogCount          int           // Допустимое количество неотправленных в ОФД документов
+	OFDBacklogAge            time.Duration // Допустимый возраст первого неотправленного в ОФД документа
+	OFDBacklogCriticalAge    time.Duration // Возраст первого неотправленного в ОФД документа для критического предупреждения
+	IgnoreStoresWithoutState bool          // Не формировать предупреждение для точек продаж без информации о статусе
+}
+
+// DefaultRetailStoreHealthThresholds пороговые значения проверок точки продаж по умолчанию.
+//
+// ФН блокируется, если документы не передаются в ОФД 30 дней.
+var DefaultRetailStoreHealthThresholds = RetailStoreHealthThresholds{
+	SyncStale:             time.Hour,
+	FiscalMemoryWarning:   30 * 24 * time.Hour,
+	FiscalMemoryCritical:  7 * 24 * time.Hour,
+	OFDBacklogCount:       10,
+	OFDBacklogAge:         24 * time.Hour,
+	OFDBacklogCriticalAge: 25 * 24 * time.Hour,
+}
+
+// RetailStoreHealthMonitor периодически загружает точки продаж, проверяет информацию об их статусе
+// ([RetailStoreState]) и окружении ([Environment]) и формирует предупреждения ([RetailStoreAlert]).
+//
+// Проверяются:
+//   - давность и ошибки синхронизации;
+//   - ошибки фискального накопителя ([FiscalMemoryState.Error]) и срок его действия;
+//   - количество и давность неотправленных в ОФД документов.
+//
+// Ошибки ККТ и платёжного терминала не проверяются: статус точки продаж их не содержит,
+// а [Environment.ChequePrinter], [Environment.PaymentTerminal] и [RetailStore.LastOperationNames]
+// описывают только подключённое оборудование и последние операции.
+//
+// Пороговые значения задаются для всех точек продаж и могут быть переопределены для отдельных точек.
+// Обработчики вызываются только для новых предупреждений: предупреждение, сохраняющееся между проверками,
+// повторно не передаётся, пока не будет устранено.
+type RetailStoreHealthMonitor struct {
+	client     *Client
+	interval   time.Duration
+	thresholds RetailStoreHealthThresholds
+	overrides  map[uuid.UUID]RetailStoreHealthThresholds
+	handlers   []func(alert *RetailStoreAlert)
+	active     map[string]struct{}
+	now        func() time.Time
+	mu         sync.Mutex
+}
+
+// NewRetailStoreHealthMonitor принимает [Client] и возвращает новый объект [RetailStoreHealthMonitor].
+func NewRetailStoreHealthMonitor(client *Client) *RetailStoreHealthMonitor {
+	return &RetailStoreHealthMonitor{
+		client:     client,
+		interval:   DefaultRetailStoreHealthInterval,
+		thresholds: DefaultRetailStoreHealthThresholds,
+		overrides:  make(map[uuid.UUID]RetailStoreHealthThresholds),
+		now:        time.Now,
+	}
+}
+
+// WithInterval устанавливает интервал между проверками.
+func (retailStoreHealthMonitor *RetailStoreHealthMonitor) WithInterval(interval time.Duration) *RetailStoreHealthMonitor {
+	retailStoreHealthMonitor.interval = interval
+	return retailStoreHealthMonitor
+}
+
+// WithThresholds устанавливает пороговые значения для всех точек продаж.
+func (retailStoreHealthMonitor *RetailStoreHealthMonitor) WithThresholds(thresholds RetailStoreHealthThresholds) *RetailStoreHealthMonitor {
+	retailStoreHealthMonitor.thresholds = thresholds
+	return retailStoreHealthMonitor
+}
+
+// WithStoreThresholds устанавливает пороговые значения для точки продаж с указанным ID.
+func (retailStoreHealthMonitor *RetailStoreHealthMonitor) WithStoreThresholds(id uuid.UUID, thresholds RetailStoreHealthThresholds) *RetailStoreHealthMonitor {
+	retailStoreHealthMonitor.overrides[id] = thresholds
+	return retailStoreHealthMonitor
+}
+
+// On добавляет обработчик новых предупреждений.
+func (retailStoreHealthMonitor *RetailStoreHealthMonitor) On(handler func(alert *RetailStoreAlert)) *RetailStoreHealthMonitor {
+	retailStoreHealthMonitor.mu.Lock()
+	defer retailStoreHealthMonitor.mu.Unlock()
+
+	retailStoreHealthMonitor.handlers = append(retailStoreHealthMonitor.handlers, handler)
+	return retailStoreHealthMonitor
+}
+
+// Thresholds возвращает пороговые значения для точки продаж.
+func (retailStoreHealthMonitor *RetailStoreHealthMonitor) Thresholds(retailStore *RetailStore) RetailStoreHealthThresholds {
+	if thresholds, ok := retailStoreHealthMonitor.overrides[retailStore.GetID()]; ok {
+		return thresholds
+	}
+	return retailStoreHealthMonitor.thresholds
+}
+
+// Evaluate проверяет точку продаж и возвращает предупреждения о её состоянии.
+//
+// Архивные точки продаж не проверяются.
+func (retailStoreHealthMonitor *RetailStoreHealthMonitor) Evaluate(retailStore *RetailStore) []*RetailStoreAlert {
+	thresholds := retailStoreHealthMonitor.Thresholds(retailStore)
+	if retailStore.GetArchived() {
+		return nil
+	}
+
+	var alerts []*RetailStoreAlert
+	alert := func(kind RetailStoreAlertKind, severity RetailStoreAlertSeverity, format string, args ...any) {
+		alerts = append(alerts, &RetailStoreAlert{
+			RetailStore: retailStore,
+			Kind:        kind,
+			Severity:    severity,
+			Message:     fmt.Sprintf(format, args...),
+		})
+	}
+
+	if retailStore.State == nil {
+		if !thresholds.IgnoreStoresWithoutState {
+			alert(RetailStoreAlertNoState, RetailStoreAlertWarning, "no state reported")
+		}
+		return alerts
+	}
+
+	now := retailStoreHealthMonitor.now()
+	state := retailStore.GetState()
+
+	// синхронизация
+	lastSync := state.LastCheckMoment.Time()
+	if thresholds.SyncStale > 0 && !lastSync.IsZero() && now.Sub(lastSync) > thresholds.SyncStale {
+		alert(RetailStoreAlertSyncStale, RetailStoreAlertWarning, "last sync at %s", lastSync.Format(time.DateTime))
+	}
+	if state.Sync.Message != "" {
+		alert(RetailStoreAlertSyncError, RetailStoreAlertWarning, "sync: %s", state.Sync.Message)
+	}
+
+	// ошибки ФН
+	if fmError := state.FiscalMemory.Error; fmError.Code != "" || fmError.Message != "" {
+		alert(RetailStoreAlertFiscalMemoryError, RetailStoreAlertCritical, "fiscal memory error %s: %s", fmError.Code, fmError.Message)
+	}
+
+	// срок действия ФН
+	validity := retailStore.GetEnvironment().ChequePrinter.FiscalMemory.FiscalValidityDate.Time()
+	if !validity.IsZero() {
+		left := validity.Sub(now)
+		switch {
+		case thresholds.FiscalMemoryCritical > 0 && left <= thresholds.FiscalMemoryCritical:
+			alert(RetailStoreAlertFiscalMemoryExpiring, RetailStoreAlertCritical, "fiscal memory valid until %s", validity.Format(time.DateOnly))
+		case thresholds.FiscalMemoryWarning > 0 && left <= thresholds.FiscalMemoryWarning:
+			alert(RetailStoreAlertFiscalMemoryExpiring, RetailStoreAlertWarning, "fiscal memory valid until %s", validity.Format(time.DateOnly))
+		}
+	}
+
+	// неотправленные в ОФД документы
+	count := state.FiscalMemory.NotSendDocCount
+	first := state.FiscalMemory.NotSendFirstDocMoment.Time()
+	if count > 0 {
+		age := time.Duration(0)
+		if !first.IsZero() {
+			age = now.Sub(first)
+		}
+		switch {
+		case thresholds.OFDBacklogCriticalAge > 0 && age > thresholds.OFDBacklogCriticalAge:
+			alert(RetailStoreAlertOFDBacklog, RetailStoreAlertCritical, "%d documents not sent to OFD since %s", count, first.Format(time.DateTime))
+		case thresholds.OFDBacklogAge > 0 && age > thresholds.OFDBacklogAge,
+			thresholds.OFDBacklogCount > 0 && count > thresholds.OFDBacklogCount:
+			alert(RetailStoreAlertOFDBacklog, RetailStoreAlertWarning, "%d documents not sent to OFD", count)
+		}
+	}
+	return alerts
+}
+
+// Check выполняет одну проверку: загружает все точки продаж, проверяет их состояние
+// и вызывает обработчики для новых предупреждений.
+//
+// Возвращает все текущие предупреждения.
+func (retailStoreHealthMonitor *RetailStoreHealthMonitor) Check(ctx context.Context) ([]*RetailStoreAlert, error) {
+	var alerts []*RetailStoreAlert
+	err := forEachPage[RetailStore](ctx, retailStoreHealthMonitor.client, EndpointRetailStore, NewParams(), func(rows Slice[RetailStore]) error {
+		for _, retailStore := range rows {
+			alerts = append(alerts, retailStoreHealthMonitor.Evaluate(retailStore)...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("retail store health: load retail stores: %w", err)
+	}
+
+	retailStoreHealthMonitor.mu.Lock()
+	active := make(map[string]struct{}, len(alerts))
+	var raised []*RetailStoreAlert
+	for _, alert := range alerts {
+		key := alert.key()
+		if _, ok := retailStoreHealthMonitor.active[key]; !ok {
+			raised = append(raised, alert)
+		}
+		active[key] = struct{}{}
+	}
+	retailStoreHealthMonitor.active = active
+	handlers := append([]func(alert *RetailStoreAlert){}, retailStoreHealthMonitor.handlers...)
+	retailStoreHealthMonitor.mu.Unlock()
+
+	for _, alert := range raised {
+		for _, handler := range handlers {
+			handler(alert)
+		}
+	}
+	return alerts, nil
+}
+
+// Run выполняет проверки с установленным интервалом до отмены контекста.
+//
+// Ошибки проверок передаются в onError, если он не равен nil, и не прерывают мониторинг.
+func (retailStoreHealthMonitor *RetailStoreHealthMonitor) Run(ctx context.Context, onError func(err error)) error {
+	ticker := time.NewTicker(retailStoreHealthMonitor.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := retailStoreHealthMonitor.Check(ctx); err != nil && onError != nil && ctx.Err() == nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
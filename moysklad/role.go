@@ -353,6 +353,11 @@ type RoleService interface {
 	// Возвращает изменённую пользовательскую роль.
 	Update(ctx context.Context, id uuid.UUID, role *Role, params ...*Params) (*Role, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение пользовательской роли, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Role, role *Role, params ...*Params) (*Role, *resty.Response, error)
+
 	// GetAdminRole выполняет запрос на получение роли администратора.
 	// Принимает контекст.
 	// Возвращает роль администратора.
@@ -390,6 +395,7 @@ type roleService struct {
 	endpointDelete[Role]
 	endpointGetByID[Role]
 	endpointUpdate[Role]
+	endpointUpdateSafe[Role]
 }
 
 func (service *roleService) GetAdminRole(ctx context.Context) (*AdminRole, *resty.Response, error) {
@@ -419,5 +425,6 @@ func NewRoleService(client *Client) RoleService {
 		endpointDelete:     endpointDelete[Role]{e},
 		endpointGetByID:    endpointGetByID[Role]{e},
 		endpointUpdate:     endpointUpdate[Role]{e},
+		endpointUpdateSafe: endpointUpdateSafe[Role]{e},
 	}
 }
@@ -307,6 +307,11 @@ type SalesChannelService interface {
 	// Принимает контекст, канал продаж и опционально объект параметров запроса Params.
 	// Возвращает изменённый канал продаж.
 	Update(ctx context.Context, id uuid.UUID, salesChannel *SalesChannel, params ...*Params) (*SalesChannel, *resty.Response, error)
+
+	// UpdateIfUnchanged выполняет запрос на изменение канала продаж, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *SalesChannel, salesChannel *SalesChannel, params ...*Params) (*SalesChannel, *resty.Response, error)
 }
 
 const (
@@ -488,6 +488,11 @@ type ServiceService interface {
 	// Возвращает изменённую услугу.
 	Update(ctx context.Context, id uuid.UUID, service *Service, params ...*Params) (*Service, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение услуги, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Service, service *Service, params ...*Params) (*Service, *resty.Response, error)
+
 	// GetBySyncID выполняет запрос на получение отдельного документа по syncID.
 	// Принимает контекст и syncID документа.
 	// Возвращает найденный документ.
@@ -0,0 +1,374 @@
+package moysklad
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Table табличные данные: заголовки столбцов и строки.
+type Table struct {
+	Header []string   // Заголовки столбцов (первая строка)
+	Rows   [][]string // Строки данных
+	Lines  []int      // Номера строк данных в исходном файле (начиная с 1)
+}
+
+// Column возвращает индекс столбца с указанным заголовком или -1, если столбец отсутствует.
+//
+// Сравнение заголовков выполняется без учёта регистра и пробелов по краям.
+func (table Table) Column(name string) int {
+	for i, header := range table.Header {
+		if strings.EqualFold(strings.TrimSpace(header), strings.TrimSpace(name)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Value возвращает значение ячейки строки row в столбце column или пустую строку.
+func (table Table) Value(row, column int) string {
+	if row < 0 || row >= len(table.Rows) || column < 0 || column >= len(table.Rows[row]) {
+		return ""
+	}
+	return strings.TrimSpace(table.Rows[row][column])
+}
+
+// RowNumber возвращает номер строки данных row в исходном файле (начиная с 1).
+//
+// Если номера строк неизвестны, считается, что строки данных следуют сразу за строкой заголовков.
+func (table Table) RowNumber(row int) int {
+	if row >= 0 && row < len(table.Lines) {
+		return table.Lines[row]
+	}
+	return row + 2
+}
+
+// newTable возвращает таблицу, первая строка записей которой считается заголовком.
+//
+// lines содержит номера строк записей в исходном файле.
+func newTable(records [][]string, lines []int) *Table {
+	table := &Table{}
+	if len(records) > 0 {
+		table.Header, table.Rows, table.Lines = records[0], records[1:], lines[1:]
+	}
+	return table
+}
+
+// ReadCSV читает таблицу в формате CSV с разделителем comma.
+//
+// Первая строка считается строкой заголовков. Метка порядка байтов UTF-8 в начале файла пропускается.
+// Пустые строки файла пропускаются, номера строк данных сохраняются в поле Lines.
+func ReadCSV(r io.Reader, comma rune) (*Table, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var (
+		records [][]string
+		lines   []int
+	)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		records, lines = append(records, record), append(lines, line)
+	}
+
+	if len(records) > 0 && len(records[0]) > 0 {
+		records[0][0] = strings.TrimPrefix(records[0][0], "\ufeff")
+	}
+	return newTable(records, lines), nil
+}
+
+// RowWriter построчная запись таблицы.
+type RowWriter interface {
+	// WriteRow записывает строку таблицы.
+	WriteRow(row []string) error
+
+	// Close завершает запись таблицы.
+	Close() error
+}
+
+// csvRowWriter построчная запись таблицы в формате CSV.
+type csvRowWriter struct {
+	writer *csv.Writer
+}
+
+// NewCSVRowWriter принимает [io.Writer] и разделитель и возвращает [RowWriter] для записи таблицы в формате CSV.
+func NewCSVRowWriter(w io.Writer, comma rune) RowWriter {
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+	return &csvRowWriter{writer}
+}
+
+func (rowWriter *csvRowWriter) WriteRow(row []string) error {
+	return rowWriter.writer.Write(row)
+}
+
+func (rowWriter *csvRowWriter) Close() error {
+	rowWriter.writer.Flush()
+	return rowWriter.writer.Error()
+}
+
+// Служебные файлы книги XLSX с одним листом.
+var xlsxStaticFiles = []struct{ name, content string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// xlsxRowWriter построчная запись таблицы в книгу XLSX с одним листом.
+type xlsxRowWriter struct {
+	archive *zip.Writer
+	sheet   io.Writer
+	err     error
+}
+
+// NewXLSXRowWriter принимает [io.Writer] и возвращает [RowWriter] для записи таблицы в книгу формата XLSX.
+//
+// Все значения записываются как строки.
+func NewXLSXRowWriter(w io.Writer) RowWriter {
+	rowWriter := &xlsxRowWriter{archive: zip.NewWriter(w)}
+	for _, file := range xlsxStaticFiles {
+		if rowWriter.err = rowWriter.writeFile(file.name, file.content); rowWriter.err != nil {
+			return rowWriter
+		}
+	}
+
+	rowWriter.sheet, rowWriter.err = rowWriter.archive.Create("xl/worksheets/sheet1.xml")
+	if rowWriter.err == nil {
+		_, rowWriter.err = io.WriteString(rowWriter.sheet, xml.Header+
+			`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	}
+	return rowWriter
+}
+
+func (rowWriter *xlsxRowWriter) writeFile(name, content string) error {
+	file, err := rowWriter.archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(file, content)
+	return err
+}
+
+func (rowWriter *xlsxRowWriter) WriteRow(row []string) error {
+	if rowWriter.err != nil {
+		return rowWriter.err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<row>")
+	for _, value := range row {
+		sb.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		if rowWriter.err = xml.EscapeText(&sb, []byte(value)); rowWriter.err != nil {
+			return rowWriter.err
+		}
+		sb.WriteString("</t></is></c>")
+	}
+	sb.WriteString("</row>")
+
+	_, rowWriter.err = io.WriteString(rowWriter.sheet, sb.String())
+	return rowWriter.err
+}
+
+func (rowWriter *xlsxRowWriter) Close() error {
+	if rowWriter.err != nil {
+		return rowWriter.err
+	}
+	if _, err := io.WriteString(rowWriter.sheet, "</sheetData></worksheet>"); err != nil {
+		return err
+	}
+	return rowWriter.archive.Close()
+}
+
+// Элементы XML книги XLSX, необходимые для чтения листов.
+type (
+	xlsxWorkbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	xlsxRelationships struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	xlsxSharedStrings struct {
+		Items []xlsxText `xml:"si"`
+	}
+	xlsxText struct {
+		T    string `xml:"t"`
+		Runs []struct {
+			T string `xml:"t"`
+		} `xml:"r"`
+	}
+	xlsxSheet struct {
+		Rows []struct {
+			Ref   int `xml:"r,attr"`
+			Cells []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Value  string   `xml:"v"`
+				Inline xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+)
+
+// String возвращает текст строки с учётом форматированных фрагментов.
+func (text xlsxText) String() string {
+	if len(text.Runs) == 0 {
+		return text.T
+	}
+	var sb strings.Builder
+	for _, run := range text.Runs {
+		sb.WriteString(run.T)
+	}
+	return sb.String()
+}
+
+// ReadXLSX читает лист с порядковым номером sheet (начиная с 0) книги в формате XLSX.
+//
+// Первая строка считается строкой заголовков.
+// Значения ячеек возвращаются в виде, в котором они хранятся в книге: числа и даты – без форматирования.
+// Пустые строки в книге не хранятся, номера строк данных сохраняются в поле Lines.
+func ReadXLSX(r io.ReaderAt, size int64, sheet int) (*Table, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	var workbook xlsxWorkbook
+	if err = decodeZipXML(files, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if sheet < 0 || sheet >= len(workbook.Sheets) {
+		return nil, fmt.Errorf("xlsx: sheet %d not found", sheet)
+	}
+
+	var rels xlsxRelationships
+	if err = decodeZipXML(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+
+	sheetPath := fmt.Sprintf("xl/worksheets/sheet%d.xml", sheet+1)
+	for _, rel := range rels.Relationships {
+		if rel.ID == workbook.Sheets[sheet].RID {
+			sheetPath = path.Join("xl", strings.TrimPrefix(rel.Target, "/xl/"))
+			break
+		}
+	}
+
+	var shared xlsxSharedStrings
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err = decodeZipXML(files, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var data xlsxSheet
+	if err = decodeZipXML(files, sheetPath, &data); err != nil {
+		return nil, err
+	}
+
+	records := make([][]string, 0, len(data.Rows))
+	lines := make([]int, 0, len(data.Rows))
+	for _, row := range data.Rows {
+		line := row.Ref
+		if line <= 0 {
+			line = len(lines) + 1
+			if len(lines) > 0 {
+				line = lines[len(lines)-1] + 1
+			}
+		}
+
+		var record []string
+		for i, cell := range row.Cells {
+			column := i
+			if cell.Ref != "" {
+				if column = xlsxColumnIndex(cell.Ref); column < 0 {
+					return nil, fmt.Errorf("xlsx: invalid cell reference %q", cell.Ref)
+				}
+			}
+			for len(record) <= column {
+				record = append(record, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				index, _ := strconv.Atoi(cell.Value)
+				if index >= 0 && index < len(shared.Items) {
+					record[column] = shared.Items[index].String()
+				}
+			case "inlineStr":
+				record[column] = cell.Inline.String()
+			case "b":
+				record[column] = strconv.FormatBool(cell.Value == "1")
+			default:
+				record[column] = cell.Value
+			}
+		}
+		records, lines = append(records, record), append(lines, line)
+	}
+	return newTable(records, lines), nil
+}
+
+// decodeZipXML декодирует XML файл архива.
+func decodeZipXML(files map[string]*zip.File, name string, v any) error {
+	file, ok := files[name]
+	if !ok {
+		return fmt.Errorf("xlsx: %s not found", name)
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// xlsxColumnIndex возвращает индекс столбца по ссылке на ячейку (например, «AB12»).
+//
+// Возвращает -1, если ссылка не начинается с буквенного обозначения столбца.
+func xlsxColumnIndex(ref string) int {
+	var index int
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+	}
+	return index - 1
+}
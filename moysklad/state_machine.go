@@ -0,0 +1,521 @@
+package moysklad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-resty/resty/v2"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrStateTransition ошибка перехода документа в статус, не разрешённый машиной состояний.
+	ErrStateTransition = errors.New("state machine: transition is not allowed")
+
+	// ErrStateRequiredField ошибка перехода документа в статус без заполнения обязательных полей.
+	ErrStateRequiredField = errors.New("state machine: required field is empty")
+
+	// ErrStateUnknown ошибка отсутствия статуса с указанным наименованием.
+	ErrStateUnknown = errors.New("state machine: unknown state")
+)
+
+// StateChange переход документа между статусами.
+type StateChange struct {
+	Document Meta           // Метаданные документа
+	From     *State         // Статус до перехода (nil, если статус не был установлен или неизвестен)
+	To       *State         // Статус после перехода
+	Fields   map[string]any // Поля документа после перехода
+}
+
+// String реализует интерфейс [fmt.Stringer].
+func (stateChange StateChange) String() string {
+	return fmt.Sprintf("%s: %q -> %q", stateChange.Document.GetHref(), stateName(stateChange.From), stateName(stateChange.To))
+}
+
+// stateName возвращает наименование статуса или пустую строку, если статус не установлен.
+func stateName(state *State) string {
+	if state == nil {
+		return ""
+	}
+	return state.GetName()
+}
+
+// StateHook действие, выполняемое при переходе документа в статус.
+type StateHook func(ctx context.Context, client *Client, change *StateChange) error
+
+// StateMachine машина состояний документов с указанным кодом сущности.
+//
+// Задаёт разрешённые переходы между статусами (по наименованиям), обязательные для статуса поля
+// и действия, выполняемые при переходе в статус. Если переходы не заданы, разрешены любые переходы.
+//
+// Машина состояний, подключённая к клиенту методом [Client.WithStateMachine], проверяет изменения документов,
+// выполняемые методами Update и UpdateIfUnchanged сервисов (например, [CustomerOrderService]).
+// Без подключения изменения проверяются функцией [StateMachineUpdate].
+// Изменения, выполненные в обход машины состояний (массовым изменением, в интерфейсе МойСклад и т.д.),
+// не блокируются: они обрабатываются по уведомлениям вебхука методом [StateMachine.HandleWebhook].
+type StateMachine struct {
+	client      *Client
+	metaType    MetaType
+	states      map[string]*State
+	names       map[string]string
+	transitions map[string]map[string]bool
+	required    map[string][]string
+	hooks       map[string][]StateHook
+	violations  []func(change *StateChange, err error)
+	last        map[string]string
+	mu          sync.Mutex
+}
+
+// NewStateMachine принимает [Client] и код сущности документа и возвращает новый объект [StateMachine].
+func NewStateMachine(client *Client, metaType MetaType) *StateMachine {
+	return &StateMachine{
+		client:      client,
+		metaType:    metaType,
+		transitions: make(map[string]map[string]bool),
+		required:    make(map[string][]string),
+		hooks:       make(map[string][]StateHook),
+		last:        make(map[string]string),
+	}
+}
+
+// Allow разрешает переходы из статуса from в статусы to.
+//
+// Пустое наименование from означает документ без статуса.
+func (stateMachine *StateMachine) Allow(from string, to ...string) *StateMachine {
+	if stateMachine.transitions[from] == nil {
+		stateMachine.transitions[from] = make(map[string]bool)
+	}
+	for _, name := range to {
+		stateMachine.transitions[from][name] = true
+	}
+	return stateMachine
+}
+
+// Require устанавливает поля, которые должны быть заполнены у документа в статусе state.
+//
+// Поля указываются по наименованиям в JSON API (например, agent, contract, positions);
+// доп. поля указываются в формате attributes.<Наименование доп. поля>.
+func (stateMachine *StateMachine) Require(state string, fields ...string) *StateMachine {
+	stateMachine.required[state] = append(stateMachine.required[state], fields...)
+	return stateMachine
+}
+
+// OnEnter добавляет действие, выполняемое после перехода документа в статус state.
+func (stateMachine *StateMachine) OnEnter(state string, hook StateHook) *StateMachine {
+	stateMachine.hooks[state] = append(stateMachine.hooks[state], hook)
+	return stateMachine
+}
+
+// OnViolation добавляет обработчик переходов, выполненных в обход машины состояний
+// и нарушающих её правила (см. [StateMachine.HandleWebhook]).
+func (stateMachine *StateMachine) OnViolation(handler func(change *StateChange, err error)) *StateMachine {
+	stateMachine.violations = append(stateMachine.violations, handler)
+	return stateMachine
+}
+
+// Load выполняет запрос на получение статусов документов.
+//
+// Вызывается автоматически при первой проверке.
+func (stateMachine *StateMachine) Load(ctx context.Context) error {
+	path := fmt.Sprintf("%s%s/metadata", EndpointEntity, stateMachine.metaType)
+	metadata, _, err := NewRequestBuilder[struct {
+		States Slice[State] `json:"states"`
+	}](stateMachine.client, path).Get(ctx)
+	if err != nil {
+		return fmt.Errorf("state machine: load %s states: %w", stateMachine.metaType, err)
+	}
+
+	states := make(map[string]*State)
+	names := make(map[string]string)
+	for _, state := range metadata.States {
+		states[state.GetName()] = state
+		names[state.GetMeta().GetHref()] = state.GetName()
+	}
+
+	var unknown []string
+	for from, to := range stateMachine.transitions {
+		if _, ok := states[from]; from != "" && !ok {
+			unknown = append(unknown, from)
+		}
+		for name := range to {
+			if _, ok := states[name]; !ok {
+				unknown = append(unknown, name)
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: %s", ErrStateUnknown, strings.Join(unknown, ", "))
+	}
+
+	stateMachine.mu.Lock()
+	stateMachine.states, stateMachine.names = states, names
+	stateMachine.mu.Unlock()
+	return nil
+}
+
+// load загружает статусы документов, если они ещё не загружены.
+func (stateMachine *StateMachine) load(ctx context.Context) error {
+	stateMachine.mu.Lock()
+	loaded := stateMachine.states != nil
+	stateMachine.mu.Unlock()
+
+	if loaded {
+		return nil
+	}
+	return stateMachine.Load(ctx)
+}
+
+// State возвращает статус документа по наименованию.
+func (stateMachine *StateMachine) State(ctx context.Context, name string) (*State, error) {
+	if err := stateMachine.load(ctx); err != nil {
+		return nil, err
+	}
+
+	stateMachine.mu.Lock()
+	defer stateMachine.mu.Unlock()
+
+	state, ok := stateMachine.states[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrStateUnknown, name)
+	}
+	return state, nil
+}
+
+// CanTransition возвращает true, если переход из статуса from в статус to разрешён.
+//
+// Сохранение документа в том же статусе разрешено всегда.
+func (stateMachine *StateMachine) CanTransition(from, to string) bool {
+	if from == to || len(stateMachine.transitions) == 0 {
+		return true
+	}
+	return stateMachine.transitions[from][to]
+}
+
+// stateByHref возвращает статус по ссылке на метаданные или nil.
+func (stateMachine *StateMachine) stateByHref(href string) *State {
+	stateMachine.mu.Lock()
+	defer stateMachine.mu.Unlock()
+
+	if name, ok := stateMachine.names[href]; ok {
+		return stateMachine.states[name]
+	}
+	return nil
+}
+
+// document выполняет запрос на получение полей документа.
+func (stateMachine *StateMachine) document(ctx context.Context, id uuid.UUID) (map[string]any, error) {
+	path := fmt.Sprintf("%s%s/%s", EndpointEntity, stateMachine.metaType, id)
+	document, _, err := NewRequestBuilder[map[string]any](stateMachine.client, path).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("state machine: get %s: %w", stateMachine.metaType, err)
+	}
+	return *document, nil
+}
+
+// Validate проверяет изменение документа с указанным ID: разрешённость перехода в новый статус
+// и заполнение обязательных полей нового статуса с учётом изменяемых полей.
+//
+// Изменяемые доп. поля объединяются с доп. полями документа по ссылке на метаданные:
+// как и при запросе на изменение, доп. поля, не переданные в изменении, сохраняют свои значения.
+//
+// Возвращает переход или nil, если статус документа не изменяется.
+func (stateMachine *StateMachine) Validate(ctx context.Context, id uuid.UUID, update any) (*StateChange, error) {
+	if err := stateMachine.load(ctx); err != nil {
+		return nil, err
+	}
+
+	fields, err := stateMachine.document(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	from := stateMachine.stateByHref(stateHref(fields))
+
+	data, err := json.Marshal(update)
+	if err != nil {
+		return nil, fmt.Errorf("state machine: %w", err)
+	}
+	var changes map[string]any
+	if err = json.Unmarshal(data, &changes); err != nil {
+		return nil, fmt.Errorf("state machine: %w", err)
+	}
+	for key, value := range changes {
+		if key == "attributes" {
+			value = mergeAttributes(fields[key], value)
+		}
+		fields[key] = value
+	}
+
+	to := stateMachine.stateByHref(stateHref(fields))
+	if to == nil || (from != nil && from.GetMeta().GetHref() == to.GetMeta().GetHref()) {
+		return nil, nil
+	}
+
+	change := &StateChange{Document: documentMeta(fields), From: from, To: to, Fields: fields}
+	if err = stateMachine.check(change); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// check проверяет разрешённость перехода и заполнение обязательных полей.
+func (stateMachine *StateMachine) check(change *StateChange) error {
+	from, to := stateName(change.From), stateName(change.To)
+	if !stateMachine.CanTransition(from, to) {
+		return fmt.Errorf("%w: %q -> %q", ErrStateTransition, from, to)
+	}
+	return stateMachine.checkRequired(change)
+}
+
+// checkRequired проверяет заполнение обязательных полей статуса, в который переходит документ.
+func (stateMachine *StateMachine) checkRequired(change *StateChange) error {
+	to := stateName(change.To)
+
+	var empty []string
+	for _, field := range stateMachine.required[to] {
+		if isEmptyField(change.Fields, field) {
+			empty = append(empty, field)
+		}
+	}
+	if len(empty) > 0 {
+		return fmt.Errorf("%w: state %q requires %s", ErrStateRequiredField, to, strings.Join(empty, ", "))
+	}
+	return nil
+}
+
+// Fire выполняет действия, установленные для статуса, в который перешёл документ.
+func (stateMachine *StateMachine) Fire(ctx context.Context, change *StateChange) error {
+	stateMachine.mu.Lock()
+	stateMachine.last[change.Document.GetHref()] = stateName(change.To)
+	stateMachine.mu.Unlock()
+
+	for _, hook := range stateMachine.hooks[stateName(change.To)] {
+		if err := hook(ctx, stateMachine.client, change); err != nil {
+			return fmt.Errorf("state machine: %s: %w", change, err)
+		}
+	}
+	return nil
+}
+
+// HandleWebhook обрабатывает изменения статусов документов из уведомления вебхука.
+//
+// Предыдущий статус документа известен, только если документ уже обрабатывался машиной состояний.
+// Переходы, нарушающие правила, передаются обработчикам [StateMachine.OnViolation];
+// для остальных переходов выполняются действия статуса.
+func (stateMachine *StateMachine) HandleWebhook(ctx context.Context, notification *WebhookNotification) error {
+	if err := stateMachine.load(ctx); err != nil {
+		return err
+	}
+
+	for _, event := range notification.Events {
+		if event.Meta.GetType() != stateMachine.metaType || event.Action == WebhookActionDelete {
+			continue
+		}
+		if event.Action == WebhookActionUpdate && len(event.UpdatedFields) > 0 && !slices.Contains(event.UpdatedFields.UnPtr(), "state") {
+			continue
+		}
+
+		fields, err := stateMachine.document(ctx, event.Meta.GetUUIDFromHref())
+		if err != nil {
+			return err
+		}
+		to := stateMachine.stateByHref(stateHref(fields))
+		if to == nil {
+			continue
+		}
+
+		stateMachine.mu.Lock()
+		previous, known := stateMachine.last[event.Meta.GetHref()]
+		from := stateMachine.states[previous]
+		stateMachine.mu.Unlock()
+
+		if known && previous == to.GetName() {
+			continue
+		}
+
+		// созданный документ не имел статуса; для остальных документов без истории
+		// разрешённость перехода проверить нельзя
+		known = known || event.Action == WebhookActionCreate
+		change := &StateChange{Document: documentMeta(fields), From: from, To: to, Fields: fields}
+		if known {
+			err = stateMachine.check(change)
+		} else {
+			err = stateMachine.checkRequired(change)
+		}
+
+		if err != nil {
+			stateMachine.mu.Lock()
+			stateMachine.last[event.Meta.GetHref()] = to.GetName()
+			stateMachine.mu.Unlock()
+
+			for _, handler := range stateMachine.violations {
+				handler(change, err)
+			}
+			continue
+		}
+
+		if err = stateMachine.Fire(ctx, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StateMachineUpdate проверяет изменение документа машиной состояний, выполняет запрос на изменение документа
+// и выполняет действия статуса, в который перешёл документ.
+//
+// Функция позволяет проверить изменение машиной состояний, не подключённой к клиенту ([Client.WithStateMachine]).
+func StateMachineUpdate[T any](ctx context.Context, stateMachine *StateMachine, id uuid.UUID, entity *T, params ...*Params) (*T, error) {
+	updated, _, err := stateMachineUpdate(ctx, stateMachine, stateMachine.client, id, entity, params...)
+	return updated, err
+}
+
+// stateMachineUpdate проверяет изменение документа машиной состояний и выполняет запрос на изменение клиентом client.
+func stateMachineUpdate[T any](ctx context.Context, stateMachine *StateMachine, client *Client, id uuid.UUID, entity *T, params ...*Params) (*T, *resty.Response, error) {
+	change, err := stateMachine.Validate(ctx, id, entity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	path := fmt.Sprintf("%s%s/%s", EndpointEntity, stateMachine.metaType, id)
+	updated, resp, err := NewRequestBuilder[T](client, path).SetParams(params...).Put(ctx, entity)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	if change != nil {
+		if err = stateMachine.Fire(ctx, change); err != nil {
+			return updated, resp, err
+		}
+	}
+	return updated, resp, nil
+}
+
+// CreateDemandOnEnter действие статуса заказа покупателя: создаёт отгрузку на основании заказа,
+// если у заказа ещё нет связанных отгрузок.
+func CreateDemandOnEnter(ctx context.Context, client *Client, change *StateChange) error {
+	if demands, ok := change.Fields["demands"].([]any); ok && len(demands) > 0 {
+		return nil
+	}
+
+	service := NewDemandService(client)
+	demand, _, err := service.TemplateBased(ctx, MetaWrapper{Meta: change.Document})
+	if err != nil {
+		return fmt.Errorf("demand template: %w", err)
+	}
+	if _, _, err = service.Create(ctx, demand); err != nil {
+		return fmt.Errorf("create demand: %w", err)
+	}
+	return nil
+}
+
+// stateHref возвращает ссылку на метаданные статуса документа.
+func stateHref(fields map[string]any) string {
+	state, _ := fields["state"].(map[string]any)
+	meta, _ := state["meta"].(map[string]any)
+	href, _ := meta["href"].(string)
+	return href
+}
+
+// mergeAttributes возвращает доп. поля документа current, дополненные и изменённые доп. полями update.
+//
+// Доп. поля сопоставляются по ссылке на метаданные (или по ID, если метаданные не указаны).
+func mergeAttributes(current, update any) any {
+	currentAttributes, _ := current.([]any)
+	updateAttributes, ok := update.([]any)
+	if !ok {
+		return update
+	}
+
+	attributeKey := func(attribute map[string]any) string {
+		meta, _ := attribute["meta"].(map[string]any)
+		if href, _ := meta["href"].(string); href != "" {
+			return href
+		}
+		id, _ := attribute["id"].(string)
+		return id
+	}
+
+	merged := make([]any, 0, len(currentAttributes)+len(updateAttributes))
+	index := make(map[string]int)
+	for _, attribute := range currentAttributes {
+		if values, ok := attribute.(map[string]any); ok {
+			if key := attributeKey(values); key != "" {
+				index[key] = len(merged)
+			}
+		}
+		merged = append(merged, attribute)
+	}
+
+	for _, attribute := range updateAttributes {
+		values, ok := attribute.(map[string]any)
+		if !ok {
+			continue
+		}
+		i, found := index[attributeKey(values)]
+		if !found {
+			merged = append(merged, values)
+			continue
+		}
+		existing, _ := merged[i].(map[string]any)
+		combined := make(map[string]any, len(existing)+len(values))
+		for key, value := range existing {
+			combined[key] = value
+		}
+		for key, value := range values {
+			combined[key] = value
+		}
+		merged[i] = combined
+	}
+	return merged
+}
+
+// documentMeta возвращает метаданные документа.
+func documentMeta(fields map[string]any) Meta {
+	var meta Meta
+	if data, err := json.Marshal(fields["meta"]); err == nil {
+		_ = json.Unmarshal(data, &meta)
+	}
+	return meta
+}
+
+// isEmptyField возвращает true, если поле документа не заполнено.
+func isEmptyField(fields map[string]any, field string) bool {
+	if name, ok := strings.CutPrefix(field, "attributes."); ok {
+		attributes, _ := fields["attributes"].([]any)
+		for _, attribute := range attributes {
+			if values, ok := attribute.(map[string]any); ok && values["name"] == name {
+				return isEmptyValue(values["value"])
+			}
+		}
+		return true
+	}
+	return isEmptyValue(fields[field])
+}
+
+// isEmptyValue возвращает true, если значение отсутствует, пустое или является пустой коллекцией.
+func isEmptyValue(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		if rows, ok := v["rows"].([]any); ok {
+			return len(rows) == 0
+		}
+		// коллекция без раскрытия
+		if meta, ok := v["meta"].(map[string]any); ok {
+			if size, ok := meta["size"].(float64); ok {
+				return size == 0
+			}
+		}
+		return len(v) == 0
+	}
+	return false
+}
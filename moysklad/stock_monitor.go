@@ -0,0 +1,284 @@
+package moysklad
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultStockMonitorInterval интервал между снимками остатков по умолчанию.
+const DefaultStockMonitorInterval = 5 * time.Minute
+
+// StockKey ключ остатка: позиция ассортимента и склад.
+//
+// При мониторинге без разбиения по складам StoreID пуст.
+type StockKey struct {
+	AssortmentID string // ID Товара/Модификации/Серии
+	StoreID      string // ID склада
+}
+
+// StockSnapshot снимок текущих остатков.
+type StockSnapshot struct {
+	Moment time.Time            // Момент получения снимка
+	Stocks map[StockKey]float64 // Физические остатки
+}
+
+// StockDelta изменение остатка между двумя снимками.
+type StockDelta struct {
+	StockKey
+	Previous float64 // Остаток в предыдущем снимке
+	Current  float64 // Остаток в текущем снимке
+}
+
+// Delta возвращает разницу между текущим и предыдущим остатком.
+func (stockDelta StockDelta) Delta() float64 {
+	return stockDelta.Current - stockDelta.Previous
+}
+
+// Diff возвращает изменения остатков относительно предыдущего снимка.
+//
+// Позиции, отсутствующие в одном из снимков, считаются имеющими нулевой остаток.
+func (stockSnapshot *StockSnapshot) Diff(previous *StockSnapshot) []StockDelta {
+	var deltas []StockDelta
+	if previous == nil {
+		previous = &StockSnapshot{}
+	}
+
+	for key, current := range stockSnapshot.Stocks {
+		if prev := previous.Stocks[key]; prev != current {
+			deltas = append(deltas, StockDelta{key, prev, current})
+		}
+	}
+
+	for key, prev := range previous.Stocks {
+		if _, ok := stockSnapshot.Stocks[key]; !ok && prev != 0 {
+			deltas = append(deltas, StockDelta{key, prev, 0})
+		}
+	}
+	return deltas
+}
+
+// StockEventType тип события мониторинга остатков.
+//
+// Возможные значения:
+//   - StockEventChanged    – Остаток изменился
+//   - StockEventLowStock   – Остаток опустился ниже неснижаемого
+//   - StockEventOutOfStock – Остаток закончился
+//   - StockEventRestocked  – Остаток восстановлен до неснижаемого или выше
+type StockEventType string
+
+const (
+	StockEventChanged    StockEventType = "changed"    // Остаток изменился
+	StockEventLowStock   StockEventType = "lowstock"   // Остаток опустился ниже неснижаемого
+	StockEventOutOfStock StockEventType = "outofstock" // Остаток закончился
+	StockEventRestocked  StockEventType = "restocked"  // Остаток восстановлен до неснижаемого или выше
+)
+
+// StockEvent событие мониторинга остатков.
+type StockEvent struct {
+	Type           StockEventType // Тип события
+	StockDelta                    // Изменение остатка
+	MinimumBalance float64        // Неснижаемый остаток товара
+}
+
+// String реализует интерфейс [fmt.Stringer].
+func (stockEvent StockEvent) String() string {
+	return Stringify(stockEvent)
+}
+
+// StockMonitor периодически получает снимки текущих остатков, вычисляет изменения
+// и сопоставляет их с неснижаемым остатком товаров ([Product.MinimumBalance]).
+//
+// Для каждого изменения вызываются обработчики события [StockEventChanged],
+// а при пересечении неснижаемого остатка – обработчики событий
+// [StockEventLowStock], [StockEventOutOfStock] и [StockEventRestocked].
+//
+// Неснижаемый остаток модификаций и серий не учитывается.
+// При мониторинге с разбиением по складам неснижаемый остаток сравнивается с остатком на каждом складе.
+type StockMonitor struct {
+	client          *Client
+	interval        time.Duration
+	byStore         bool
+	handlers        map[StockEventType][]func(event *StockEvent)
+	minimumBalances map[string]float64
+	previous        *StockSnapshot
+	mu              sync.Mutex
+}
+
+// NewStockMonitor принимает [Client] и возвращает новый объект [StockMonitor].
+func NewStockMonitor(client *Client) *StockMonitor {
+	return &StockMonitor{
+		client:   client,
+		interval: DefaultStockMonitorInterval,
+		handlers: make(map[StockEventType][]func(event *StockEvent)),
+	}
+}
+
+// WithInterval устанавливает интервал между снимками остатков.
+func (stockMonitor *StockMonitor) WithInterval(interval time.Duration) *StockMonitor {
+	stockMonitor.interval = interval
+	return stockMonitor
+}
+
+// WithStores включает получение остатков с разбиением по складам.
+func (stockMonitor *StockMonitor) WithStores() *StockMonitor {
+	stockMonitor.byStore = true
+	return stockMonitor
+}
+
+// On добавляет обработчик событий указанного типа.
+func (stockMonitor *StockMonitor) On(eventType StockEventType, handler func(event *StockEvent)) *StockMonitor {
+	stockMonitor.mu.Lock()
+	defer stockMonitor.mu.Unlock()
+
+	stockMonitor.handlers[eventType] = append(stockMonitor.handlers[eventType], handler)
+	return stockMonitor
+}
+
+// Snapshot выполняет запрос на получение текущих остатков и возвращает их снимок.
+func (stockMonitor *StockMonitor) Snapshot(ctx context.Context) (*StockSnapshot, error) {
+	snapshot := &StockSnapshot{Moment: time.Now(), Stocks: make(map[StockKey]float64)}
+
+	service := NewReportStockService(stockMonitor.client)
+	if stockMonitor.byStore {
+		stocks, _, err := service.GetCurrentByStore(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, stock := range Deref(stocks) {
+			snapshot.Stocks[StockKey{stock.AssortmentID, stock.StoreID}] = stock.Stock
+		}
+		return snapshot, nil
+	}
+
+	stocks, _, err := service.GetCurrentAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, stock := range Deref(stocks) {
+		snapshot.Stocks[StockKey{AssortmentID: stock.AssortmentID}] = stock.Stock
+	}
+	return snapshot, nil
+}
+
+// RefreshMinimumBalances выполняет загрузку неснижаемых остатков товаров.
+//
+// Вызывается автоматически при первой проверке.
+func (stockMonitor *StockMonitor) RefreshMinimumBalances(ctx context.Context) error {
+	minimumBalances := make(map[string]float64)
+	err := forEachPage[Product](ctx, stockMonitor.client, EndpointProduct, NewParams(), func(products Slice[Product]) error {
+		for _, product := range products {
+			if product.ID != nil && product.GetMinimumBalance() > 0 {
+				minimumBalances[product.ID.String()] = product.GetMinimumBalance()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	stockMonitor.mu.Lock()
+	stockMonitor.minimumBalances = minimumBalances
+	stockMonitor.mu.Unlock()
+	return nil
+}
+
+// Check выполняет одну проверку: получает снимок остатков, сравнивает его с предыдущим
+// и вызывает обработчики событий.
+//
+// При первой проверке предыдущий снимок отсутствует, поэтому события формируются
+// для всех позиций с ненулевым остатком, а также для позиций с остатком ниже неснижаемого.
+func (stockMonitor *StockMonitor) Check(ctx context.Context) ([]*StockEvent, error) {
+	stockMonitor.mu.Lock()
+	loaded := stockMonitor.minimumBalances != nil
+	stockMonitor.mu.Unlock()
+
+	if !loaded {
+		if err := stockMonitor.RefreshMinimumBalances(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	snapshot, err := stockMonitor.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stockMonitor.mu.Lock()
+	previous := stockMonitor.previous
+	stockMonitor.previous = snapshot
+	events := stockMonitor.events(snapshot, previous)
+	handlers := make(map[StockEventType][]func(event *StockEvent), len(stockMonitor.handlers))
+	for eventType, fns := range stockMonitor.handlers {
+		handlers[eventType] = append([]func(event *StockEvent){}, fns...)
+	}
+	stockMonitor.mu.Unlock()
+
+	for _, event := range events {
+		for _, handler := range handlers[event.Type] {
+			handler(event)
+		}
+	}
+	return events, nil
+}
+
+// events формирует события по изменениям остатков.
+func (stockMonitor *StockMonitor) events(snapshot, previous *StockSnapshot) []*StockEvent {
+	var events []*StockEvent
+	deltas := snapshot.Diff(previous)
+
+	// товары с неснижаемым остатком, отсутствующие в отчёте при первой проверке
+	if previous == nil && !stockMonitor.byStore {
+		for id := range stockMonitor.minimumBalances {
+			key := StockKey{AssortmentID: id}
+			if _, ok := snapshot.Stocks[key]; !ok {
+				deltas = append(deltas, StockDelta{key, 0, 0})
+			}
+		}
+	}
+
+	for _, delta := range deltas {
+		minimum := stockMonitor.minimumBalances[delta.AssortmentID]
+		if delta.Previous != delta.Current {
+			events = append(events, &StockEvent{StockEventChanged, delta, minimum})
+		}
+
+		if minimum <= 0 {
+			continue
+		}
+
+		wasLow := previous != nil && delta.Previous < minimum
+		wasOut := previous != nil && delta.Previous <= 0
+
+		switch {
+		case delta.Current <= 0 && !wasOut:
+			events = append(events, &StockEvent{StockEventOutOfStock, delta, minimum})
+		case delta.Current > 0 && delta.Current < minimum && (!wasLow || wasOut):
+			events = append(events, &StockEvent{StockEventLowStock, delta, minimum})
+		case delta.Current >= minimum && wasLow:
+			events = append(events, &StockEvent{StockEventRestocked, delta, minimum})
+		}
+	}
+	return events
+}
+
+// Run выполняет проверки с установленным интервалом до отмены контекста.
+//
+// Ошибки проверок передаются в onError, если он не равен nil, и не прерывают мониторинг.
+func (stockMonitor *StockMonitor) Run(ctx context.Context, onError func(err error)) error {
+	ticker := time.NewTicker(stockMonitor.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := stockMonitor.Check(ctx); err != nil && onError != nil && ctx.Err() == nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -521,6 +521,11 @@ type StoreService interface {
 	// Возвращает изменённый склад.
 	Update(ctx context.Context, id uuid.UUID, store *Store, params ...*Params) (*Store, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение склада, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Store, store *Store, params ...*Params) (*Store, *resty.Response, error)
+
 	// GetNamedFilterList выполняет запрос на получение списка фильтров.
 	// Принимает контекст и опционально объект параметров запроса Params.
 	// Возвращает объект List.
@@ -630,6 +635,7 @@ type storeService struct {
 	endpointAttributes
 	endpointGetByID[Store]
 	endpointUpdate[Store]
+	endpointUpdateSafe[Store]
 	endpointNamedFilter
 }
 
@@ -718,6 +724,7 @@ func NewStoreService(client *Client) StoreService {
 		endpointAttributes:       endpointAttributes{e},
 		endpointGetByID:          endpointGetByID[Store]{e},
 		endpointUpdate:           endpointUpdate[Store]{e},
+		endpointUpdateSafe:       endpointUpdateSafe[Store]{e},
 		endpointNamedFilter:      endpointNamedFilter{e},
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/go-resty/resty/v2"
 	"github.com/google/uuid"
+	"io"
 	"time"
 )
 
@@ -782,6 +783,21 @@ func (supplyPosition *SupplyPosition) SetVatEnabled(vatEnabled bool) *SupplyPosi
 	return supplyPosition
 }
 
+// SetBaseQuantity устанавливает упаковку, количество и цену позиции по количеству baseQuantity в базовых единицах
+// и цене basePrice за базовую единицу (в копейках).
+//
+// Количество и цена пересчитываются в упаковки pack с помощью [QuantityConverter];
+// если pack равен nil, количество и цена устанавливаются без изменений.
+// Возвращает ошибку, если количество не допускается единицей измерения товара или упаковки.
+func (supplyPosition *SupplyPosition) SetBaseQuantity(converter *QuantityConverter, baseQuantity, basePrice float64, pack *Pack) error {
+	quantity, price, err := converter.packedPosition(baseQuantity, basePrice, pack)
+	if err != nil {
+		return err
+	}
+	supplyPosition.SetPack(pack).SetQuantity(quantity).SetPrice(price)
+	return nil
+}
+
 // String реализует интерфейс [fmt.Stringer].
 func (supplyPosition SupplyPosition) String() string {
 	return Stringify(supplyPosition)
@@ -839,6 +855,11 @@ type SupplyService interface {
 	// Возвращает изменённую приемку.
 	Update(ctx context.Context, id uuid.UUID, supply *Supply, params ...*Params) (*Supply, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение приемки, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Supply, supply *Supply, params ...*Params) (*Supply, *resty.Response, error)
+
 	// Template выполняет запрос на получение предзаполненной приемки со стандартными полями без связи с какими-либо другими документами.
 	// Принимает контекст.
 	// Возвращает предзаполненную приемку.
@@ -968,6 +989,16 @@ type SupplyService interface {
 	// Возвращает файл
 	PrintDocument(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg) (*PrintFile, *resty.Response, error)
 
+	// PrintDocumentTo выполняет запрос на печать отдельного документа по шаблону печатной формы.
+	// Принимает контекст, ID документа, объект PrintDocumentArg и io.Writer, в который записывается файл без полной буферизации.
+	// Возвращает название файла.
+	PrintDocumentTo(ctx context.Context, id uuid.UUID, PrintDocumentArg *PrintDocumentArg, w io.Writer) (string, *resty.Response, error)
+
+	// PrintDocumentMany выполняет асинхронный запрос на массовую печать документов.
+	// Принимает контекст, объект MassPrintArg и io.Writer, в который записывается полученный файл.
+	// Возвращает объект MassPrintResult, содержащий название файла и ошибки печати отдельных документов.
+	PrintDocumentMany(ctx context.Context, massPrintArg *MassPrintArg, w io.Writer) (*MassPrintResult, *resty.Response, error)
+
 	// GetFileList выполняет запрос на получение файлов в виде списка.
 	// Принимает контекст и ID сущности/документа.
 	// Возвращает объект List.
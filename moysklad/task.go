@@ -875,6 +875,11 @@ type TaskService interface {
 	// Возвращает изменённую задачу.
 	Update(ctx context.Context, id uuid.UUID, task *Task, params ...*Params) (*Task, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение задачи, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Task, task *Task, params ...*Params) (*Task, *resty.Response, error)
+
 	// GetNamedFilterList выполняет запрос на получение списка фильтров.
 	// Принимает контекст и опционально объект параметров запроса Params.
 	// Возвращает объект List.
@@ -959,6 +964,7 @@ type taskService struct {
 	endpointDelete[Task]
 	endpointGetByID[Task]
 	endpointUpdate[Task]
+	endpointUpdateSafe[Task]
 	endpointNamedFilter
 	endpointFiles
 }
@@ -1010,6 +1016,7 @@ func NewTaskService(client *Client) TaskService {
 		endpointDelete:           endpointDelete[Task]{e},
 		endpointGetByID:          endpointGetByID[Task]{e},
 		endpointUpdate:           endpointUpdate[Task]{e},
+		endpointUpdateSafe:       endpointUpdateSafe[Task]{e},
 		endpointNamedFilter:      endpointNamedFilter{e},
 		endpointFiles:            endpointFiles{e},
 	}
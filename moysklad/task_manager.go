@@ -0,0 +1,341 @@
+package moysklad
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TaskAssigner выбирает ответственного за задачу.
+//
+// Возвращает nil, если ответственный не может быть выбран.
+type TaskAssigner interface {
+	Assign(ctx context.Context, task *Task) (*Employee, error)
+}
+
+// TaskAssignerFunc функция, реализующая интерфейс [TaskAssigner].
+type TaskAssignerFunc func(ctx context.Context, task *Task) (*Employee, error)
+
+// Assign реализует интерфейс [TaskAssigner].
+func (taskAssignerFunc TaskAssignerFunc) Assign(ctx context.Context, task *Task) (*Employee, error) {
+	return taskAssignerFunc(ctx, task)
+}
+
+// AssignToEmployee возвращает [TaskAssigner], назначающий задачи указанному сотруднику.
+func AssignToEmployee(employee *Employee) TaskAssigner {
+	return TaskAssignerFunc(func(context.Context, *Task) (*Employee, error) {
+		return employee, nil
+	})
+}
+
+// AssignToFirst возвращает [TaskAssigner], назначающий задачи по первому правилу, выбравшему ответственного.
+func AssignToFirst(assigners ...TaskAssigner) TaskAssigner {
+	return TaskAssignerFunc(func(ctx context.Context, task *Task) (*Employee, error) {
+		for _, assigner := range assigners {
+			employee, err := assigner.Assign(ctx, task)
+			if err != nil || employee != nil {
+				return employee, err
+			}
+		}
+		return nil, nil
+	})
+}
+
+// ownedEntity поля контрагента или документа, необходимые для выбора ответственного.
+type ownedEntity struct {
+	Owner *Employee `json:"owner"`
+	Agent *Agent    `json:"agent"`
+}
+
+// AssignToAgentOwner возвращает [TaskAssigner], назначающий задачи владельцу контрагента,
+// связанного с задачей. Для задачи, связанной с документом, используется владелец контрагента документа.
+func AssignToAgentOwner(client *Client) TaskAssigner {
+	return TaskAssignerFunc(func(ctx context.Context, task *Task) (*Employee, error) {
+		agent := task.Agent
+		if agent == nil && task.Operation != nil {
+			operation, _, err := FetchMeta[ownedEntity](ctx, client, task.Operation.GetMeta())
+			if err != nil {
+				return nil, fmt.Errorf("task manager: get operation: %w", err)
+			}
+			agent = operation.Agent
+		}
+		if agent == nil {
+			return nil, nil
+		}
+
+		owned, _, err := FetchMeta[ownedEntity](ctx, client, agent.GetMeta())
+		if err != nil {
+			return nil, fmt.Errorf("task manager: get agent: %w", err)
+		}
+		return owned.Owner, nil
+	})
+}
+
+// RoundRobinAssigner назначает задачи сотрудникам по очереди.
+type RoundRobinAssigner struct {
+	employees Slice[Employee]
+	next      int
+	mu        sync.Mutex
+}
+
+// NewRoundRobinAssigner принимает сотрудников и возвращает новый объект [RoundRobinAssigner].
+func NewRoundRobinAssigner(employees ...*Employee) *RoundRobinAssigner {
+	return &RoundRobinAssigner{employees: employees}
+}
+
+// NewGroupRoundRobinAssigner загружает неархивных сотрудников отдела с доступом к сервису
+// и возвращает новый объект [RoundRobinAssigner].
+//
+// Сотрудники упорядочиваются по наименованию.
+func NewGroupRoundRobinAssigner(ctx context.Context, client *Client, group *Group) (*RoundRobinAssigner, error) {
+	var members Slice[Employee]
+	href := group.GetMeta().GetHref()
+	err := forEachPage[Employee](ctx, client, EndpointEmployee, NewParams(), func(rows Slice[Employee]) error {
+		for _, employee := range rows {
+			if !employee.GetArchived() && employee.GetGroup().GetMeta().GetHref() == href {
+				members.Push(employee)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("task manager: load employees: %w", err)
+	}
+
+	// задачи назначаются только сотрудникам с доступом к сервису
+	var employees Slice[Employee]
+	employeeService := NewEmployeeService(client)
+	for _, employee := range members {
+		permission, _, err := employeeService.GetPermissions(ctx, employee.GetID())
+		if err != nil {
+			return nil, fmt.Errorf("task manager: load permissions of %q: %w", employee.GetName(), err)
+		}
+		if permission.GetActive() {
+			employees.Push(employee)
+		}
+	}
+
+	sort.Slice(employees, func(i, j int) bool { return employees[i].GetName() < employees[j].GetName() })
+	return NewRoundRobinAssigner(employees...), nil
+}
+
+// Assign реализует интерфейс [TaskAssigner].
+func (roundRobinAssigner *RoundRobinAssigner) Assign(context.Context, *Task) (*Employee, error) {
+	roundRobinAssigner.mu.Lock()
+	defer roundRobinAssigner.mu.Unlock()
+
+	if len(roundRobinAssigner.employees) == 0 {
+		return nil, nil
+	}
+	employee := roundRobinAssigner.employees[roundRobinAssigner.next%len(roundRobinAssigner.employees)]
+	roundRobinAssigner.next++
+	return employee, nil
+}
+
+// TaskAgenda невыполненные задачи сотрудника с истёкшим сроком и сроком на сегодня.
+type TaskAgenda struct {
+	Assignee Employee    // Ответственный
+	Overdue  Slice[Task] // Просроченные задачи
+	DueToday Slice[Task] // Задачи со сроком на сегодня
+}
+
+// TaskManager создаёт задачи, связанные с контрагентами и документами, назначает ответственных по правилам,
+// формирует списки просроченных задач и задач на сегодня и закрывает задачи,
+// когда связанный документ переходит в указанный статус.
+type TaskManager struct {
+	client     *Client
+	assigner   TaskAssigner
+	closeRules map[MetaType][]string
+	now        func() time.Time
+}
+
+// NewTaskManager принимает [Client] и возвращает новый объект [TaskManager].
+func NewTaskManager(client *Client) *TaskManager {
+	return &TaskManager{
+		client:     client,
+		closeRules: make(map[MetaType][]string),
+		now:        time.Now,
+	}
+}
+
+// WithAssigner устанавливает правило выбора ответственного для задач без ответственного.
+func (taskManager *TaskManager) WithAssigner(assigner TaskAssigner) *TaskManager {
+	taskManager.assigner = assigner
+	return taskManager
+}
+
+// WithCloseOnState добавляет правило: невыполненные задачи, связанные с документом с кодом сущности metaType,
+// закрываются, когда документ переходит в один из указанных статусов.
+func (taskManager *TaskManager) WithCloseOnState(metaType MetaType, states ...*State) *TaskManager {
+	for _, state := range states {
+		taskManager.closeRules[metaType] = append(taskManager.closeRules[metaType], state.GetMeta().GetHref())
+	}
+	return taskManager
+}
+
+// Create назначает ответственного (если он не указан) и выполняет запрос на создание задачи.
+func (taskManager *TaskManager) Create(ctx context.Context, task *Task) (*Task, error) {
+	if task.Assignee == nil && taskManager.assigner != nil {
+		assignee, err := taskManager.assigner.Assign(ctx, task)
+		if err != nil {
+			return nil, err
+		}
+		task.SetAssignee(assignee)
+	}
+	if task.Assignee == nil {
+		return nil, fmt.Errorf("task manager: assignee is required")
+	}
+
+	created, _, err := NewTaskService(taskManager.client).Create(ctx, task)
+	if err != nil {
+		return nil, fmt.Errorf("task manager: create task: %w", err)
+	}
+	return created, nil
+}
+
+// CreateForAgent создаёт задачу, связанную с контрагентом или юрлицом.
+func (taskManager *TaskManager) CreateForAgent(ctx context.Context, agent AgentOrganizationConverter, description string, dueToDate time.Time) (*Task, error) {
+	task := new(Task).SetAgent(agent).SetDescription(description)
+	if !dueToDate.IsZero() {
+		task.SetDueToDate(dueToDate)
+	}
+	return taskManager.Create(ctx, task)
+}
+
+// CreateForOperation создаёт задачу, связанную с документом.
+func (taskManager *TaskManager) CreateForOperation(ctx context.Context, operation TaskOperationConverter, description string, dueToDate time.Time) (*Task, error) {
+	task := new(Task).SetOperation(operation).SetDescription(description)
+	if !dueToDate.IsZero() {
+		task.SetDueToDate(dueToDate)
+	}
+	return taskManager.Create(ctx, task)
+}
+
+// Agenda возвращает невыполненные задачи с истёкшим сроком и сроком на сегодня по ответственным.
+func (taskManager *TaskManager) Agenda(ctx context.Context) (map[uuid.UUID]*TaskAgenda, error) {
+	now := taskManager.now()
+	year, month, day := now.Date()
+	tomorrow := time.Date(year, month, day+1, 0, 0, 0, 0, now.Location())
+
+	params := NewParams().
+		WithFilterEquals("done", "false").
+		WithFilterLesser("dueToDate", tomorrow.Format(time.DateTime))
+
+	agendas := make(map[uuid.UUID]*TaskAgenda)
+	err := forEachPage[Task](ctx, taskManager.client, EndpointTask, params, func(rows Slice[Task]) error {
+		for _, task := range rows {
+			if task.DueToDate == nil {
+				continue
+			}
+
+			assignee := task.GetAssignee()
+			id := assignee.GetMeta().GetUUIDFromHref()
+			agenda, ok := agendas[id]
+			if !ok {
+				agenda = &TaskAgenda{Assignee: assignee}
+				agendas[id] = agenda
+			}
+
+			if task.GetDueToDate().Before(now) {
+				agenda.Overdue.Push(task)
+			} else {
+				agenda.DueToday.Push(task)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("task manager: load tasks: %w", err)
+	}
+	return agendas, nil
+}
+
+// stateOwner поле статуса документа.
+type stateOwner struct {
+	State *State `json:"state"`
+}
+
+// CloseOnState проверяет статус документа и, если он соответствует правилу [TaskManager.WithCloseOnState],
+// отмечает выполненными связанные с документом невыполненные задачи.
+//
+// Возвращает закрытые задачи.
+func (taskManager *TaskManager) CloseOnState(ctx context.Context, operation MetaOwner) (Slice[Task], error) {
+	return taskManager.closeOnState(ctx, []Meta{operation.GetMeta()})
+}
+
+// closeOnState проверяет статусы документов и закрывает задачи документов, перешедших в статусы правил.
+//
+// Фильтр списка задач по документу не поддерживается, поэтому невыполненные задачи
+// загружаются один раз для всех документов.
+func (taskManager *TaskManager) closeOnState(ctx context.Context, metas []Meta) (Slice[Task], error) {
+	operations := make(map[string]struct{})
+	for _, meta := range metas {
+		rules, ok := taskManager.closeRules[meta.GetType()]
+		if !ok {
+			continue
+		}
+		if _, ok = operations[meta.GetHref()]; ok {
+			continue
+		}
+
+		document, _, err := FetchMeta[stateOwner](ctx, taskManager.client, meta)
+		if err != nil {
+			return nil, fmt.Errorf("task manager: get %s: %w", meta.GetType(), err)
+		}
+		if document.State != nil && slices.Contains(rules, document.State.GetMeta().GetHref()) {
+			operations[meta.GetHref()] = struct{}{}
+		}
+	}
+	if len(operations) == 0 {
+		return nil, nil
+	}
+
+	var tasks Slice[Task]
+	params := NewParams().WithFilterEquals("done", "false")
+	err := forEachPage[Task](ctx, taskManager.client, EndpointTask, params, func(rows Slice[Task]) error {
+		for _, task := range rows {
+			if task.Operation == nil {
+				continue
+			}
+			if _, ok := operations[task.Operation.GetMeta().GetHref()]; ok {
+				tasks.Push(new(Task).SetMeta(task.Meta).SetDone(true))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("task manager: load tasks: %w", err)
+	}
+
+	var closed Slice[Task]
+	for _, chunk := range tasks.IntoChunks(MaxPositions) {
+		updated, _, err := NewTaskService(taskManager.client).CreateUpdateMany(ctx, chunk)
+		if err != nil {
+			return nil, fmt.Errorf("task manager: close tasks: %w", err)
+		}
+		closed.Push(Deref(updated)...)
+	}
+	return closed, nil
+}
+
+// HandleWebhook закрывает задачи по документам, статус которых изменился в уведомлении вебхука.
+//
+// Обрабатываются события изменения документов с правилами [TaskManager.WithCloseOnState];
+// задачи всех документов уведомления загружаются одним проходом.
+func (taskManager *TaskManager) HandleWebhook(ctx context.Context, notification *WebhookNotification) (Slice[Task], error) {
+	var metas []Meta
+	for _, event := range notification.Events {
+		if event.Action != WebhookActionUpdate {
+			continue
+		}
+		if len(event.UpdatedFields) > 0 && !slices.Contains(event.UpdatedFields.UnPtr(), "state") {
+			continue
+		}
+		metas = append(metas, event.Meta)
+	}
+	return taskManager.closeOnState(ctx, metas)
+}
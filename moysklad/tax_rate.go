@@ -192,6 +192,11 @@ type TaxRateService interface {
 	// Принимает контекст, налоговую ставку и опционально объект параметров запроса Params.
 	// Возвращает изменённую налоговую ставку.
 	Update(ctx context.Context, id uuid.UUID, taxRate *TaxRate, params ...*Params) (*TaxRate, *resty.Response, error)
+
+	// UpdateIfUnchanged выполняет запрос на изменение налоговой ставки, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *TaxRate, taxRate *TaxRate, params ...*Params) (*TaxRate, *resty.Response, error)
 }
 
 const (
@@ -233,6 +233,59 @@ func NewPrintDocArgMany(templates ...*PrintDocTemplate) *PrintDocumentArg {
 	return printDocumentArg
 }
 
+// MassPrintArg аргумент запроса на массовую печать документов одного типа.
+type MassPrintArg struct {
+	Template  *TemplateOwner `json:"template,omitempty"`  // Метаданные Шаблона печати
+	Extension Extension      `json:"extension,omitempty"` // Расширение, в котором нужно напечатать формы
+	Documents []MetaWrapper  `json:"documents,omitempty"` // Метаданные печатаемых документов
+}
+
+// NewMassPrintArg создаёт и возвращает заполненный объект для запроса массовой печати документов.
+//
+// Аргументы: шаблон, расширение и документы одного типа.
+func NewMassPrintArg(template TemplateConverter, ext Extension, documents ...MetaOwner) *MassPrintArg {
+	massPrintArg := &MassPrintArg{Extension: ext}
+
+	if template != nil {
+		massPrintArg.Template = template.AsTemplate()
+	}
+
+	for _, document := range documents {
+		if document != nil {
+			massPrintArg.Documents = append(massPrintArg.Documents, document.GetMeta().Wrap())
+		}
+	}
+
+	return massPrintArg
+}
+
+// MassPrintResult результат массовой печати документов.
+type MassPrintResult struct {
+	FileName string                  // Название полученного файла
+	Failures Slice[MassPrintFailure] // Документы, которые не удалось напечатать
+}
+
+// MassPrintFailure ошибка печати отдельного документа.
+type MassPrintFailure struct {
+	Document MetaWrapper // Метаданные документа
+	Error    ApiError    // Ошибка печати
+}
+
+// newMassPrintFailures сопоставляет ошибки асинхронной задачи с печатаемыми документами.
+//
+// Ошибки, не относящиеся к конкретному документу, возвращаются с пустыми метаданными документа.
+func newMassPrintFailures(apiErrors ApiErrors) Slice[MassPrintFailure] {
+	var failures = Slice[MassPrintFailure]{}
+	for _, apiError := range apiErrors.ApiErrors {
+		failure := &MassPrintFailure{Error: *apiError}
+		if apiError.Meta != nil {
+			failure.Document = apiError.Meta.Wrap()
+		}
+		failures.Push(failure)
+	}
+	return failures
+}
+
 // Ценники
 
 type PrintLabelArg struct {
@@ -191,6 +191,11 @@ type UomService interface {
 	// Принимает контекст, единицу измерения и опционально объект параметров запроса Params.
 	// Возвращает изменённую единицу измерения.
 	Update(ctx context.Context, id uuid.UUID, uom *Uom, params ...*Params) (*Uom, *resty.Response, error)
+
+	// UpdateIfUnchanged выполняет запрос на изменение единицы измерения, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Uom, uom *Uom, params ...*Params) (*Uom, *resty.Response, error)
 }
 
 const (
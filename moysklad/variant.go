@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/go-resty/resty/v2"
 	"github.com/google/uuid"
+	"io"
 	"time"
 )
 
@@ -177,6 +178,12 @@ func (variant *Variant) SetDescription(description string) *Variant {
 	return variant
 }
 
+// SetName устанавливает Наименование товара с Модификацией.
+func (variant *Variant) SetName(name string) *Variant {
+	variant.Name = &name
+	return variant
+}
+
 // SetExternalCode устанавливает Внешний код Модификации.
 func (variant *Variant) SetExternalCode(externalCode string) *Variant {
 	variant.ExternalCode = &externalCode
@@ -480,6 +487,11 @@ type VariantService interface {
 	// Возвращает изменённую модификацию.
 	Update(ctx context.Context, id uuid.UUID, variant *Variant, params ...*Params) (*Variant, *resty.Response, error)
 
+	// UpdateIfUnchanged выполняет запрос на изменение модификации, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Variant, variant *Variant, params ...*Params) (*Variant, *resty.Response, error)
+
 	// GetMetadata выполняет запрос на получение метаданных модификаций.
 	// Принимает контекст.
 	// Возвращает объект метаданных MetaCharacteristicsWrapper.
@@ -546,6 +558,16 @@ type VariantService interface {
 	// Принимает контекст и ID характеристики.
 	// Возвращает «true» в случае успешного удаления характеристики.
 	DeleteCharacteristic(ctx context.Context, id uuid.UUID) (bool, *resty.Response, error)
+
+	// PrintLabel выполняет запрос на печать этикеток и ценников.
+	// Принимает контекст, ID модификации и объект PrintLabelArg.
+	// Возвращает объект PrintFile.
+	PrintLabel(ctx context.Context, id uuid.UUID, PrintLabelArg *PrintLabelArg) (*PrintFile, *resty.Response, error)
+
+	// PrintLabelTo выполняет запрос на печать этикеток и ценников.
+	// Принимает контекст, ID модификации, объект PrintLabelArg и io.Writer, в который записывается файл без полной буферизации.
+	// Возвращает название файла.
+	PrintLabelTo(ctx context.Context, id uuid.UUID, PrintLabelArg *PrintLabelArg, w io.Writer) (string, *resty.Response, error)
 }
 
 const (
@@ -564,9 +586,11 @@ type variantService struct {
 	endpointDelete[Variant]
 	endpointGetByID[Variant]
 	endpointUpdate[Variant]
+	endpointUpdateSafe[Variant]
 	endpointMetadata[MetaCharacteristicsWrapper]
 	endpointImages
 	endpointNamedFilter
+	endpointPrintLabel
 }
 
 func (service *variantService) CreateCharacteristic(ctx context.Context, characteristic *Characteristic) (*Characteristic, *resty.Response, error) {
@@ -605,8 +629,10 @@ func NewVariantService(client *Client) VariantService {
 		endpointDelete:           endpointDelete[Variant]{e},
 		endpointGetByID:          endpointGetByID[Variant]{e},
 		endpointUpdate:           endpointUpdate[Variant]{e},
+		endpointUpdateSafe:       endpointUpdateSafe[Variant]{e},
 		endpointMetadata:         endpointMetadata[MetaCharacteristicsWrapper]{e},
 		endpointImages:           endpointImages{e},
 		endpointNamedFilter:      endpointNamedFilter{e},
+		endpointPrintLabel:       endpointPrintLabel{e},
 	}
 }
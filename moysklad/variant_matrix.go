@@ -0,0 +1,476 @@
+package moysklad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+)
+
+// VariantCombination сочетание значений характеристик модификации.
+type VariantCombination struct {
+	Names  []string // Наименования характеристик в порядке добавления в матрицу
+	Values []string // Значения характеристик
+}
+
+// Value возвращает значение характеристики с указанным наименованием или пустую строку.
+func (variantCombination VariantCombination) Value(name string) string {
+	for i, n := range variantCombination.Names {
+		if strings.EqualFold(n, name) {
+			return variantCombination.Values[i]
+		}
+	}
+	return ""
+}
+
+// Name возвращает наименование модификации в формате «Товар (значение1, значение2)».
+func (variantCombination VariantCombination) Name(productName string) string {
+	return fmt.Sprintf("%s (%s)", productName, strings.Join(variantCombination.Values, ", "))
+}
+
+// key возвращает ключ сочетания без учёта регистра значений.
+func (variantCombination VariantCombination) key() string {
+	return variantMatrixKey(variantCombination.Names, variantCombination.Values)
+}
+
+// matches возвращает true, если сочетание содержит все значения values.
+func (variantCombination VariantCombination) matches(values map[string]string) bool {
+	for name, value := range values {
+		if !strings.EqualFold(variantCombination.Value(name), value) {
+			return false
+		}
+	}
+	return true
+}
+
+// variantMatrixKey возвращает ключ сочетания значений характеристик.
+func variantMatrixKey(names, values []string) string {
+	parts := make([]string, len(names))
+	for i := range names {
+		parts[i] = strings.ToLower(strings.TrimSpace(names[i])) + "=" + strings.ToLower(strings.TrimSpace(values[i]))
+	}
+	return strings.Join(parts, "\x1f")
+}
+
+// variantMatrixAxis характеристика матрицы и её значения.
+type variantMatrixAxis struct {
+	name   string
+	values []string
+}
+
+// variantMatrixSurcharge надбавка к ценам продажи для значения характеристики.
+type variantMatrixSurcharge struct {
+	name   string
+	value  string
+	amount float64
+}
+
+// VariantMatrixResult результат синхронизации модификаций товара с матрицей.
+type VariantMatrixResult struct {
+	Combinations []VariantCombination // Все сочетания матрицы с учётом исключений
+	Created      Slice[Variant]       // Созданные модификации
+	Restored     Slice[Variant]       // Модификации, извлечённые из архива
+	Archived     Slice[Variant]       // Модификации, перемещённые в архив
+	Unchanged    Slice[Variant]       // Модификации, соответствующие матрице
+	Failed       Slice[Variant]       // Модификации, которые не удалось создать или изменить
+}
+
+// VariantMatrix генератор модификаций товара по сочетаниям значений характеристик.
+//
+// Для каждого сочетания значений (декартова произведения) без модификации создаётся новая модификация
+// с ценами продажи товара (с учётом надбавок) и, при необходимости, внутренним штрихкодом EAN13.
+// Модификации, сочетания которых отсутствуют в матрице (в том числе модификации с другим набором характеристик
+// после добавления или удаления характеристики), перемещаются в архив.
+//
+// Наименование создаваемой модификации формируется методом [VariantCombination.Name]
+// из наименования товара и значений характеристик.
+type VariantMatrix struct {
+	client      *Client
+	product     *Product
+	axes        []variantMatrixAxis
+	exclusions  []map[string]string
+	surcharges  []variantMatrixSurcharge
+	rules       BarcodeRules
+	prefix      string
+	nextBarcode int64
+	barcodes    bool
+	dryRun      bool
+}
+
+// NewVariantMatrix принимает [Client] и товар и возвращает новый объект [VariantMatrix].
+func NewVariantMatrix(client *Client, product *Product) *VariantMatrix {
+	return &VariantMatrix{client: client, product: product}
+}
+
+// Add добавляет в матрицу характеристику с наименованием name и списком значений.
+//
+// Повторный вызов с тем же наименованием заменяет значения характеристики.
+func (variantMatrix *VariantMatrix) Add(name string, values ...string) *VariantMatrix {
+	var unique []string
+	seen := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if _, ok := seen[strings.ToLower(value)]; ok || value == "" {
+			continue
+		}
+		seen[strings.ToLower(value)] = struct{}{}
+		unique = append(unique, value)
+	}
+
+	for i, axis := range variantMatrix.axes {
+		if strings.EqualFold(axis.name, name) {
+			variantMatrix.axes[i].values = unique
+			return variantMatrix
+		}
+	}
+	variantMatrix.axes = append(variantMatrix.axes, variantMatrixAxis{name: strings.TrimSpace(name), values: unique})
+	return variantMatrix
+}
+
+// Exclude исключает из матрицы сочетания, содержащие все переданные значения характеристик.
+//
+// Например, Exclude(map[string]string{"Размер": "XXL", "Цвет": "Белый"}) исключает сочетание XXL и белого цвета.
+func (variantMatrix *VariantMatrix) Exclude(values map[string]string) *VariantMatrix {
+	variantMatrix.exclusions = append(variantMatrix.exclusions, values)
+	return variantMatrix
+}
+
+// WithSurcharge устанавливает надбавку amount (в копейках) ко всем ценам продажи модификаций
+// со значением value характеристики name.
+func (variantMatrix *VariantMatrix) WithSurcharge(name, value string, amount float64) *VariantMatrix {
+	variantMatrix.surcharges = append(variantMatrix.surcharges, variantMatrixSurcharge{name, value, amount})
+	return variantMatrix
+}
+
+// WithBarcodes включает генерацию внутренних штрихкодов EAN13 для создаваемых модификаций.
+//
+// Штрихкоды формируются методом [BarcodeRules.NewInStoreBarcodeEAN13] с префиксом prefix
+// и порядковыми номерами, начиная с next.
+func (variantMatrix *VariantMatrix) WithBarcodes(rules BarcodeRules, prefix string, next int64) *VariantMatrix {
+	variantMatrix.rules = rules
+	variantMatrix.prefix = prefix
+	variantMatrix.nextBarcode = next
+	variantMatrix.barcodes = true
+	return variantMatrix
+}
+
+// WithDryRun включает режим проверки: результат рассчитывается без изменения данных.
+//
+// Отсутствующие характеристики при этом также не создаются.
+func (variantMatrix *VariantMatrix) WithDryRun() *VariantMatrix {
+	variantMatrix.dryRun = true
+	return variantMatrix
+}
+
+// Combinations возвращает все сочетания значений характеристик с учётом исключений.
+func (variantMatrix *VariantMatrix) Combinations() []VariantCombination {
+	if len(variantMatrix.axes) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(variantMatrix.axes))
+	for i, axis := range variantMatrix.axes {
+		if len(axis.values) == 0 {
+			return nil
+		}
+		names[i] = axis.name
+	}
+
+	var combinations []VariantCombination
+	indexes := make([]int, len(variantMatrix.axes))
+	for {
+		values := make([]string, len(indexes))
+		for i, index := range indexes {
+			values[i] = variantMatrix.axes[i].values[index]
+		}
+
+		combination := VariantCombination{Names: names, Values: values}
+		if !variantMatrix.isExcluded(combination) {
+			combinations = append(combinations, combination)
+		}
+
+		// следующее сочетание: последняя характеристика меняется быстрее остальных
+		i := len(indexes) - 1
+		for ; i >= 0; i-- {
+			if indexes[i]++; indexes[i] < len(variantMatrix.axes[i].values) {
+				break
+			}
+			indexes[i] = 0
+		}
+		if i < 0 {
+			return combinations
+		}
+	}
+}
+
+// isExcluded возвращает true, если сочетание попадает под одно из исключений.
+func (variantMatrix *VariantMatrix) isExcluded(combination VariantCombination) bool {
+	for _, exclusion := range variantMatrix.exclusions {
+		if len(exclusion) > 0 && combination.matches(exclusion) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sync приводит модификации товара в соответствие с матрицей.
+//
+// Отсутствующие характеристики создаются, модификации для новых сочетаний создаются,
+// архивные модификации для сочетаний матрицы извлекаются из архива,
+// модификации с сочетаниями вне матрицы перемещаются в архив.
+//
+// Ошибки создания или изменения отдельных модификаций не прерывают синхронизацию:
+// такие модификации попадают в поле Failed результата, а ошибки возвращаются объединённой ошибкой.
+func (variantMatrix *VariantMatrix) Sync(ctx context.Context) (*VariantMatrixResult, error) {
+	product, err := variantMatrix.loadProduct(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	characteristics, err := variantMatrix.ensureCharacteristics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := variantMatrix.loadVariants(ctx, product.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VariantMatrixResult{Combinations: variantMatrix.Combinations()}
+
+	var changes []variantMatrixChange
+	inMatrix := make(map[string]struct{}, len(result.Combinations))
+
+	for _, combination := range result.Combinations {
+		key := combination.key()
+		inMatrix[key] = struct{}{}
+
+		if variants, ok := existing[key]; ok {
+			variant := variants[0]
+			if variant.GetArchived() {
+				changes = append(changes, variantMatrixChange{&result.Restored, variant, &Variant{Meta: variant.Meta, Archived: Bool(false)}})
+			} else {
+				result.Unchanged.Push(variant)
+			}
+			continue
+		}
+
+		variant, err := variantMatrix.newVariant(product, combination, characteristics)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, variantMatrixChange{&result.Created, variant, variant})
+	}
+
+	for key, variants := range existing {
+		// с сочетанием матрицы сопоставляется только первая модификация, остальные архивируются
+		if _, ok := inMatrix[key]; ok {
+			variants = variants[1:]
+		}
+		for _, variant := range variants {
+			if !variant.GetArchived() {
+				changes = append(changes, variantMatrixChange{&result.Archived, variant, &Variant{Meta: variant.Meta, Archived: Bool(true)}})
+			}
+		}
+	}
+
+	if variantMatrix.dryRun {
+		for _, change := range changes {
+			change.target.Push(change.variant)
+		}
+		return result, nil
+	}
+
+	var errs []error
+	for start := 0; start < len(changes); start += MaxPositions {
+		chunk := changes[start:min(start+MaxPositions, len(changes))]
+
+		var payload = make(Slice[Variant], len(chunk))
+		for i, change := range chunk {
+			payload[i] = change.payload
+		}
+
+		saved, itemErrs, err := createUpdateEach(ctx, variantMatrix.client, EndpointVariant, payload)
+		if err != nil {
+			return nil, fmt.Errorf("variant matrix: save variants: %w", err)
+		}
+
+		for i, change := range chunk {
+			if itemErrs[i] != nil {
+				result.Failed.Push(change.variant)
+				errs = append(errs, fmt.Errorf("variant matrix: save %q: %w", change.variant.GetName(), itemErrs[i]))
+				continue
+			}
+			// созданная модификация заменяется ответом сервиса
+			if change.target == &result.Created {
+				change.variant = saved[i]
+			}
+			change.target.Push(change.variant)
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// variantMatrixChange изменение модификации при синхронизации.
+type variantMatrixChange struct {
+	target  *Slice[Variant] // Список результата, в который попадает модификация
+	variant *Variant        // Модификация для результата
+	payload *Variant        // Тело запроса на создание/изменение
+}
+
+// loadProduct возвращает товар, запрашивая его, если передан объект только с метаданными.
+func (variantMatrix *VariantMatrix) loadProduct(ctx context.Context) (*Product, error) {
+	product := variantMatrix.product
+	if product == nil {
+		return nil, fmt.Errorf("variant matrix: product is nil")
+	}
+	if product.Name != nil && product.ID != nil {
+		return product, nil
+	}
+
+	id := product.GetID()
+	if id == uuid.Nil {
+		id = product.GetMeta().GetUUIDFromHref()
+	}
+	loaded, _, err := NewProductService(variantMatrix.client).GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("variant matrix: load product: %w", err)
+	}
+	variantMatrix.product = loaded
+	return loaded, nil
+}
+
+// ensureCharacteristics возвращает характеристики матрицы, создавая отсутствующие.
+func (variantMatrix *VariantMatrix) ensureCharacteristics(ctx context.Context) (map[string]*Characteristic, error) {
+	service := NewVariantService(variantMatrix.client)
+	metadata, _, err := service.GetMetadata(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("variant matrix: get characteristics: %w", err)
+	}
+
+	characteristics := make(map[string]*Characteristic)
+	for _, characteristic := range metadata.Characteristics {
+		characteristics[strings.ToLower(characteristic.GetName())] = characteristic
+	}
+
+	var missing Slice[Characteristic]
+	for _, axis := range variantMatrix.axes {
+		if _, ok := characteristics[strings.ToLower(axis.name)]; !ok {
+			missing.Push(new(Characteristic).SetName(axis.name))
+		}
+	}
+
+	if len(missing) == 0 {
+		return characteristics, nil
+	}
+
+	if variantMatrix.dryRun {
+		for _, characteristic := range missing {
+			characteristics[strings.ToLower(characteristic.GetName())] = characteristic
+		}
+		return characteristics, nil
+	}
+
+	created, _, err := service.CreateCharacteristicMany(ctx, missing...)
+	if err != nil {
+		return nil, fmt.Errorf("variant matrix: create characteristics: %w", err)
+	}
+	for _, characteristic := range Deref(created) {
+		characteristics[strings.ToLower(characteristic.GetName())] = characteristic
+	}
+	return characteristics, nil
+}
+
+// loadVariants загружает модификации товара, включая архивные, и возвращает их по ключам сочетаний.
+//
+// Модификации, набор характеристик которых не совпадает с характеристиками матрицы,
+// возвращаются по ключу, не совпадающему ни с одним сочетанием матрицы.
+// Модификации с одинаковым сочетанием возвращаются по одному ключу в порядке загрузки.
+func (variantMatrix *VariantMatrix) loadVariants(ctx context.Context, productID uuid.UUID) (map[string]Slice[Variant], error) {
+	params := NewParams().
+		WithFilterEquals("productid", productID.String()).
+		WithFilterEquals("archived", "true").
+		WithFilterEquals("archived", "false")
+
+	variants := make(map[string]Slice[Variant])
+	err := forEachPage[Variant](ctx, variantMatrix.client, EndpointVariant, params, func(rows Slice[Variant]) error {
+		for _, variant := range rows {
+			key, ok := variantMatrix.variantKey(variant)
+			if !ok {
+				key = "\x00" + variant.GetID().String()
+			}
+			variants[key] = append(variants[key], variant)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("variant matrix: load variants: %w", err)
+	}
+	return variants, nil
+}
+
+// variantKey возвращает ключ сочетания значений характеристик модификации.
+func (variantMatrix *VariantMatrix) variantKey(variant *Variant) (string, bool) {
+	if len(variant.Characteristics) != len(variantMatrix.axes) {
+		return "", false
+	}
+
+	names := make([]string, len(variantMatrix.axes))
+	values := make([]string, len(variantMatrix.axes))
+	for i, axis := range variantMatrix.axes {
+		names[i] = axis.name
+		found := false
+		for _, characteristic := range variant.Characteristics {
+			if strings.EqualFold(characteristic.GetName(), axis.name) {
+				values[i], found = characteristic.GetValue(), true
+				break
+			}
+		}
+		if !found {
+			return "", false
+		}
+	}
+	return variantMatrixKey(names, values), true
+}
+
+// newVariant возвращает новую модификацию товара для сочетания значений характеристик.
+func (variantMatrix *VariantMatrix) newVariant(product *Product, combination VariantCombination, characteristics map[string]*Characteristic) (*Variant, error) {
+	variant := new(Variant).SetProduct(product).SetName(combination.Name(product.GetName()))
+
+	for i, name := range combination.Names {
+		value := new(Characteristic).SetValue(combination.Values[i])
+		if characteristic := characteristics[strings.ToLower(name)]; characteristic != nil && characteristic.Meta != nil {
+			value.SetMeta(characteristic.Meta)
+		} else {
+			value.SetName(name)
+		}
+		variant.SetCharacteristics(value)
+	}
+
+	var surcharge float64
+	for _, s := range variantMatrix.surcharges {
+		if strings.EqualFold(combination.Value(s.name), s.value) {
+			surcharge += s.amount
+		}
+	}
+
+	for _, price := range product.GetSalePrices() {
+		variant.SetSalePrices(&SalePrice{
+			Value:     Float(price.GetValue() + surcharge),
+			Currency:  price.Currency,
+			PriceType: price.PriceType,
+		})
+	}
+
+	if variantMatrix.barcodes {
+		barcode, err := variantMatrix.rules.NewInStoreBarcodeEAN13(variantMatrix.prefix, variantMatrix.nextBarcode)
+		if err != nil {
+			return nil, fmt.Errorf("variant matrix: barcode for %q: %w", combination.Name(product.GetName()), err)
+		}
+		variantMatrix.nextBarcode++
+		variant.SetBarcodes(barcode)
+	}
+	return variant, nil
+}
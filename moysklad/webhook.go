@@ -264,6 +264,11 @@ type WebhookService interface {
 	// Принимает контекст, вебхук и опционально объект параметров запроса Params.
 	// Возвращает изменённый вебхук.
 	Update(ctx context.Context, id uuid.UUID, webhook *Webhook, params ...*Params) (*Webhook, *resty.Response, error)
+
+	// UpdateIfUnchanged выполняет запрос на изменение вебхука, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *Webhook, webhook *Webhook, params ...*Params) (*Webhook, *resty.Response, error)
 }
 
 const (
@@ -201,6 +201,11 @@ type WebhookStockService interface {
 	// Принимает контекст, вебхук на изменение остатков и опционально объект параметров запроса Params.
 	// Возвращает изменённый вебхук на изменение остатков.
 	Update(ctx context.Context, id uuid.UUID, webhookStock *WebhookStock, params ...*Params) (*WebhookStock, *resty.Response, error)
+
+	// UpdateIfUnchanged выполняет запрос на изменение вебхука на изменение остатков, если объект не изменялся с момента загрузки версии expected.
+	// Принимает контекст, ранее загруженную версию, изменённый объект и опционально объект параметров запроса Params.
+	// Возвращает изменённый объект или ошибку ConflictError, содержащую обе версии объекта.
+	UpdateIfUnchanged(ctx context.Context, expected *WebhookStock, webhookStock *WebhookStock, params ...*Params) (*WebhookStock, *resty.Response, error)
 }
 
 const (